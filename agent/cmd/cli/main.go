@@ -12,6 +12,7 @@ import (
 	"github.com/BenjaminAGH/nocturneagent/internal/domain"
 	"github.com/BenjaminAGH/nocturneagent/internal/infrastructure/backend"
 	"github.com/BenjaminAGH/nocturneagent/internal/infrastructure/metrics"
+	"github.com/BenjaminAGH/nocturneagent/internal/infrastructure/spool"
 	cliui "github.com/BenjaminAGH/nocturneagent/internal/interface/cli"
 	agentuc "github.com/BenjaminAGH/nocturneagent/internal/usecase/agent"
 )
@@ -71,27 +72,38 @@ func startAgentFromConfig(cfg config.AgentConfig, metricsChan chan domain.Metric
 	deviceName, _ := os.Hostname()
 	ip := getOutboundIP()
 
-	collectors := []agentuc.Collector{
-		metrics.NewBasicSystemCollector(deviceName, ip),
-		metrics.NewCPUPerCoreCollector(),
-		metrics.NewHostInfoCollector(),
-		metrics.NewNetCollector(),
-		metrics.NewDeviceTypeCollector(cfg.DeviceType),
-		metrics.NewTemperatureCollector(),
-		metrics.NewGatewayCollector(),
-	}
+	collectors := metrics.NewAgentCollectors(cfg, deviceName, ip)
 
-	client := backend.NewHTTPClient(
-		cfg.BackendURL,
-		cfg.APIToken,
+	opts := []backend.Option{
 		backend.WithQueue(200),
 		backend.WithRetry(4, time.Second),
-	)
+	}
+	// spool en disco; si no se puede abrir seguimos con la cola en memoria
+	if sp, err := openSpool(cfg); err == nil {
+		opts = append(opts, backend.WithSpool(sp))
+	} else {
+		log.Printf("no se pudo abrir el spool, se usa cola en memoria: %v", err)
+	}
+
+	client := backend.NewHTTPClient(cfg.BackendURL, cfg.APIToken, opts...)
 
 	svc := agentuc.NewService(collectors, client, interval, metricsChan)
 	svc.Start()
 }
 
+func openSpool(cfg config.AgentConfig) (*spool.Spool, error) {
+	dir, err := config.SpoolDir()
+	if err != nil {
+		return nil, err
+	}
+	maxBytes, maxAge := cfg.SpoolLimits()
+	return spool.Open(spool.Options{
+		Dir:      dir,
+		MaxBytes: maxBytes,
+		MaxAge:   maxAge,
+	})
+}
+
 func getOutboundIP() string {
 	conn, err := net.Dial("udp", "8.8.8.8:80")
 	if err != nil {
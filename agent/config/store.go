@@ -6,6 +6,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 type AgentConfig struct {
@@ -13,9 +14,31 @@ type AgentConfig struct {
 	APIToken   string `json:"api_token"`
 	Interval   string `json:"interval"`
 	DeviceType string `json:"device_type"`
+
+	// límites del spool en disco (opcionales)
+	SpoolMaxMB  int    `json:"spool_max_mb,omitempty"`
+	SpoolMaxAge string `json:"spool_max_age,omitempty"`
+
+	// puntos de montaje a reportar: patrones glob sobre la ruta (ej. "/data*").
+	// Sin include se reportan todos; exclude se aplica después.
+	MountInclude []string `json:"mount_include,omitempty"`
+	MountExclude []string `json:"mount_exclude,omitempty"`
+
+	// interfaces de red a reportar, igual que los montajes (ej. "eth*").
+	// Loopback nunca se reporta; sin exclude se omiten las de contenedores.
+	IfaceInclude []string `json:"iface_include,omitempty"`
+	IfaceExclude []string `json:"iface_exclude,omitempty"`
+
+	// discos a reportar en el I/O, igual que las interfaces (ej. "nvme*").
+	// Las particiones no se reportan; sin exclude se omiten loop, ram, zram y lectoras.
+	DiskInclude []string `json:"disk_include,omitempty"`
+	DiskExclude []string `json:"disk_exclude,omitempty"`
+
+	// cuántos procesos reportar por CPU y por memoria (5 por defecto; -1 desactiva)
+	TopProcs int `json:"top_procs,omitempty"`
 }
 
-func configPath() (string, error) {
+func configDir() (string, error) {
 	var home string
 	var err error
 
@@ -43,9 +66,27 @@ func configPath() (string, error) {
 	// But for reading it's fine. For writing, it might be an issue if it doesn't exist.
 	// Assuming it exists or we are careful.
 	_ = os.MkdirAll(dir, 0700)
+	return dir, nil
+}
+
+func configPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
 	return filepath.Join(dir, "config.json"), nil
 }
 
+// SpoolDir devuelve el directorio donde se guardan las métricas pendientes de envío
+// (~/.nocturneagent/spool).
+func SpoolDir() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "spool"), nil
+}
+
 func Save(cfg AgentConfig) error {
 	path, err := configPath()
 	if err != nil {
@@ -81,3 +122,17 @@ func Delete() error {
 	}
 	return os.Remove(path)
 }
+
+// SpoolLimits devuelve los topes del spool en disco, con valores por defecto
+// (256 MB y 7 días) si no están configurados.
+func (c AgentConfig) SpoolLimits() (maxBytes int64, maxAge time.Duration) {
+	maxBytes = 256 << 20
+	if c.SpoolMaxMB > 0 {
+		maxBytes = int64(c.SpoolMaxMB) << 20
+	}
+	maxAge = 7 * 24 * time.Hour
+	if d, err := time.ParseDuration(c.SpoolMaxAge); err == nil && d > 0 {
+		maxAge = d
+	}
+	return maxBytes, maxAge
+}
@@ -22,6 +22,104 @@ type Metric struct {
 	OS          string    `json:"os,omitempty"`
 	DeviceType  string    `json:"device_type,omitempty"` //
 
-	// opcional: procesos top-N (lo dejamos como texto simple)
-	TopProcs []string `json:"top_procs,omitempty"`
+	AgentVersion string `json:"agent_version,omitempty"`
+
+	// uso por punto de montaje (DiskUsage es sólo "/")
+	Mounts []MountUsage `json:"mounts,omitempty"`
+
+	// tráfico por interfaz (NetRxBytes/NetTxBytes suman todas)
+	Interfaces []InterfaceStats `json:"interfaces,omitempty"`
+
+	// I/O por disco desde la recolección anterior (vacío en la primera)
+	DiskIO []DiskIO `json:"disk_io,omitempty"`
+
+	// procesos top-N por CPU y por memoria (sin repetir)
+	TopProcs []ProcessInfo `json:"top_procs,omitempty"`
+
+	// saturación; nil si el SO no lo expone
+	Load          *LoadAvg   `json:"load,omitempty"`
+	Swap          *SwapUsage `json:"swap,omitempty"`
+	Pressure      *Pressure  `json:"pressure,omitempty"`        // PSI, sólo Linux >= 4.20
+	CtxSwitchRate *float64   `json:"ctx_switch_rate,omitempty"` // por segundo; nil en la primera muestra
+	ForkRate      *float64   `json:"fork_rate,omitempty"`       // procesos creados por segundo
+}
+
+// LoadAvg es la carga promedio del sistema a 1, 5 y 15 minutos.
+type LoadAvg struct {
+	Load1  float64 `json:"load1"`
+	Load5  float64 `json:"load5"`
+	Load15 float64 `json:"load15"`
+}
+
+// SwapUsage es el uso de swap; con TotalBytes 0 el equipo no tiene swap.
+type SwapUsage struct {
+	UsedPercent float64 `json:"used_pct"`
+	UsedBytes   uint64  `json:"used_bytes"`
+	TotalBytes  uint64  `json:"total_bytes"`
+}
+
+// Pressure es el % del tiempo en que hubo tareas esperando CPU, memoria o
+// disco desde la recolección anterior. "some": al menos una tarea esperando;
+// "full": todas las tareas no ociosas esperando a la vez.
+type Pressure struct {
+	CPUSome    float64 `json:"cpu_some"`
+	MemorySome float64 `json:"memory_some"`
+	MemoryFull float64 `json:"memory_full"`
+	IOSome     float64 `json:"io_some"`
+	IOFull     float64 `json:"io_full"`
+}
+
+// ProcessInfo es un proceso del top-N. CPUPercent es sobre un núcleo, como en
+// top (puede pasar de 100 en procesos multihilo).
+type ProcessInfo struct {
+	PID        int32   `json:"pid"`
+	Name       string  `json:"name"`
+	User       string  `json:"user,omitempty"`
+	CPUPercent float64 `json:"cpu_pct"`
+	MemPercent float64 `json:"mem_pct"`
+	RSSBytes   uint64  `json:"rss_bytes"`
+	Cmdline    string  `json:"cmdline,omitempty"` // truncada
+}
+
+// MountUsage es el uso de un sistema de archivos montado.
+type MountUsage struct {
+	Path        string  `json:"path"`
+	Device      string  `json:"device,omitempty"`
+	FSType      string  `json:"fstype,omitempty"`
+	UsedPercent float64 `json:"used_pct"`
+	FreeBytes   uint64  `json:"free_bytes"`
+	TotalBytes  uint64  `json:"total_bytes"`
+	InodesUsed  float64 `json:"inodes_used_pct"` // 0 si el sistema de archivos no tiene inodos (vfat, ...)
+	ReadOnly    bool    `json:"read_only,omitempty"`
+}
+
+// DiskIO es la actividad de un disco entre dos recolecciones.
+type DiskIO struct {
+	Name       string  `json:"name"`
+	ReadBytes  float64 `json:"read_bytes"` // por segundo
+	WriteBytes float64 `json:"write_bytes"`
+	ReadIOPS   float64 `json:"read_iops"`
+	WriteIOPS  float64 `json:"write_iops"`
+	AwaitMs    float64 `json:"await_ms"` // espera promedio por operación (cola + servicio)
+	UtilPct    float64 `json:"util_pct"` // % del tiempo con operaciones en curso
+}
+
+// InterfaceStats es el estado de una interfaz de red y su tráfico desde la
+// recolección anterior.
+type InterfaceStats struct {
+	Name  string          `json:"name"`
+	Up    bool            `json:"up"`
+	Rates *InterfaceRates `json:"rates,omitempty"` // nil en la primera muestra o si el contador se reinició
+}
+
+// InterfaceRates son tasas por segundo.
+type InterfaceRates struct {
+	RxBytes   float64 `json:"rx_bytes"`
+	TxBytes   float64 `json:"tx_bytes"`
+	RxPackets float64 `json:"rx_packets"`
+	TxPackets float64 `json:"tx_packets"`
+	RxErrors  float64 `json:"rx_errors"`
+	TxErrors  float64 `json:"tx_errors"`
+	RxDrops   float64 `json:"rx_drops"`
+	TxDrops   float64 `json:"tx_drops"`
 }
@@ -3,12 +3,14 @@ package backend
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"time"
 
 	"github.com/BenjaminAGH/nocturneagent/internal/domain"
+	"github.com/BenjaminAGH/nocturneagent/internal/infrastructure/spool"
 )
 
 type HTTPClient struct {
@@ -17,9 +19,14 @@ type HTTPClient struct {
 	client  *http.Client
 
 	queue       chan domain.Metric
+	spool       *spool.Spool
+	wake        chan struct{}
 	maxRetries  int
 	backoffBase time.Duration
 	silent      bool
+
+	batchSize  int
+	noBatchAPI bool // el backend no tiene /api/metrics/batch, se envía de a una
 }
 
 type Option func(*HTTPClient)
@@ -30,6 +37,24 @@ func WithQueue(size int) Option {
 	}
 }
 
+// WithSpool guarda en disco las métricas que no se pudieron enviar, en lugar de
+// la cola en memoria. Se reenvían en orden cuando el backend vuelve a responder.
+func WithSpool(sp *spool.Spool) Option {
+	return func(c *HTTPClient) {
+		c.spool = sp
+	}
+}
+
+// WithBatchSize define cuántas métricas pendientes se envían por request al
+// vaciar la cola o el spool.
+func WithBatchSize(n int) Option {
+	return func(c *HTTPClient) {
+		if n > 0 {
+			c.batchSize = n
+		}
+	}
+}
+
 func WithRetry(max int, base time.Duration) Option {
 	return func(c *HTTPClient) {
 		c.maxRetries = max
@@ -54,15 +79,21 @@ func NewHTTPClient(baseURL, token string, opts ...Option) *HTTPClient {
 		token:       token,
 		client:      &http.Client{Timeout: 5 * time.Second},
 		queue:       make(chan domain.Metric, 100), // por defecto 100 en cola
+		wake:        make(chan struct{}, 1),
 		maxRetries:  3,
 		backoffBase: time.Second,
+		batchSize:   50,
 	}
 	for _, o := range opts {
 		o(c)
 	}
 
-	// lanzamos el worker que vacía la cola
-	go c.worker()
+	// lanzamos el worker que vacía la cola (o el spool en disco)
+	if c.spool != nil {
+		go c.spoolWorker()
+	} else {
+		go c.worker()
+	}
 
 	if !c.silent {
 		fmt.Printf("🔗 HTTP Client initialized: %s (token: %s...)\n", baseURL, token[:min(8, len(token))])
@@ -84,8 +115,15 @@ func (c *HTTPClient) SendMetric(m domain.Metric) error {
 		return fmt.Errorf("backend no configurado")
 	}
 
+	if c.spool != nil {
+		return c.sendSpooled(m)
+	}
+
 	// intento inmediato
 	if err := c.sendOnce(m); err != nil {
+		if isPermanent(err) {
+			return err // reintentarla no sirve
+		}
 		// si falla, lo mandamos a la cola
 		select {
 		case c.queue <- m:
@@ -102,22 +140,310 @@ func (c *HTTPClient) SendMetric(m domain.Metric) error {
 	return nil
 }
 
-// worker que reintenta lo que quedó en la cola
+// worker que reintenta lo que quedó en la cola, en lotes
 func (c *HTTPClient) worker() {
 	for m := range c.queue {
-		ok := c.retrySend(m)
+		batch := []domain.Metric{m}
+	drain:
+		for len(batch) < c.batchSize {
+			select {
+			case next := <-c.queue:
+				batch = append(batch, next)
+			default:
+				break drain
+			}
+		}
+
+		ok := c.retrySend(batch)
 		if !ok {
 			if !c.silent {
-				fmt.Println("no se pudo enviar métrica después de reintentos, se descarta")
+				fmt.Printf("no se pudieron enviar %d métricas después de reintentos, se descartan\n", len(batch))
+			}
+		}
+	}
+}
+
+// con spool, si hay métricas pendientes la nueva va detrás para respetar el orden
+func (c *HTTPClient) sendSpooled(m domain.Metric) error {
+	if !c.spool.Empty() {
+		if err := c.spool.Append(m); err != nil {
+			if !c.silent {
+				fmt.Println("no se pudo guardar métrica en spool:", err)
+			}
+			return err
+		}
+		c.notify()
+		return nil
+	}
+
+	if err := c.sendOnce(m); err != nil {
+		if isPermanent(err) {
+			if derr := c.spool.DeadLetter(m, err.Error()); derr != nil && !c.silent {
+				fmt.Println("no se pudo guardar métrica rechazada en dead-letter:", derr)
+			}
+			return err
+		}
+		if serr := c.spool.Append(m); serr != nil {
+			if !c.silent {
+				fmt.Println("no se pudo guardar métrica en spool:", serr)
+			}
+		} else if !c.silent {
+			fmt.Println("backend no disponible, métrica guardada en spool")
+		}
+		c.notify()
+		return err
+	}
+	return nil
+}
+
+func (c *HTTPClient) notify() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// worker que vacía el spool en orden. Los errores transitorios (red, 5xx, 429,
+// token rechazado) se reintentan con backoff sin mover el cursor; lo que el
+// backend rechaza de forma permanente se aparta en el dead-letter para no
+// bloquear la cola.
+func (c *HTTPClient) spoolWorker() {
+	failures := 0
+	for {
+		ms, err := c.spool.Peek(c.batchSize)
+		if err != nil || len(ms) == 0 {
+			if err != nil && !c.silent {
+				fmt.Println("error leyendo spool:", err)
+			}
+			select {
+			case <-c.wake:
+			case <-time.After(30 * time.Second):
+			}
+			continue
+		}
+
+		done, rejected, err := c.sendBatch(ms)
+		for _, r := range rejected {
+			if derr := c.spool.DeadLetter(r.metric, r.reason); derr != nil && !c.silent {
+				fmt.Println("no se pudo guardar métrica rechazada en dead-letter:", derr)
+			}
+		}
+		if done > 0 {
+			if aerr := c.spool.Ack(done); aerr != nil && !c.silent {
+				fmt.Println("error confirmando spool:", aerr)
+			}
+		}
+		if err != nil {
+			failures++
+			time.Sleep(c.backoff(failures))
+			continue
+		}
+		failures = 0
+	}
+}
+
+// rejectedMetric es una métrica que el backend no va a aceptar aunque se reintente.
+type rejectedMetric struct {
+	metric domain.Metric
+	reason string
+}
+
+// sendBatch envía las métricas en orden. Devuelve cuántas quedaron resueltas
+// (entregadas o rechazadas de forma permanente), cuáles fueron rechazadas y el
+// error transitorio que cortó el envío, si lo hubo.
+// Si el backend no conoce el endpoint de lotes, cae a envíos individuales.
+func (c *HTTPClient) sendBatch(ms []domain.Metric) (int, []rejectedMetric, error) {
+	if !c.noBatchAPI && len(ms) > 1 {
+		reasons, err := c.postBatch(ms)
+		switch {
+		case err == nil:
+			var rejected []rejectedMetric
+			for i, m := range ms {
+				if reason, ok := reasons[i]; ok {
+					rejected = append(rejected, rejectedMetric{metric: m, reason: reason})
+				}
+			}
+			return len(ms), rejected, nil
+		case err == errNoBatchAPI:
+			c.noBatchAPI = true
+			if !c.silent {
+				fmt.Println("backend sin /api/metrics/batch, se envía de a una métrica")
+			}
+		case !isPermanent(err):
+			return 0, nil, err
+		}
+		// el lote completo fue rechazado sin detalle: de a una se sabe cuál es la culpable
+	}
+
+	var rejected []rejectedMetric
+	for i, m := range ms {
+		err := c.sendOnce(m)
+		if isPermanent(err) {
+			rejected = append(rejected, rejectedMetric{metric: m, reason: err.Error()})
+			continue
+		}
+		if err != nil {
+			return i, rejected, err
+		}
+	}
+	return len(ms), rejected, nil
+}
+
+// permanentError es una respuesta 4xx (salvo 401, 403, 408 y 429): reenviar la
+// misma métrica va a dar el mismo resultado.
+type permanentError struct {
+	status int
+	body   string
+}
+
+func (e *permanentError) Error() string {
+	return fmt.Sprintf("backend rechazó la métrica (%d): %s", e.status, e.body)
+}
+
+func isPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// authError es un 401/403. Puede ser un token rotado o mal copiado, pero el
+// backend también responde 401 si no pudo validar el token (base de datos
+// caída), así que las métricas se conservan y se reintentan.
+type authError struct {
+	status int
+}
+
+func (e *authError) Error() string {
+	return fmt.Sprintf("backend rechazó el API token (%d)", e.status)
+}
+
+func isAuthError(err error) bool {
+	var a *authError
+	return errors.As(err, &a)
+}
+
+// statusError clasifica una respuesta no exitosa del backend.
+func statusError(status int, body []byte) error {
+	if status == http.StatusUnauthorized || status == http.StatusForbidden {
+		return &authError{status: status}
+	}
+	if status >= 400 && status < 500 && status != http.StatusRequestTimeout && status != http.StatusTooManyRequests {
+		return &permanentError{status: status, body: string(body)}
+	}
+	return fmt.Errorf("backend status %d", status)
+}
+
+var errNoBatchAPI = errors.New("batch endpoint no disponible")
+
+// batchResponse es el resultado por item que devuelve /api/metrics/batch.
+type batchResponse struct {
+	Results []struct {
+		Index  int    `json:"index"`
+		Status string `json:"status"`
+		Error  string `json:"error"`
+	} `json:"results"`
+}
+
+// postBatch envía el lote y devuelve, por índice, el motivo de cada item que el
+// backend rechazó. Un 400 sin detalle por item (cuerpo ilegible) vuelve como
+// permanentError para que el llamador reintente de a una.
+func (c *HTTPClient) postBatch(ms []domain.Metric) (map[int]string, error) {
+	body, err := json.Marshal(ms)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+"/api/metrics/batch", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("X-API-Key", c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		if !c.silent {
+			fmt.Println("error enviando lote de métricas:", err)
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed:
+		return nil, errNoBatchAPI
+	case resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusMultiStatus:
+		var br batchResponse
+		if err := json.Unmarshal(respBody, &br); err != nil || len(br.Results) == 0 {
+			if resp.StatusCode == http.StatusMultiStatus {
+				// parte ya quedó guardada: reenviar duplicaría, se da por entregado
+				if !c.silent {
+					fmt.Printf("backend aceptó parte del lote sin detalle por item: %s\n", string(respBody))
+				}
+				return nil, nil
+			}
+			if !c.silent {
+				fmt.Printf("backend rechazó el lote (400): %s\n", string(respBody))
+			}
+			return nil, statusError(resp.StatusCode, respBody)
+		}
+		reasons := map[int]string{}
+		for _, r := range br.Results {
+			if r.Status != "ok" {
+				reasons[r.Index] = fmt.Sprintf("backend rechazó la métrica: %s", r.Error)
 			}
 		}
+		if !c.silent {
+			fmt.Printf("backend rechazó %d de %d métricas del lote\n", len(reasons), len(ms))
+		}
+		return reasons, nil
+	case resp.StatusCode >= 300:
+		err := statusError(resp.StatusCode, respBody)
+		c.logStatus(resp.StatusCode, respBody, err)
+		return nil, err
+	}
+
+	if !c.silent {
+		fmt.Printf("✅ Lote de %d métricas enviado exitosamente\n", len(ms))
+	}
+	return nil, nil
+}
+
+// logStatus informa una respuesta no exitosa; un token rechazado se explica
+// aparte porque no se arregla solo.
+func (c *HTTPClient) logStatus(status int, body []byte, err error) {
+	if c.silent {
+		return
+	}
+	if isAuthError(err) {
+		fmt.Printf("❌ el backend rechazó el API token (%d): revise api_token en la configuración; las métricas quedan pendientes y se reintentan\n", status)
+		return
 	}
+	fmt.Printf("backend respondió %d: %s\n", status, string(body))
 }
 
-// retry con backoff exponencial simple
-func (c *HTTPClient) retrySend(m domain.Metric) bool {
+// backoff exponencial acotado: 1s, 2s, 4s... hasta backoffBase * 2^maxRetries
+func (c *HTTPClient) backoff(failures int) time.Duration {
+	exp := failures - 1
+	if exp > c.maxRetries {
+		exp = c.maxRetries
+	}
+	return c.backoffBase * (1 << exp)
+}
+
+// retry con backoff exponencial simple; lo ya entregado no se reenvía y lo
+// rechazado de forma permanente se descarta
+func (c *HTTPClient) retrySend(ms []domain.Metric) bool {
 	for i := 0; i < c.maxRetries; i++ {
-		if err := c.sendOnce(m); err == nil {
+		done, rejected, err := c.sendBatch(ms)
+		if len(rejected) > 0 && !c.silent {
+			fmt.Printf("backend rechazó %d métricas, se descartan\n", len(rejected))
+		}
+		ms = ms[done:]
+		if err == nil {
 			return true
 		}
 		sleep := c.backoffBase * (1 << i) // 1s, 2s, 4s...
@@ -155,10 +481,9 @@ func (c *HTTPClient) sendOnce(m domain.Metric) error {
 	respBody, _ := io.ReadAll(resp.Body)
 
 	if resp.StatusCode >= 300 {
-		if !c.silent {
-			fmt.Printf("backend respondió %d: %s\n", resp.StatusCode, string(respBody))
-		}
-		return fmt.Errorf("backend status %d", resp.StatusCode)
+		err := statusError(resp.StatusCode, respBody)
+		c.logStatus(resp.StatusCode, respBody, err)
+		return err
 	}
 
 	if !c.silent {
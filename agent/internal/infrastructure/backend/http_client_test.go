@@ -0,0 +1,122 @@
+package backend
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/BenjaminAGH/nocturneagent/internal/domain"
+)
+
+func TestStatusError(t *testing.T) {
+	tests := []struct {
+		status    int
+		permanent bool
+		auth      bool
+	}{
+		{http.StatusBadRequest, true, false},
+		{http.StatusUnprocessableEntity, true, false},
+		{http.StatusRequestEntityTooLarge, true, false},
+		{http.StatusUnauthorized, false, true},
+		{http.StatusForbidden, false, true},
+		{http.StatusRequestTimeout, false, false},
+		{http.StatusTooManyRequests, false, false},
+		{http.StatusInternalServerError, false, false},
+		{http.StatusServiceUnavailable, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			err := statusError(tt.status, []byte("x"))
+			if got := isPermanent(err); got != tt.permanent {
+				t.Errorf("isPermanent = %v, want %v", got, tt.permanent)
+			}
+			if got := isAuthError(err); got != tt.auth {
+				t.Errorf("isAuthError = %v, want %v", got, tt.auth)
+			}
+		})
+	}
+}
+
+// fakeBackend responde al lote con batch y a cada métrica individual con single
+// (según el nombre del dispositivo).
+func fakeBackend(t *testing.T, batch func(w http.ResponseWriter), single func(device string) int) *HTTPClient {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/metrics/batch":
+			batch(w)
+		case "/api/metrics":
+			var m domain.Metric
+			body, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(body, &m)
+			w.WriteHeader(single(m.DeviceName))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return NewHTTPClient(srv.URL, "token", WithSilent(true), WithRetry(1, time.Millisecond))
+}
+
+func TestSendBatch(t *testing.T) {
+	status := func(code int) func(http.ResponseWriter) {
+		return func(w http.ResponseWriter) { w.WriteHeader(code) }
+	}
+	rejectSecond := func(device string) int {
+		if device == "b" {
+			return http.StatusUnprocessableEntity
+		}
+		return http.StatusCreated
+	}
+
+	tests := []struct {
+		name         string
+		batch        func(http.ResponseWriter)
+		single       func(string) int
+		wantDone     int
+		wantRejected []string
+		wantErr      bool
+	}{
+		{"lote aceptado", status(http.StatusCreated), rejectSecond, 3, nil, false},
+		{"resultado por item", func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusMultiStatus)
+			w.Write([]byte(`{"results":[{"index":0,"status":"ok"},{"index":1,"status":"error","error":"cpu fuera de rango"},{"index":2,"status":"ok"}]}`))
+		}, rejectSecond, 3, []string{"b"}, false},
+		{"400 sin detalle cae a envíos individuales", status(http.StatusBadRequest), rejectSecond, 3, []string{"b"}, false},
+		{"token rechazado no descarta nada", status(http.StatusUnauthorized), rejectSecond, 0, nil, true},
+		{"token prohibido no descarta nada", status(http.StatusForbidden), rejectSecond, 0, nil, true},
+		{"error del servidor se reintenta", status(http.StatusBadGateway), rejectSecond, 0, nil, true},
+		{"token rechazado a mitad de envíos individuales", status(http.StatusBadRequest), func(device string) int {
+			if device == "c" {
+				return http.StatusUnauthorized
+			}
+			return rejectSecond(device)
+		}, 2, []string{"b"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fakeBackend(t, tt.batch, tt.single)
+			ms := []domain.Metric{{DeviceName: "a"}, {DeviceName: "b"}, {DeviceName: "c"}}
+
+			done, rejected, err := c.sendBatch(ms)
+			if done != tt.wantDone {
+				t.Errorf("done = %d, want %d", done, tt.wantDone)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, want error %v", err, tt.wantErr)
+			}
+			var got []string
+			for _, r := range rejected {
+				got = append(got, r.metric.DeviceName)
+			}
+			if len(got) != len(tt.wantRejected) || (len(got) > 0 && got[0] != tt.wantRejected[0]) {
+				t.Errorf("rejected = %v, want %v", got, tt.wantRejected)
+			}
+		})
+	}
+}
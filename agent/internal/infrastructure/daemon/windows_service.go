@@ -13,6 +13,7 @@ import (
 	"github.com/BenjaminAGH/nocturneagent/internal/domain"
 	"github.com/BenjaminAGH/nocturneagent/internal/infrastructure/backend"
 	"github.com/BenjaminAGH/nocturneagent/internal/infrastructure/metrics"
+	"github.com/BenjaminAGH/nocturneagent/internal/infrastructure/spool"
 	agentuc "github.com/BenjaminAGH/nocturneagent/internal/usecase/agent"
 )
 
@@ -49,21 +50,23 @@ func (p *program) run() {
 	deviceName, _ := os.Hostname()
 	ip := "127.0.0.1"
 
-	collectors := []agentuc.Collector{
-		metrics.NewBasicSystemCollector(deviceName, ip),
-		metrics.NewCPUPerCoreCollector(),
-		metrics.NewHostInfoCollector(),
-		metrics.NewNetCollector(),
-		metrics.NewDeviceTypeCollector(cfg.DeviceType),
-		metrics.NewTemperatureCollector(),
-	}
+	collectors := metrics.NewAgentCollectors(cfg, deviceName, ip)
 
-	client := backend.NewHTTPClient(
-		cfg.BackendURL,
-		cfg.APIToken,
+	opts := []backend.Option{
 		backend.WithQueue(200),
 		backend.WithRetry(4, time.Second),
-	)
+	}
+	if dir, err := config.SpoolDir(); err == nil {
+		maxBytes, maxAge := cfg.SpoolLimits()
+		sp, err := spool.Open(spool.Options{Dir: dir, MaxBytes: maxBytes, MaxAge: maxAge})
+		if err == nil {
+			opts = append(opts, backend.WithSpool(sp))
+		} else {
+			log.Printf("no se pudo abrir el spool, se usa cola en memoria: %v", err)
+		}
+	}
+
+	client := backend.NewHTTPClient(cfg.BackendURL, cfg.APIToken, opts...)
 
 	svc := agentuc.NewService(collectors, client, interval, metricsChan)
 	svc.Start()
@@ -1,7 +1,31 @@
 package metrics
 
-import "github.com/BenjaminAGH/nocturneagent/internal/domain"
+import (
+	"github.com/BenjaminAGH/nocturneagent/config"
+	"github.com/BenjaminAGH/nocturneagent/internal/domain"
+	agentuc "github.com/BenjaminAGH/nocturneagent/internal/usecase/agent"
+)
 
 type Collector interface {
 	Collect() (domain.Metric, error)
 }
+
+// NewAgentCollectors arma la lista de colectores del agente según la
+// configuración. La comparten el agente, la CLI y el servicio de Windows para
+// que todos reporten las mismas métricas.
+func NewAgentCollectors(cfg config.AgentConfig, deviceName, ip string) []agentuc.Collector {
+	return []agentuc.Collector{
+		NewBasicSystemCollector(deviceName, ip),
+		NewCPUPerCoreCollector(),
+		NewHostInfoCollector(),
+		NewNetCollector(),
+		NewDeviceTypeCollector(cfg.DeviceType),
+		NewTemperatureCollector(),
+		NewGatewayCollector(),
+		NewMountCollector(cfg.MountInclude, cfg.MountExclude),
+		NewInterfaceCollector(cfg.IfaceInclude, cfg.IfaceExclude),
+		NewDiskIOCollector(cfg.DiskInclude, cfg.DiskExclude),
+		NewProcessCollector(cfg.TopProcs),
+		NewSaturationCollector(),
+	}
+}
@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"os"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/disk"
+
+	"github.com/BenjaminAGH/nocturneagent/internal/domain"
+)
+
+// defaultDiskExclude omite los dispositivos de bloque virtuales (imágenes de
+// snaps, RAM) y las lectoras cuando no se configura un exclude propio.
+var defaultDiskExclude = []string{"loop*", "ram*", "zram*", "sr*", "fd*"}
+
+// DiskIOCollector reporta el I/O de cada disco (en Linux, /proc/diskstats).
+// Guarda los contadores de la recolección anterior para calcular las tasas.
+type DiskIOCollector struct {
+	include []string
+	exclude []string
+
+	mu     sync.Mutex
+	last   map[string]disk.IOCountersStat
+	lastAt time.Time
+}
+
+// NewDiskIOCollector recibe patrones glob sobre el nombre del disco (ej. "sd*").
+func NewDiskIOCollector(include, exclude []string) *DiskIOCollector {
+	if len(exclude) == 0 {
+		exclude = defaultDiskExclude
+	}
+	return &DiskIOCollector{include: include, exclude: exclude, last: map[string]disk.IOCountersStat{}}
+}
+
+func (c *DiskIOCollector) Collect() (domain.Metric, error) {
+	counters, err := disk.IOCounters()
+	if err != nil {
+		return domain.Metric{}, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	elapsed := now.Sub(c.lastAt).Seconds()
+
+	current := make(map[string]disk.IOCountersStat, len(counters))
+	var out []domain.DiskIO
+	for name, cur := range counters {
+		if !wholeDisk(name) || !c.wants(name) {
+			continue
+		}
+		current[name] = cur
+
+		prev, ok := c.last[name]
+		if !ok || elapsed <= 0 {
+			continue
+		}
+		if io, ok := diskRates(name, prev, cur, elapsed); ok {
+			out = append(out, io)
+		}
+	}
+	c.last = current
+	c.lastAt = now
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return domain.Metric{DiskIO: out}, nil
+}
+
+func (c *DiskIOCollector) wants(name string) bool {
+	if len(c.include) > 0 && !matchAny(c.include, name) {
+		return false
+	}
+	return !matchAny(c.exclude, name)
+}
+
+// wholeDisk descarta las particiones (sda1, nvme0n1p2): en Linux sólo los
+// discos completos, incluidos los de LVM (dm-*) y RAID (md*), aparecen en /sys/block.
+func wholeDisk(name string) bool {
+	if runtime.GOOS != "linux" {
+		return true
+	}
+	_, err := os.Stat("/sys/block/" + name)
+	return err == nil
+}
+
+// diskRates calcula la actividad entre dos lecturas; false si algún contador
+// se reinició. Los tiempos del kernel vienen en milisegundos.
+func diskRates(name string, prev, cur disk.IOCountersStat, elapsed float64) (domain.DiskIO, bool) {
+	ok := true
+	delta := func(p, c uint64) float64 {
+		d, valid := counterDelta(p, c)
+		if !valid {
+			ok = false
+		}
+		return float64(d)
+	}
+	reads := delta(prev.ReadCount, cur.ReadCount)
+	writes := delta(prev.WriteCount, cur.WriteCount)
+	waited := delta(prev.ReadTime, cur.ReadTime) + delta(prev.WriteTime, cur.WriteTime)
+	busy := delta(prev.IoTime, cur.IoTime)
+
+	io := domain.DiskIO{
+		Name:       name,
+		ReadBytes:  delta(prev.ReadBytes, cur.ReadBytes) / elapsed,
+		WriteBytes: delta(prev.WriteBytes, cur.WriteBytes) / elapsed,
+		ReadIOPS:   reads / elapsed,
+		WriteIOPS:  writes / elapsed,
+		UtilPct:    busy / (elapsed * 1000) * 100,
+	}
+	if ops := reads + writes; ops > 0 {
+		io.AwaitMs = waited / ops
+	}
+	// el intervalo medido por el agente y el del kernel no coinciden exactamente
+	if io.UtilPct > 100 {
+		io.UtilPct = 100
+	}
+	return io, ok
+}
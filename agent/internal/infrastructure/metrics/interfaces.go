@@ -0,0 +1,142 @@
+package metrics
+
+import (
+	"os"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/net"
+
+	"github.com/BenjaminAGH/nocturneagent/internal/domain"
+)
+
+// defaultIfaceExclude omite los puentes e interfaces virtuales de contenedores
+// y VMs cuando no se configura un exclude propio.
+var defaultIfaceExclude = []string{"docker*", "br-*", "veth*", "virbr*", "cni*", "flannel*", "cali*"}
+
+// InterfaceCollector reporta el tráfico por interfaz. Guarda los contadores de
+// la recolección anterior para calcular las tasas por segundo.
+type InterfaceCollector struct {
+	include []string
+	exclude []string
+
+	mu     sync.Mutex
+	last   map[string]net.IOCountersStat
+	lastAt time.Time
+}
+
+// NewInterfaceCollector recibe patrones glob sobre el nombre de la interfaz (ej. "eth*").
+func NewInterfaceCollector(include, exclude []string) *InterfaceCollector {
+	if len(exclude) == 0 {
+		exclude = defaultIfaceExclude
+	}
+	return &InterfaceCollector{include: include, exclude: exclude, last: map[string]net.IOCountersStat{}}
+}
+
+func (c *InterfaceCollector) Collect() (domain.Metric, error) {
+	counters, err := net.IOCounters(true)
+	if err != nil {
+		return domain.Metric{}, err
+	}
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return domain.Metric{}, err
+	}
+	flags := make(map[string][]string, len(ifaces))
+	for _, ifc := range ifaces {
+		flags[ifc.Name] = ifc.Flags
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	elapsed := now.Sub(c.lastAt).Seconds()
+
+	current := make(map[string]net.IOCountersStat, len(counters))
+	var out []domain.InterfaceStats
+	for _, cur := range counters {
+		f, ok := flags[cur.Name]
+		if !ok || slices.Contains(f, "loopback") || !c.wants(cur.Name) {
+			continue
+		}
+		current[cur.Name] = cur
+
+		st := domain.InterfaceStats{Name: cur.Name, Up: linkUp(cur.Name, f)}
+		if prev, ok := c.last[cur.Name]; ok && elapsed > 0 {
+			st.Rates = ifaceRates(prev, cur, elapsed)
+		}
+		out = append(out, st)
+	}
+	// las interfaces que desaparecieron salen del mapa; si vuelven, su primera
+	// muestra no tiene tasas
+	c.last = current
+	c.lastAt = now
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return domain.Metric{Interfaces: out}, nil
+}
+
+func (c *InterfaceCollector) wants(name string) bool {
+	if len(c.include) > 0 && !matchAny(c.include, name) {
+		return false
+	}
+	return !matchAny(c.exclude, name)
+}
+
+// ifaceRates calcula las tasas entre dos lecturas; nil si algún contador se
+// reinició (ej. el driver se recargó).
+func ifaceRates(prev, cur net.IOCountersStat, elapsed float64) *domain.InterfaceRates {
+	ok := true
+	rate := func(p, c uint64) float64 {
+		d, valid := counterDelta(p, c)
+		if !valid {
+			ok = false
+		}
+		return float64(d) / elapsed
+	}
+	r := &domain.InterfaceRates{
+		RxBytes:   rate(prev.BytesRecv, cur.BytesRecv),
+		TxBytes:   rate(prev.BytesSent, cur.BytesSent),
+		RxPackets: rate(prev.PacketsRecv, cur.PacketsRecv),
+		TxPackets: rate(prev.PacketsSent, cur.PacketsSent),
+		RxErrors:  rate(prev.Errin, cur.Errin),
+		TxErrors:  rate(prev.Errout, cur.Errout),
+		RxDrops:   rate(prev.Dropin, cur.Dropin),
+		TxDrops:   rate(prev.Dropout, cur.Dropout),
+	}
+	if !ok {
+		return nil
+	}
+	return r
+}
+
+// counterDelta devuelve cuánto avanzó un contador. Si bajó se asume que se
+// reinició (la interfaz se recreó, el driver se recargó, ...) y la muestra no
+// sirve: la siguiente recolección parte de la lectura nueva. gopsutil entrega
+// contadores de 64 bits en todos los SO, así que no se interpreta como vuelta.
+func counterDelta(prev, cur uint64) (uint64, bool) {
+	if cur < prev {
+		return 0, false
+	}
+	return cur - prev, true
+}
+
+// linkUp usa el estado operativo del enlace en Linux (cable conectado) y el
+// flag administrativo "up" en el resto.
+func linkUp(name string, flags []string) bool {
+	if runtime.GOOS == "linux" {
+		if b, err := os.ReadFile("/sys/class/net/" + name + "/operstate"); err == nil {
+			state := strings.TrimSpace(string(b))
+			// "unknown" es habitual en túneles y algunas interfaces virtuales
+			if state != "unknown" {
+				return state == "up"
+			}
+		}
+	}
+	return slices.Contains(flags, "up")
+}
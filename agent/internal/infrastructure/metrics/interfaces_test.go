@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCounterDelta(t *testing.T) {
+	tests := []struct {
+		name      string
+		prev, cur uint64
+		want      uint64
+		wantOK    bool
+	}{
+		{"sin cambio", 100, 100, 0, true},
+		{"avanza", 100, 250, 150, true},
+		{"desde cero", 0, 42, 42, true},
+		{"cerca del tope de 64 bits", math.MaxUint64 - 10, math.MaxUint64, 10, true},
+		{"reinicio a cero", 5000, 0, 0, false},
+		{"reinicio parcial", 5000, 1200, 0, false},
+		// un contador de 32 bits que da la vuelta también se trata como reinicio
+		{"bajo el tope de 32 bits", math.MaxUint32 - 5, 10, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := counterDelta(tt.prev, tt.cur)
+			if got != tt.want || ok != tt.wantOK {
+				t.Fatalf("counterDelta(%d, %d) = (%d, %v), want (%d, %v)", tt.prev, tt.cur, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"path"
+	"slices"
+	"sort"
+
+	"github.com/shirou/gopsutil/v3/disk"
+
+	"github.com/BenjaminAGH/nocturneagent/internal/domain"
+)
+
+// pseudoFSTypes son sistemas de archivos virtuales o de sólo lectura por diseño
+// (imágenes de snaps, capas de contenedores) que no tiene sentido vigilar.
+var pseudoFSTypes = map[string]bool{
+	"autofs": true, "binfmt_misc": true, "bpf": true, "cgroup": true, "cgroup2": true,
+	"configfs": true, "debugfs": true, "devpts": true, "devtmpfs": true, "efivarfs": true,
+	"fusectl": true, "hugetlbfs": true, "mqueue": true, "nsfs": true, "overlay": true,
+	"proc": true, "pstore": true, "ramfs": true, "rpc_pipefs": true, "securityfs": true,
+	"squashfs": true, "sysfs": true, "tmpfs": true, "tracefs": true,
+}
+
+// MountCollector reporta el uso de cada sistema de archivos montado.
+type MountCollector struct {
+	include []string
+	exclude []string
+}
+
+// NewMountCollector recibe patrones glob sobre la ruta de montaje (ej. "/data*");
+// sin include se reportan todos los montajes reales.
+func NewMountCollector(include, exclude []string) *MountCollector {
+	return &MountCollector{include: include, exclude: exclude}
+}
+
+func (c *MountCollector) Collect() (domain.Metric, error) {
+	parts, err := disk.Partitions(false)
+	if err != nil {
+		return domain.Metric{}, err
+	}
+
+	// una ruta montada dos veces (un montaje encima de otro) se reporta una vez,
+	// con el último, que es el visible. Los subvolúmenes de btrfs y los bind
+	// mounts comparten dispositivo pero son rutas distintas y se reportan todos.
+	byPath := map[string]int{}
+	var mounts []domain.MountUsage
+	for _, p := range parts {
+		if pseudoFSTypes[p.Fstype] || !c.wants(p.Mountpoint) {
+			continue
+		}
+		u, err := disk.Usage(p.Mountpoint)
+		if err != nil || u.Total == 0 {
+			continue
+		}
+
+		mu := domain.MountUsage{
+			Path:        p.Mountpoint,
+			Device:      p.Device,
+			FSType:      p.Fstype,
+			UsedPercent: u.UsedPercent,
+			FreeBytes:   u.Free,
+			TotalBytes:  u.Total,
+			InodesUsed:  u.InodesUsedPercent,
+			ReadOnly:    slices.Contains(p.Opts, "ro"),
+		}
+		if i, ok := byPath[p.Mountpoint]; ok {
+			mounts[i] = mu
+			continue
+		}
+		byPath[p.Mountpoint] = len(mounts)
+		mounts = append(mounts, mu)
+	}
+
+	sort.Slice(mounts, func(i, j int) bool { return mounts[i].Path < mounts[j].Path })
+	return domain.Metric{Mounts: mounts}, nil
+}
+
+func (c *MountCollector) wants(mountpoint string) bool {
+	if len(c.include) > 0 && !matchAny(c.include, mountpoint) {
+		return false
+	}
+	return !matchAny(c.exclude, mountpoint)
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/shirou/gopsutil/v3/mem"
+	"github.com/shirou/gopsutil/v3/process"
+
+	"github.com/BenjaminAGH/nocturneagent/internal/domain"
+)
+
+const (
+	defaultTopProcs = 5
+	maxCmdlineLen   = 200
+)
+
+// cpuSample es el tiempo de CPU acumulado de un proceso; created distingue un
+// PID reutilizado por otro proceso.
+type cpuSample struct {
+	seconds float64
+	created int64
+}
+
+// ProcessCollector reporta los N procesos que más CPU usan y los N que más
+// memoria ocupan. El % de CPU sale del tiempo consumido desde la recolección
+// anterior, no del promedio de toda la vida del proceso.
+type ProcessCollector struct {
+	n int
+
+	mu     sync.Mutex
+	last   map[int32]cpuSample
+	lastAt time.Time
+}
+
+// NewProcessCollector recibe N; 0 usa el valor por defecto y un negativo desactiva el colector.
+func NewProcessCollector(n int) *ProcessCollector {
+	if n == 0 {
+		n = defaultTopProcs
+	}
+	return &ProcessCollector{n: n, last: map[int32]cpuSample{}}
+}
+
+type procUsage struct {
+	p   *process.Process
+	cpu float64
+	rss uint64
+}
+
+func (c *ProcessCollector) Collect() (domain.Metric, error) {
+	if c.n < 0 {
+		return domain.Metric{}, nil
+	}
+	procs, err := process.Processes()
+	if err != nil {
+		return domain.Metric{}, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	elapsed := now.Sub(c.lastAt).Seconds()
+
+	current := make(map[int32]cpuSample, len(procs))
+	usage := make([]procUsage, 0, len(procs))
+	for _, p := range procs {
+		t, err := p.Times()
+		if err != nil {
+			continue // el proceso terminó o no hay permisos
+		}
+		created, _ := p.CreateTime()
+		sample := cpuSample{seconds: t.User + t.System, created: created}
+		current[p.Pid] = sample
+
+		u := procUsage{p: p}
+		if prev, ok := c.last[p.Pid]; ok && prev.created == created && elapsed > 0 {
+			u.cpu = (sample.seconds - prev.seconds) / elapsed * 100
+		} else if life := now.Sub(time.UnixMilli(created)).Seconds(); created > 0 && life > 0 {
+			// primera vez que lo vemos: promedio desde que arrancó
+			u.cpu = sample.seconds / life * 100
+		}
+		if mi, err := p.MemoryInfo(); err == nil {
+			u.rss = mi.RSS
+		}
+		usage = append(usage, u)
+	}
+	c.last = current
+	c.lastAt = now
+
+	var total uint64
+	if vm, err := mem.VirtualMemory(); err == nil {
+		total = vm.Total
+	}
+
+	top := topUsage(usage, c.n)
+	out := make([]domain.ProcessInfo, 0, len(top))
+	for _, u := range top {
+		out = append(out, processInfo(u, total))
+	}
+	return domain.Metric{TopProcs: out}, nil
+}
+
+// topUsage junta los n de más CPU con los n de más memoria, sin repetir.
+func topUsage(usage []procUsage, n int) []procUsage {
+	byCPU := append([]procUsage(nil), usage...)
+	sort.Slice(byCPU, func(i, j int) bool { return byCPU[i].cpu > byCPU[j].cpu })
+	byRSS := append([]procUsage(nil), usage...)
+	sort.Slice(byRSS, func(i, j int) bool { return byRSS[i].rss > byRSS[j].rss })
+
+	var out []procUsage
+	seen := map[int32]bool{}
+	add := func(u procUsage) {
+		if !seen[u.p.Pid] {
+			seen[u.p.Pid] = true
+			out = append(out, u)
+		}
+	}
+	// los procesos ociosos (o hilos de kernel sin memoria) no se listan
+	for i := 0; i < n && i < len(byCPU) && byCPU[i].cpu > 0; i++ {
+		add(byCPU[i])
+	}
+	for i := 0; i < n && i < len(byRSS) && byRSS[i].rss > 0; i++ {
+		add(byRSS[i])
+	}
+	return out
+}
+
+func processInfo(u procUsage, totalMem uint64) domain.ProcessInfo {
+	info := domain.ProcessInfo{
+		PID:        u.p.Pid,
+		CPUPercent: u.cpu,
+		RSSBytes:   u.rss,
+	}
+	info.Name, _ = u.p.Name()
+	info.User, _ = u.p.Username()
+	if totalMem > 0 {
+		info.MemPercent = float64(u.rss) / float64(totalMem) * 100
+	}
+	if cmd, err := u.p.Cmdline(); err == nil {
+		info.Cmdline = truncate(cmd, maxCmdlineLen)
+	}
+	return info
+}
+
+// truncate corta s a max bytes sin partir un carácter UTF-8.
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "…"
+}
@@ -0,0 +1,146 @@
+package metrics
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/load"
+	"github.com/shirou/gopsutil/v3/mem"
+
+	"github.com/BenjaminAGH/nocturneagent/internal/domain"
+)
+
+const pressureDir = "/proc/pressure"
+
+// psiTotal es el tiempo acumulado de espera (µs) de una línea de PSI,
+// ej. "memory full"; avg60 se usa cuando aún no hay lectura anterior.
+type psiTotal struct {
+	total uint64
+	avg60 float64
+}
+
+// SaturationCollector reporta la carga, el swap, la presión (PSI) y la
+// actividad del planificador. La presión y las tasas salen de la diferencia
+// con la recolección anterior.
+type SaturationCollector struct {
+	mu        sync.Mutex
+	lastPSI   map[string]psiTotal
+	lastCtxt  uint64
+	lastForks uint64
+	lastAt    time.Time
+}
+
+func NewSaturationCollector() *SaturationCollector {
+	return &SaturationCollector{}
+}
+
+func (c *SaturationCollector) Collect() (domain.Metric, error) {
+	var m domain.Metric
+
+	if avg, err := load.Avg(); err == nil {
+		m.Load = &domain.LoadAvg{Load1: avg.Load1, Load5: avg.Load5, Load15: avg.Load15}
+	}
+	if sw, err := mem.SwapMemory(); err == nil {
+		m.Swap = &domain.SwapUsage{UsedPercent: sw.UsedPercent, UsedBytes: sw.Used, TotalBytes: sw.Total}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	elapsed := now.Sub(c.lastAt).Seconds()
+	first := c.lastAt.IsZero()
+
+	psi := readPressure()
+	if len(psi) > 0 {
+		m.Pressure = c.pressure(psi, elapsed, first)
+	}
+	c.lastPSI = psi
+
+	// ctxt y processes de /proc/stat; en otros SO load.Misc falla y se omiten
+	if misc, err := load.Misc(); err == nil {
+		ctxt, forks := uint64(misc.Ctxt), uint64(misc.ProcsCreated)
+		if !first && elapsed > 0 {
+			if d, ok := counterDelta(c.lastCtxt, ctxt); ok {
+				r := float64(d) / elapsed
+				m.CtxSwitchRate = &r
+			}
+			if d, ok := counterDelta(c.lastForks, forks); ok {
+				r := float64(d) / elapsed
+				m.ForkRate = &r
+			}
+		}
+		c.lastCtxt, c.lastForks = ctxt, forks
+	}
+
+	c.lastAt = now
+	return m, nil
+}
+
+// pressure calcula el % del intervalo con tareas esperando; en la primera
+// muestra (o si el contador retrocedió) usa el promedio de 60 s del kernel.
+func (c *SaturationCollector) pressure(cur map[string]psiTotal, elapsed float64, first bool) *domain.Pressure {
+	pct := func(key string) float64 {
+		now, ok := cur[key]
+		if !ok {
+			return 0
+		}
+		prev, seen := c.lastPSI[key]
+		if first || !seen || elapsed <= 0 || now.total < prev.total {
+			return now.avg60
+		}
+		v := float64(now.total-prev.total) / (elapsed * 1e6) * 100
+		if v > 100 {
+			v = 100
+		}
+		return v
+	}
+	return &domain.Pressure{
+		CPUSome:    pct("cpu some"),
+		MemorySome: pct("memory some"),
+		MemoryFull: pct("memory full"),
+		IOSome:     pct("io some"),
+		IOFull:     pct("io full"),
+	}
+}
+
+// readPressure lee /proc/pressure/{cpu,memory,io}; vacío si el kernel no
+// tiene PSI (anterior a 4.20 o desactivado con psi=0).
+func readPressure() map[string]psiTotal {
+	out := map[string]psiTotal{}
+	for _, res := range []string{"cpu", "memory", "io"} {
+		f, err := os.Open(filepath.Join(pressureDir, res))
+		if err != nil {
+			continue
+		}
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			// some avg10=0.00 avg60=0.00 avg300=0.00 total=123456
+			fields := strings.Fields(sc.Text())
+			if len(fields) == 0 {
+				continue
+			}
+			var t psiTotal
+			for _, kv := range fields[1:] {
+				k, v, ok := strings.Cut(kv, "=")
+				if !ok {
+					continue
+				}
+				switch k {
+				case "avg60":
+					t.avg60, _ = strconv.ParseFloat(v, 64)
+				case "total":
+					t.total, _ = strconv.ParseUint(v, 10, 64)
+				}
+			}
+			out[res+" "+fields[0]] = t
+		}
+		f.Close()
+	}
+	return out
+}
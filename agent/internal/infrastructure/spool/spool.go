@@ -0,0 +1,471 @@
+package spool
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BenjaminAGH/nocturneagent/internal/domain"
+)
+
+const (
+	segmentExt     = ".seg"
+	cursorFile     = "cursor.json"
+	deadLetterFile = "dead-letter.ndjson"
+
+	defaultSegmentBytes = 4 << 20
+	maxDeadLetterBytes  = 4 << 20
+)
+
+// Options configura el spool. Los valores en cero toman un default razonable
+// (MaxBytes y MaxAge en cero desactivan el tope correspondiente).
+type Options struct {
+	Dir          string
+	SegmentBytes int64         // tamaño máximo de cada segmento
+	MaxBytes     int64         // tope total en disco
+	MaxAge       time.Duration // antigüedad máxima de un segmento
+	Silent       bool
+}
+
+// Spool es un write-ahead log segmentado para métricas que aún no llegan al
+// backend. Cada segmento es un archivo NDJSON (una métrica por línea) y un
+// cursor persistido indica hasta dónde se confirmó el envío, así el contenido
+// sobrevive a reinicios del proceso. Al superar los topes se descartan los
+// segmentos más antiguos primero.
+type Spool struct {
+	mu   sync.Mutex
+	opts Options
+
+	segs    []segment
+	active  *os.File // segmento abierto para escritura (el último)
+	nextSeq uint64
+	cur     position
+
+	// posiciones de la última lectura con Peek, para confirmar con Ack
+	peeked  []position
+	peekEnd position
+}
+
+type segment struct {
+	seq     uint64
+	size    int64
+	modTime time.Time
+}
+
+type position struct {
+	Segment uint64 `json:"segment"`
+	Offset  int64  `json:"offset"`
+}
+
+func (p position) before(o position) bool {
+	if p.Segment != o.Segment {
+		return p.Segment < o.Segment
+	}
+	return p.Offset < o.Offset
+}
+
+// Open abre (o crea) el spool en opts.Dir y recupera el estado anterior.
+func Open(opts Options) (*Spool, error) {
+	if opts.Dir == "" {
+		return nil, errors.New("spool: directorio vacío")
+	}
+	if opts.SegmentBytes <= 0 {
+		opts.SegmentBytes = defaultSegmentBytes
+	}
+	if err := os.MkdirAll(opts.Dir, 0700); err != nil {
+		return nil, err
+	}
+
+	s := &Spool{opts: opts, nextSeq: 1}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	s.evict(time.Now())
+	return s, nil
+}
+
+func (s *Spool) load() error {
+	entries, err := os.ReadDir(s.opts.Dir)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return err
+		}
+		s.segs = append(s.segs, segment{seq: seq, size: info.Size(), modTime: info.ModTime()})
+	}
+	sort.Slice(s.segs, func(i, j int) bool { return s.segs[i].seq < s.segs[j].seq })
+
+	if n := len(s.segs); n > 0 {
+		s.nextSeq = s.segs[n-1].seq + 1
+	}
+
+	if data, err := os.ReadFile(filepath.Join(s.opts.Dir, cursorFile)); err == nil {
+		_ = json.Unmarshal(data, &s.cur)
+	}
+
+	// segmentos anteriores al cursor ya fueron enviados
+	for len(s.segs) > 0 && s.segs[0].seq < s.cur.Segment {
+		_ = os.Remove(s.segPath(s.segs[0].seq))
+		s.segs = s.segs[1:]
+	}
+	s.normalize()
+
+	// un corte de energía puede dejar una línea a medias al final del último segmento
+	if n := len(s.segs); n > 0 {
+		if err := s.repairTail(&s.segs[n-1]); err != nil {
+			return err
+		}
+		last := s.segs[n-1]
+		if last.size < s.opts.SegmentBytes {
+			f, err := os.OpenFile(s.segPath(last.seq), os.O_WRONLY|os.O_APPEND, 0600)
+			if err != nil {
+				return err
+			}
+			s.active = f
+		}
+	}
+	return nil
+}
+
+// repairTail trunca el segmento hasta el último salto de línea completo.
+func (s *Spool) repairTail(seg *segment) error {
+	if seg.size == 0 {
+		return nil
+	}
+	f, err := os.OpenFile(s.segPath(seg.seq), os.O_RDWR, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+	keep := int64(strings.LastIndexByte(string(data), '\n') + 1)
+	if keep == int64(len(data)) {
+		return nil
+	}
+	if err := f.Truncate(keep); err != nil {
+		return err
+	}
+	seg.size = keep
+	if s.cur.Segment == seg.seq && s.cur.Offset > keep {
+		s.cur.Offset = keep
+	}
+	return nil
+}
+
+// Append agrega una métrica al final del spool.
+func (s *Spool) Append(m domain.Metric) error {
+	line, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.active == nil || s.segs[len(s.segs)-1].size+int64(len(line)) > s.opts.SegmentBytes {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.active.Write(line); err != nil {
+		return err
+	}
+	if err := s.active.Sync(); err != nil {
+		return err
+	}
+
+	last := &s.segs[len(s.segs)-1]
+	last.size += int64(len(line))
+	last.modTime = time.Now()
+
+	s.evict(last.modTime)
+	return nil
+}
+
+func (s *Spool) rotate() error {
+	if s.active != nil {
+		_ = s.active.Close()
+		s.active = nil
+	}
+
+	seq := s.nextSeq
+	f, err := os.OpenFile(s.segPath(seq), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	s.nextSeq++
+	s.active = f
+	s.segs = append(s.segs, segment{seq: seq, modTime: time.Now()})
+	s.normalize()
+	return nil
+}
+
+// Peek devuelve hasta n métricas pendientes, de la más antigua a la más nueva,
+// sin consumirlas. Se confirman con Ack una vez enviadas.
+func (s *Spool) Peek(n int) ([]domain.Metric, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evict(time.Now())
+	s.peeked = s.peeked[:0]
+
+	out := make([]domain.Metric, 0, n)
+	pos := s.cur
+	for _, seg := range s.segs {
+		if seg.seq < pos.Segment {
+			continue
+		}
+		if seg.seq > pos.Segment {
+			pos = position{Segment: seg.seq}
+		}
+		if len(out) >= n {
+			break
+		}
+		if pos.Offset >= seg.size {
+			continue
+		}
+
+		var err error
+		out, pos, err = s.readSegment(seg, pos, n, out)
+		if err != nil {
+			return nil, err
+		}
+	}
+	s.peekEnd = pos
+
+	// sólo había líneas corruptas: se saltan para no bloquear la cola
+	if len(out) == 0 && s.cur.before(pos) {
+		if err := s.advance(pos); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func (s *Spool) readSegment(seg segment, pos position, n int, out []domain.Metric) ([]domain.Metric, position, error) {
+	f, err := os.Open(s.segPath(seg.seq))
+	if err != nil {
+		return out, pos, err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(pos.Offset, io.SeekStart); err != nil {
+		return out, pos, err
+	}
+
+	r := bufio.NewReader(f)
+	for len(out) < n && pos.Offset < seg.size {
+		line, _ := r.ReadBytes('\n')
+		if len(line) == 0 || line[len(line)-1] != '\n' {
+			break // fin de lo escrito
+		}
+		pos.Offset += int64(len(line))
+
+		var m domain.Metric
+		if err := json.Unmarshal(line, &m); err != nil {
+			continue
+		}
+		out = append(out, m)
+		s.peeked = append(s.peeked, pos)
+	}
+	return out, pos, nil
+}
+
+// Ack confirma las primeras n métricas devueltas por el último Peek.
+func (s *Spool) Ack(n int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if n <= 0 || len(s.peeked) == 0 {
+		return nil
+	}
+
+	target := s.peekEnd
+	if n < len(s.peeked) {
+		target = s.peeked[n-1]
+	}
+	s.peeked = s.peeked[:0]
+
+	if !s.cur.before(target) {
+		return nil
+	}
+	return s.advance(target)
+}
+
+// advance mueve el cursor y elimina los segmentos ya consumidos.
+func (s *Spool) advance(target position) error {
+	s.cur = target
+
+	for len(s.segs) > 0 {
+		head := s.segs[0]
+		done := head.seq < s.cur.Segment || (head.seq == s.cur.Segment && s.cur.Offset >= head.size)
+		if !done {
+			break
+		}
+		if len(s.segs) == 1 && s.active != nil {
+			_ = s.active.Close()
+			s.active = nil
+		}
+		s.dropHead()
+	}
+	s.normalize()
+
+	return s.saveCursor()
+}
+
+// deadLetter es una métrica que el backend rechazó de forma permanente.
+type deadLetter struct {
+	Time   time.Time     `json:"time"`
+	Reason string        `json:"reason"`
+	Metric domain.Metric `json:"metric"`
+}
+
+// DeadLetter aparta una métrica que el backend rechazó (dead-letter.ndjson en
+// el directorio del spool) para poder revisarla; no se vuelve a enviar. Al
+// pasar el tope el archivo se rota a dead-letter.ndjson.1.
+func (s *Spool) DeadLetter(m domain.Metric, reason string) error {
+	line, err := json.Marshal(deadLetter{Time: time.Now().UTC(), Reason: reason, Metric: m})
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := filepath.Join(s.opts.Dir, deadLetterFile)
+	if info, err := os.Stat(path); err == nil && info.Size()+int64(len(line)) > maxDeadLetterBytes {
+		_ = os.Rename(path, path+".1")
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(line)
+	return err
+}
+
+// Empty indica si no quedan métricas pendientes.
+func (s *Spool) Empty() bool {
+	return s.Size() == 0
+}
+
+// Size devuelve los bytes pendientes de envío.
+func (s *Spool) Size() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.totalBytes() - s.cur.Offset
+}
+
+// Close cierra el segmento activo y persiste el cursor.
+func (s *Spool) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.active != nil {
+		_ = s.active.Close()
+		s.active = nil
+	}
+	return s.saveCursor()
+}
+
+// evict aplica los topes de tamaño y antigüedad descartando desde el segmento más antiguo.
+func (s *Spool) evict(now time.Time) {
+	dropped := 0
+
+	if s.opts.MaxAge > 0 {
+		for len(s.segs) > 0 && now.Sub(s.segs[0].modTime) > s.opts.MaxAge {
+			if len(s.segs) == 1 && s.active != nil {
+				_ = s.active.Close()
+				s.active = nil
+			}
+			s.dropHead()
+			dropped++
+		}
+	}
+
+	if s.opts.MaxBytes > 0 {
+		for len(s.segs) > 1 && s.totalBytes() > s.opts.MaxBytes {
+			s.dropHead()
+			dropped++
+		}
+	}
+
+	if dropped > 0 {
+		s.normalize()
+		_ = s.saveCursor()
+	}
+	if dropped > 0 && !s.opts.Silent {
+		fmt.Printf("spool lleno o vencido: se descartaron %d segmentos antiguos\n", dropped)
+	}
+}
+
+func (s *Spool) dropHead() {
+	_ = os.Remove(s.segPath(s.segs[0].seq))
+	s.segs = s.segs[1:]
+}
+
+// normalize deja el cursor apuntando a un segmento existente (o al próximo a crear).
+func (s *Spool) normalize() {
+	if len(s.segs) == 0 {
+		s.cur = position{Segment: s.nextSeq}
+		return
+	}
+	if s.cur.Segment < s.segs[0].seq {
+		s.cur = position{Segment: s.segs[0].seq}
+	}
+}
+
+func (s *Spool) totalBytes() int64 {
+	var total int64
+	for _, seg := range s.segs {
+		total += seg.size
+	}
+	return total
+}
+
+func (s *Spool) saveCursor() error {
+	data, err := json.Marshal(s.cur)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(s.opts.Dir, cursorFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *Spool) segPath(seq uint64) string {
+	return filepath.Join(s.opts.Dir, fmt.Sprintf("%016d%s", seq, segmentExt))
+}
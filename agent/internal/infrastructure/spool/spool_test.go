@@ -0,0 +1,362 @@
+package spool
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BenjaminAGH/nocturneagent/internal/domain"
+)
+
+func metric(i int) domain.Metric {
+	return domain.Metric{DeviceName: fmt.Sprintf("dev-%02d", i)}
+}
+
+// lineSize es lo que ocupa en disco una métrica de metric(i).
+func lineSize(t *testing.T) int64 {
+	t.Helper()
+	b, err := json.Marshal(metric(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return int64(len(b)) + 1
+}
+
+func openSpool(t *testing.T, opts Options) *Spool {
+	t.Helper()
+	opts.Silent = true
+	s, err := Open(opts)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	return s
+}
+
+func appendN(t *testing.T, s *Spool, from, n int) {
+	t.Helper()
+	for i := from; i < from+n; i++ {
+		if err := s.Append(metric(i)); err != nil {
+			t.Fatalf("Append(%d): %v", i, err)
+		}
+	}
+}
+
+func names(ms []domain.Metric) []string {
+	out := make([]string, len(ms))
+	for i, m := range ms {
+		out[i] = m.DeviceName
+	}
+	return out
+}
+
+func expect(t *testing.T, s *Spool, want ...int) {
+	t.Helper()
+	got, err := s.Peek(100)
+	if err != nil {
+		t.Fatalf("Peek: %v", err)
+	}
+	wantNames := make([]string, len(want))
+	for i, w := range want {
+		wantNames[i] = metric(w).DeviceName
+	}
+	if strings.Join(names(got), ",") != strings.Join(wantNames, ",") {
+		t.Fatalf("Peek = %v, want %v", names(got), wantNames)
+	}
+}
+
+func segFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestSegmentRollover(t *testing.T) {
+	line := lineSize(t)
+
+	tests := []struct {
+		name     string
+		perSeg   int64 // líneas que caben en un segmento
+		appended int
+		wantSegs int
+	}{
+		{"un segmento", 10, 4, 1},
+		{"justo lleno", 2, 4, 2},
+		{"una línea por segmento", 1, 3, 3},
+		{"resto en segmento nuevo", 2, 5, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			s := openSpool(t, Options{Dir: dir, SegmentBytes: tt.perSeg * line})
+			defer s.Close()
+
+			appendN(t, s, 0, tt.appended)
+
+			if got := len(segFiles(t, dir)); got != tt.wantSegs {
+				t.Fatalf("segmentos = %d, want %d", got, tt.wantSegs)
+			}
+			want := make([]int, tt.appended)
+			for i := range want {
+				want[i] = i
+			}
+			expect(t, s, want...)
+			if got := s.Size(); got != int64(tt.appended)*line {
+				t.Fatalf("Size = %d, want %d", got, int64(tt.appended)*line)
+			}
+		})
+	}
+}
+
+func TestCursorRecovery(t *testing.T) {
+	line := lineSize(t)
+
+	tests := []struct {
+		name     string
+		perSeg   int64
+		appended int
+		peek     int
+		ack      int
+		want     []int
+		wantSegs int
+	}{
+		{"sin ack", 10, 3, 3, 0, []int{0, 1, 2}, 1},
+		{"ack parcial", 10, 5, 3, 2, []int{2, 3, 4}, 1},
+		{"ack de todo lo leído", 10, 5, 3, 3, []int{3, 4}, 1},
+		{"ack mayor a lo leído", 10, 3, 2, 5, []int{2}, 1},
+		{"ack cruza segmentos", 2, 5, 4, 3, []int{3, 4}, 2},
+		{"ack vacía el spool", 2, 4, 4, 4, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			opts := Options{Dir: dir, SegmentBytes: tt.perSeg * line}
+
+			s := openSpool(t, opts)
+			appendN(t, s, 0, tt.appended)
+			if _, err := s.Peek(tt.peek); err != nil {
+				t.Fatalf("Peek: %v", err)
+			}
+			if err := s.Ack(tt.ack); err != nil {
+				t.Fatalf("Ack: %v", err)
+			}
+			if err := s.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			s = openSpool(t, opts)
+			defer s.Close()
+
+			expect(t, s, tt.want...)
+			if got := len(segFiles(t, dir)); got != tt.wantSegs {
+				t.Fatalf("segmentos = %d, want %d", got, tt.wantSegs)
+			}
+			if got := s.Empty(); got != (len(tt.want) == 0) {
+				t.Fatalf("Empty = %v", got)
+			}
+
+			// lo que se agrega después del reinicio va detrás de lo pendiente
+			appendN(t, s, tt.appended, 1)
+			expect(t, s, append(tt.want, tt.appended)...)
+		})
+	}
+}
+
+func TestRepairTail(t *testing.T) {
+	line := lineSize(t)
+
+	tests := []struct {
+		name     string
+		tail     string
+		ack      int  // métricas confirmadas antes del corte
+		pastTail bool // el cursor guardado apunta dentro de la línea rota
+		want     []int
+	}{
+		{"sin daño", "", 0, false, []int{0, 1, 2}},
+		{"línea a medias", `{"device_name":"dev-`, 0, false, []int{0, 1, 2}},
+		{"sólo una llave", "{", 1, false, []int{1, 2}},
+		{"cursor más allá del corte", `{"device_name":"dev-03"`, 0, true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			opts := Options{Dir: dir, SegmentBytes: 100 * line}
+
+			s := openSpool(t, opts)
+			appendN(t, s, 0, 3)
+			if tt.ack > 0 {
+				if _, err := s.Peek(tt.ack); err != nil {
+					t.Fatal(err)
+				}
+				if err := s.Ack(tt.ack); err != nil {
+					t.Fatal(err)
+				}
+			}
+			s.Close()
+
+			// simula el corte de energía durante una escritura
+			seg := s.segPath(1)
+			f, err := os.OpenFile(seg, os.O_WRONLY|os.O_APPEND, 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.WriteString(tt.tail)
+			f.Close()
+			if tt.pastTail {
+				s.cur = position{Segment: 1, Offset: 3*line + int64(len(tt.tail))}
+				if err := s.saveCursor(); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			s = openSpool(t, opts)
+			defer s.Close()
+
+			info, err := os.Stat(seg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Size() != 3*line {
+				t.Fatalf("tamaño tras reparar = %d, want %d", info.Size(), 3*line)
+			}
+			expect(t, s, tt.want...)
+
+			// la próxima línea no queda pegada a los restos
+			appendN(t, s, 9, 1)
+			expect(t, s, append(tt.want, 9)...)
+		})
+	}
+}
+
+func TestSkipsCorruptLines(t *testing.T) {
+	dir := t.TempDir()
+	s := openSpool(t, Options{Dir: dir})
+	appendN(t, s, 0, 1)
+	s.Close()
+
+	f, err := os.OpenFile(s.segPath(1), os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("no es json\n")
+	f.Close()
+
+	s = openSpool(t, Options{Dir: dir})
+	defer s.Close()
+	appendN(t, s, 1, 1)
+	expect(t, s, 0, 1)
+
+	if err := s.Ack(2); err != nil {
+		t.Fatal(err)
+	}
+	if !s.Empty() {
+		t.Fatalf("Empty = false tras confirmar todo, Size = %d", s.Size())
+	}
+}
+
+func TestEviction(t *testing.T) {
+	line := lineSize(t)
+
+	tests := []struct {
+		name     string
+		maxLines int64         // tope en líneas (MaxBytes)
+		maxAge   time.Duration // tope de antigüedad
+		oldSegs  int           // segmentos que se envejecen antes de reabrir
+		want     []int
+	}{
+		{"sin topes", 0, 0, 0, []int{0, 1, 2, 3, 4}},
+		{"tope de bytes", 3, 0, 0, []int{2, 3, 4}},
+		{"tope de bytes mayor al contenido", 10, 0, 0, []int{0, 1, 2, 3, 4}},
+		{"tope de antigüedad", 0, time.Hour, 2, []int{2, 3, 4}},
+		{"antigüedad vence todo", 0, time.Hour, 5, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			opts := Options{Dir: dir, SegmentBytes: line, MaxBytes: tt.maxLines * line, MaxAge: tt.maxAge}
+
+			s := openSpool(t, opts)
+			appendN(t, s, 0, 5)
+			s.Close()
+
+			old := time.Now().Add(-2 * time.Hour)
+			for i := 0; i < tt.oldSegs; i++ {
+				if err := os.Chtimes(s.segPath(uint64(i+1)), old, old); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			s = openSpool(t, opts)
+			defer s.Close()
+			expect(t, s, tt.want...)
+			if got := len(segFiles(t, dir)); got != len(tt.want) {
+				t.Fatalf("segmentos = %d, want %d", got, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestDeadLetter(t *testing.T) {
+	tests := []struct {
+		name        string
+		existing    int64 // tamaño previo de dead-letter.ndjson
+		wantRotated bool
+	}{
+		{"archivo nuevo", 0, false},
+		{"bajo el tope", 1024, false},
+		{"rota al pasar el tope", maxDeadLetterBytes, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			s := openSpool(t, Options{Dir: dir})
+			defer s.Close()
+
+			path := filepath.Join(dir, deadLetterFile)
+			if tt.existing > 0 {
+				filler := strings.Repeat("x", int(tt.existing)-1) + "\n"
+				if err := os.WriteFile(path, []byte(filler), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := s.DeadLetter(metric(7), "HTTP 422"); err != nil {
+				t.Fatalf("DeadLetter: %v", err)
+			}
+
+			_, err := os.Stat(path + ".1")
+			if rotated := err == nil; rotated != tt.wantRotated {
+				t.Fatalf("rotado = %v, want %v", rotated, tt.wantRotated)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+			var got deadLetter
+			if err := json.Unmarshal([]byte(lines[len(lines)-1]), &got); err != nil {
+				t.Fatalf("línea inválida: %v", err)
+			}
+			if got.Reason != "HTTP 422" || got.Metric.DeviceName != metric(7).DeviceName {
+				t.Fatalf("dead letter = %+v", got)
+			}
+
+			// lo apartado no vuelve a la cola
+			if !s.Empty() {
+				t.Fatal("la métrica apartada quedó pendiente")
+			}
+		})
+	}
+}
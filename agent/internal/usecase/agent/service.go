@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/BenjaminAGH/nocturneagent/internal/domain"
+	"github.com/BenjaminAGH/nocturneagent/internal/version"
 )
 
 type Collector interface {
@@ -54,7 +55,7 @@ func (s *Service) Stop() {
 }
 
 func (s *Service) runOnce() {
-	base := domain.Metric{}
+	base := domain.Metric{AgentVersion: version.Version}
 	for _, c := range s.collectors {
 		m, err := c.Collect()
 		if err != nil {
@@ -98,6 +99,33 @@ func merge(dst, src domain.Metric) domain.Metric {
 	if len(src.CPUPerCore) > 0 {
 		dst.CPUPerCore = src.CPUPerCore
 	}
+	if len(src.Mounts) > 0 {
+		dst.Mounts = src.Mounts
+	}
+	if len(src.Interfaces) > 0 {
+		dst.Interfaces = src.Interfaces
+	}
+	if len(src.DiskIO) > 0 {
+		dst.DiskIO = src.DiskIO
+	}
+	if len(src.TopProcs) > 0 {
+		dst.TopProcs = src.TopProcs
+	}
+	if src.Load != nil {
+		dst.Load = src.Load
+	}
+	if src.Swap != nil {
+		dst.Swap = src.Swap
+	}
+	if src.Pressure != nil {
+		dst.Pressure = src.Pressure
+	}
+	if src.CtxSwitchRate != nil {
+		dst.CtxSwitchRate = src.CtxSwitchRate
+	}
+	if src.ForkRate != nil {
+		dst.ForkRate = src.ForkRate
+	}
 	if src.UptimeSec != 0 {
 		dst.UptimeSec = src.UptimeSec
 	}
@@ -4,6 +4,7 @@ import (
 	"log"
 
 	"github.com/BenjaminAGH/nocturneagent/internal/domain"
+	"github.com/BenjaminAGH/nocturneagent/internal/version"
 )
 
 type Collector interface {
@@ -27,7 +28,7 @@ func NewService(collectors []Collector, backend BackendSink) *Service {
 }
 
 func (s *Service) RunOnce() {
-	base := domain.Metric{}
+	base := domain.Metric{AgentVersion: version.Version}
 
 	for _, c := range s.collectors {
 		m, err := c.Collect()
@@ -86,6 +87,32 @@ func mergeMetric(dst, src domain.Metric) domain.Metric {
 	if len(src.TopProcs) > 0 {
 		dst.TopProcs = src.TopProcs
 	}
+	if len(src.Mounts) > 0 {
+		dst.Mounts = src.Mounts
+	}
+	if len(src.Interfaces) > 0 {
+		dst.Interfaces = src.Interfaces
+	}
+	if len(src.DiskIO) > 0 {
+		dst.DiskIO = src.DiskIO
+	}
+
+	// punteros (nil = el colector no lo reportó)
+	if src.Load != nil {
+		dst.Load = src.Load
+	}
+	if src.Swap != nil {
+		dst.Swap = src.Swap
+	}
+	if src.Pressure != nil {
+		dst.Pressure = src.Pressure
+	}
+	if src.CtxSwitchRate != nil {
+		dst.CtxSwitchRate = src.CtxSwitchRate
+	}
+	if src.ForkRate != nil {
+		dst.ForkRate = src.ForkRate
+	}
 
 	// uints
 	if src.UptimeSec != 0 {
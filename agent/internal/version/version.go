@@ -0,0 +1,5 @@
+package version
+
+// Version del agente. Se reemplaza al compilar con
+// -ldflags "-X github.com/BenjaminAGH/nocturneagent/internal/version.Version=v1.2.3".
+var Version = "dev"
@@ -21,12 +21,17 @@ func main() {
 	_ = godotenv.Load("config/.env")
 
 	db := database.Connect()
-	influxWriter := database.NewInfluxFromEnv()
+	metricStore := database.NewMetricStoreFromEnv(db)
 
 	// repos
 	userRepo := repository.NewUserGormRepository(db)
 	apiTokenRepo := repository.NewAPITokenGormRepository(db)
 	topologyRepo := repository.NewTopologyGormRepository(db)
+	deviceRepo := repository.NewDeviceGormRepository(db)
+	alertEventRepo := repository.NewAlertEventGormRepository(db)
+	deadLetterRepo := repository.NewMailDeadLetterGormRepository(db)
+	silenceRepo := repository.NewSilenceGormRepository(db)
+	processRepo := repository.NewProcessSnapshotGormRepository(db)
 
 	// servicios
 	userService := service.NewUserService(userRepo)
@@ -35,10 +40,13 @@ func main() {
 	sessionStore := session.NewMemoryStore()
 	authService := service.NewAuthService(userRepo, jwtService, sessionStore)
 
-	alertService := service.NewAlertService()
-	metricService := service.NewMetricService(influxWriter, alertService)
+	alertService := service.NewAlertService(alertEventRepo, deadLetterRepo)
+	deviceService := service.NewDeviceService(deviceRepo)
+	processService := service.NewProcessService(processRepo)
+	metricService := service.NewMetricService(metricStore, deviceService, processService, alertService)
 	apiTokenService := service.NewTokenService(apiTokenRepo)
 	topologyService := service.NewTopologyService(topologyRepo, alertService)
+	silenceService := service.NewSilenceService(silenceRepo, userRepo, alertService)
 
 	app := fiber.New()
 
@@ -66,7 +74,13 @@ func main() {
 		fmt.Printf("Error loading alert rules: %v\n", err)
 	}
 
-	httpRoutes.Register(app, userService, authService, jwtService, metricService, apiTokenService, topologyService, alertService)
+	if err := silenceService.LoadSilences(); err != nil {
+		fmt.Printf("Error loading silences: %v\n", err)
+	}
+
+	alertService.StartHeartbeatMonitor()
+
+	httpRoutes.Register(app, userService, authService, jwtService, metricService, deviceService, apiTokenService, topologyService, alertService, silenceService)
 
 	log.Fatal(app.Listen(":3000"))
 }
@@ -1,24 +1,184 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 type AlertRule struct {
 	ID              string
 	TopologyID      uint
+	TopologyName    string
+	UserID          uint   // owner of the topology
 	DeviceID        string // The device name/ID to monitor
-	Metric          string // cpu, ram, disk, temp
+	Metric          string // cpu, ram, disk, temp, heartbeat; composite when Expr is set
+	Instance        string // per-instance metrics: which one, e.g. the mount "/data" or "eth0"; empty for the worst
 	Operator        string // >, >=, <, <=, ==
 	Threshold       float64
-	EmailTo         string
-	EmailSubject    string
-	EmailBody       string
-	Cooldown        string // e.g., "5m", "1h"
+	Aggregation     string        // last (default), avg, min or max of the metric over Window
+	Outputs         []AlertOutput // every notification node connected to the action
+	Window          string        // heartbeat: time without data before alerting; aggregations: span, e.g. "5m"
+	For             string        // how long the condition must hold before firing, e.g. "2m"
+	Expr            *AlertExpr    // composite rules: expression over other rules' conditions
+	Upstream        []string      // devices DeviceID depends on (topology edges into its node)
+	Downstream      []string      // devices depending on DeviceID, directly or not
 	LastTriggeredAt time.Time
 }
 
+// Aggregations a rule can apply over its window.
+const (
+	AggLast = "last"
+	AggAvg  = "avg"
+	AggMin  = "min"
+	AggMax  = "max"
+)
+
+// AlertExpr is a node of a composite rule's expression tree. A leaf wraps the
+// condition of an action node; the other nodes combine their arguments.
+type AlertExpr struct {
+	Op   string // and, or, not; empty for a leaf
+	Args []*AlertExpr
+	Cond *AlertRule
+}
+
+// Logic operators of a composite rule.
+const (
+	ExprAnd = "and"
+	ExprOr  = "or"
+	ExprNot = "not"
+)
+
+// Leaves returns the conditions the expression reads, each once.
+func (e *AlertExpr) Leaves() []*AlertRule {
+	var out []*AlertRule
+	seen := make(map[string]bool)
+	var walk func(e *AlertExpr)
+	walk = func(e *AlertExpr) {
+		if e.Cond != nil {
+			if !seen[e.Cond.ID] {
+				seen[e.Cond.ID] = true
+				out = append(out, e.Cond)
+			}
+			return
+		}
+		for _, a := range e.Args {
+			walk(a)
+		}
+	}
+	walk(e)
+	return out
+}
+
+// Devices returns the devices the expression reads, in order of appearance.
+func (e *AlertExpr) Devices() []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, c := range e.Leaves() {
+		if !seen[c.DeviceID] {
+			seen[c.DeviceID] = true
+			out = append(out, c.DeviceID)
+		}
+	}
+	return out
+}
+
+// String renders the expression, e.g. "(srv1 cpu > 90 AND srv1 ram > 90)".
+func (e *AlertExpr) String() string {
+	if e.Cond != nil {
+		c := e.Cond
+		metric := c.Metric
+		if c.Instance != "" {
+			metric += "[" + c.Instance + "]"
+		}
+		switch {
+		case c.Metric == "heartbeat":
+			return fmt.Sprintf("%s offline", c.DeviceID)
+		case c.Aggregation != "" && c.Aggregation != AggLast:
+			return fmt.Sprintf("%s %s(%s, %s) %s %g", c.DeviceID, c.Aggregation, metric, c.Window, c.Operator, c.Threshold)
+		default:
+			return fmt.Sprintf("%s %s %s %g", c.DeviceID, metric, c.Operator, c.Threshold)
+		}
+	}
+	if e.Op == ExprNot && len(e.Args) == 1 {
+		return "NOT " + e.Args[0].String()
+	}
+	parts := make([]string, len(e.Args))
+	for i, a := range e.Args {
+		parts[i] = a.String()
+	}
+	return "(" + strings.Join(parts, " "+strings.ToUpper(e.Op)+" ") + ")"
+}
+
+// AlertOutput is one notification node fed by a rule. Each output keeps its
+// own cooldown, so a chatty channel doesn't mute the others.
+type AlertOutput struct {
+	NodeID   string
+	Channel  string   // email (default), webhook, slack, discord or ntfy
+	To       []string // email recipients
+	Cc       []string
+	Bcc      []string
+	Subject  string // text/template, see notify.TemplateContext
+	Body     string // text/template
+	HTML     string // html/template; sent as a multipart alternative when set
+	Webhook  WebhookConfig
+	Cooldown string // e.g., "5m", "1h"
+}
+
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+	ChannelSlack   = "slack"
+	ChannelDiscord = "discord"
+	ChannelNtfy    = "ntfy"
+)
+
+// WebhookConfig is the delivery configuration of an HTTP output node
+// (webhook, slack, discord or ntfy).
+type WebhookConfig struct {
+	URL     string
+	Method  string // POST by default
+	Headers map[string]string
+	Secret  string // HMAC-SHA256 signing key, optional
+	Timeout string // per attempt, e.g. "10s"
+	Retries *int   // extra attempts on network errors, 429 and 5xx; nil uses the default
+}
+
+type AlertState string
+
+const (
+	AlertInactive AlertState = "inactive"
+	AlertPending  AlertState = "pending"
+	AlertFiring   AlertState = "firing"
+	AlertResolved AlertState = "resolved"
+)
+
+// ActiveAlert is the current state of a rule that is pending or firing.
+type ActiveAlert struct {
+	RuleID     string     `json:"rule_id"`
+	TopologyID uint       `json:"topology_id"`
+	UserID     uint       `json:"-"`
+	DeviceID   string     `json:"device"`
+	Metric     string     `json:"metric"`
+	Instance   string     `json:"instance,omitempty"`
+	Operator   string     `json:"operator,omitempty"`
+	Threshold  float64    `json:"threshold"`
+	State      AlertState `json:"state"`
+	Value      float64    `json:"value"`
+	Since      time.Time  `json:"since"`                 // when the rule entered the current state
+	HeldBy     string     `json:"held_by,omitempty"`     // upstream device whose outage holds the notification
+	SilencedBy uint       `json:"silenced_by,omitempty"` // silence muting the notification
+}
+
 type AlertService interface {
 	UpdateRules(topologyID uint, rules []AlertRule)
+	UpdateSilences(silences []Silence)
 	Evaluate(metric Metric)
 	GetRecentAlerts(window time.Duration) []string
+	GetActiveAlerts() []ActiveAlert
+	History(filter AlertEventFilter) ([]AlertEvent, int64, error)
+	Acknowledge(id uint, scope MetricScope, comment string) error
+	DeadLetters(limit, offset int) ([]MailDeadLetter, int64, error)
 	SendTestEmail(toEmail string) error
+	SendTestNotification(out AlertOutput) error
 }
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Outcome of the notification attached to an alert event.
+const (
+	NotifyPending    = "pending"
+	NotifySent       = "sent"
+	NotifyFailed     = "failed"
+	NotifyPartial    = "partial"    // some deliveries went out, others failed
+	NotifySuppressed = "suppressed" // cooldown active or upstream device down
+	NotifyDisabled   = "disabled"   // no channel configured
+)
+
+var ErrAlertEventNotFound = errors.New("alert event not found")
+
+// AlertEvent is one firing of a rule, from the moment it fired until it resolved.
+type AlertEvent struct {
+	ID           uint       `json:"id"`
+	RuleID       string     `json:"rule_id"`
+	TopologyID   uint       `json:"topology_id"`
+	UserID       uint       `json:"user_id"`
+	DeviceID     string     `json:"device"`
+	Metric       string     `json:"metric"`
+	Operator     string     `json:"operator,omitempty"`
+	Threshold    float64    `json:"threshold"`
+	Value        float64    `json:"value"`
+	State        AlertState `json:"state"` // firing or resolved
+	FiredAt      time.Time  `json:"fired_at"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+	NotifyStatus string     `json:"notify_status"`
+	NotifyError  string     `json:"notify_error,omitempty"`
+	AckedAt      *time.Time `json:"acked_at,omitempty"`
+	AckedBy      *uint      `json:"acked_by,omitempty"`
+	AckComment   string     `json:"ack_comment,omitempty"`
+
+	Deliveries []AlertDelivery `json:"deliveries,omitempty"`
+}
+
+// AlertDelivery is the outcome of notifying one target (an email recipient or
+// an HTTP endpoint) about an event.
+type AlertDelivery struct {
+	ID         uint       `json:"id"`
+	EventID    uint       `json:"event_id"`
+	TopologyID uint       `json:"topology_id"`
+	RuleID     string     `json:"rule_id"`
+	NodeID     string     `json:"node_id"` // output node
+	Channel    string     `json:"channel"`
+	Target     string     `json:"target"` // recipient address or endpoint host
+	State      AlertState `json:"state"`  // whether the firing or the resolution was announced
+	Status     string     `json:"status"` // one of the Notify* values
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type AlertEventFilter struct {
+	Scope      MetricScope
+	DeviceID   string
+	RuleID     string
+	TopologyID uint
+	State      AlertState
+	From       time.Time
+	To         time.Time
+	Acked      *bool
+	Limit      int
+	Offset     int
+}
+
+type AlertEventRepository interface {
+	Create(event *AlertEvent) error
+	Resolve(id uint, at time.Time, value float64) error
+	SetNotification(id uint, status, errMsg string) error
+	AddDelivery(delivery *AlertDelivery) error
+	Ack(id uint, scope MetricScope, by uint, comment string, at time.Time) error
+	List(filter AlertEventFilter) ([]AlertEvent, int64, error)
+	// Open returns the events that have not resolved yet, with their deliveries.
+	Open() ([]AlertEvent, error)
+	// LastNotified returns, per "topologyID/ruleID/nodeID" output, the last
+	// time a firing notification went out.
+	LastNotified() (map[string]time.Time, error)
+}
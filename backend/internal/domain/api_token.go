@@ -2,12 +2,20 @@ package domain
 
 import "time"
 
+// Tipos de token. Un token "device" sólo puede reportar por su DeviceName;
+// uno "fleet" interpreta DeviceName como patrón (path.Match, ej. "web-*").
+const (
+	TokenKindDevice = "device"
+	TokenKindFleet  = "fleet"
+)
+
 type APIToken struct {
 	ID         uint
 	Name       string
 	TokenHash  string
 	UserID     *uint
 	DeviceName string
+	Kind       string
 	CreatedAt  time.Time
 	RevokedAt  *time.Time
 }
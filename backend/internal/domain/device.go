@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	DeviceOnline  = "online"
+	DeviceOffline = "offline"
+)
+
+// Device es un equipo que reporta métricas. Se registra (o actualiza) en cada
+// ingesta; el intervalo esperado se estima a partir de los propios reportes.
+type Device struct {
+	ID           uint      `json:"id"`
+	OwnerID      uint      `json:"owner_id"`
+	Name         string    `json:"name"`
+	IpAddress    string    `json:"ip_address"`
+	Gateway      string    `json:"gateway,omitempty"`
+	OS           string    `json:"os,omitempty"`
+	DeviceType   string    `json:"device_type,omitempty"`
+	AgentVersion string    `json:"agent_version,omitempty"`
+	FirstSeen    time.Time `json:"first_seen"`
+	LastSeen     time.Time `json:"last_seen"`
+	IntervalSec  float64   `json:"interval_sec"`
+	Status       string    `json:"status"`
+}
+
+var ErrDeviceNotFound = errors.New("device not found")
+
+type DeviceRepository interface {
+	Find(ownerID uint, name string) (*Device, error)
+	Save(device *Device) error
+	List(scope MetricScope) ([]Device, error)
+	FindByName(scope MetricScope, name string) (*Device, error)
+	Delete(id uint) error
+}
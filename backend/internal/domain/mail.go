@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrMailQueueFull is returned when the outbound mail queue can't take more messages.
+var ErrMailQueueFull = errors.New("mail queue full")
+
+// MailDeadLetter keeps a message the mail queue gave up on, so it isn't lost.
+type MailDeadLetter struct {
+	ID         uint      `json:"id"`
+	Recipients string    `json:"recipients"` // comma separated
+	Subject    string    `json:"subject"`
+	Message    string    `json:"-"` // full RFC 5322 message
+	Error      string    `json:"error"`
+	Attempts   int       `json:"attempts"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type MailDeadLetterRepository interface {
+	Create(letter *MailDeadLetter) error
+	List(limit, offset int) ([]MailDeadLetter, int64, error)
+}
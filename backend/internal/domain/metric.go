@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 type Metric struct {
 	DeviceName string    `json:"device_name"`
@@ -12,12 +15,151 @@ type Metric struct {
 	RAMUsage  float64 `json:"ram_usage"`
 	DiskUsage float64 `json:"disk_usage"`
 
-	CPUPerCore  []float64 `json:"cpu_per_core,omitempty"`
-	UptimeSec   uint64    `json:"uptime_sec,omitempty"`
-	NetRxBytes  uint64    `json:"net_rx_bytes,omitempty"`
-	NetTxBytes  uint64    `json:"net_tx_bytes,omitempty"`
-	Temperature float64   `json:"temperature,omitempty"`
-	OS          string    `json:"os,omitempty"`
-	DeviceType  string    `json:"device_type,omitempty"`
-	TopProcs    []string  `json:"top_procs,omitempty"`
+	CPUPerCore  []float64     `json:"cpu_per_core,omitempty"`
+	UptimeSec   uint64        `json:"uptime_sec,omitempty"`
+	NetRxBytes  uint64        `json:"net_rx_bytes,omitempty"`
+	NetTxBytes  uint64        `json:"net_tx_bytes,omitempty"`
+	Temperature float64       `json:"temperature,omitempty"`
+	OS          string        `json:"os,omitempty"`
+	DeviceType  string        `json:"device_type,omitempty"`
+	TopProcs    []ProcessInfo `json:"top_procs,omitempty"`
+
+	AgentVersion string `json:"agent_version,omitempty"`
+
+	// Uso por punto de montaje (DiskUsage es sólo "/").
+	Mounts []MountUsage `json:"mounts,omitempty"`
+	// Tráfico por interfaz, con tasas calculadas por el agente.
+	Interfaces []InterfaceStats `json:"interfaces,omitempty"`
+	// I/O por disco desde la recolección anterior del agente.
+	DiskIO []DiskIO `json:"disk_io,omitempty"`
+
+	// Saturación según el agente; nil si el SO del equipo no lo expone.
+	Load          *LoadAvg   `json:"load,omitempty"`
+	Swap          *SwapUsage `json:"swap,omitempty"`
+	Pressure      *Pressure  `json:"pressure,omitempty"`
+	CtxSwitchRate *float64   `json:"ctx_switch_rate,omitempty"`
+	ForkRate      *float64   `json:"fork_rate,omitempty"`
+
+	// Derivadas por el backend al recibir la métrica, a partir de la anterior
+	// del mismo dispositivo: bytes/s de red (nil si no hay una muestra previa
+	// válida o el contador se reinició) y si el equipo se reinició (uptime bajó).
+	NetRxRate *float64 `json:"net_rx_rate,omitempty"`
+	NetTxRate *float64 `json:"net_tx_rate,omitempty"`
+	Rebooted  bool     `json:"rebooted,omitempty"`
+
+	// OwnerID es el usuario dueño del API token que envió la métrica (lo fija el backend).
+	OwnerID *uint `json:"-"`
+}
+
+// MountUsage es el uso de un sistema de archivos montado, tal como lo reporta el agente.
+type MountUsage struct {
+	Path        string  `json:"path"`
+	Device      string  `json:"device,omitempty"`
+	FSType      string  `json:"fstype,omitempty"`
+	UsedPercent float64 `json:"used_pct"`
+	FreeBytes   uint64  `json:"free_bytes"`
+	TotalBytes  uint64  `json:"total_bytes"`
+	InodesUsed  float64 `json:"inodes_used_pct"`
+	ReadOnly    bool    `json:"read_only,omitempty"`
+}
+
+// InterfaceStats es el estado de una interfaz de red; Rates es nil en su primera
+// muestra o si sus contadores se reiniciaron.
+type InterfaceStats struct {
+	Name  string          `json:"name"`
+	Up    bool            `json:"up"`
+	Rates *InterfaceRates `json:"rates,omitempty"`
+}
+
+// InterfaceRates son tasas por segundo desde la recolección anterior del agente.
+type InterfaceRates struct {
+	RxBytes   float64 `json:"rx_bytes"`
+	TxBytes   float64 `json:"tx_bytes"`
+	RxPackets float64 `json:"rx_packets"`
+	TxPackets float64 `json:"tx_packets"`
+	RxErrors  float64 `json:"rx_errors"`
+	TxErrors  float64 `json:"tx_errors"`
+	RxDrops   float64 `json:"rx_drops"`
+	TxDrops   float64 `json:"tx_drops"`
+}
+
+// DiskIO es la actividad de un disco: tasas por segundo, espera promedio por
+// operación y % del tiempo ocupado.
+type DiskIO struct {
+	Name       string  `json:"name"`
+	ReadBytes  float64 `json:"read_bytes"`
+	WriteBytes float64 `json:"write_bytes"`
+	ReadIOPS   float64 `json:"read_iops"`
+	WriteIOPS  float64 `json:"write_iops"`
+	AwaitMs    float64 `json:"await_ms"`
+	UtilPct    float64 `json:"util_pct"`
+}
+
+// LoadAvg es la carga promedio a 1, 5 y 15 minutos.
+type LoadAvg struct {
+	Load1  float64 `json:"load1"`
+	Load5  float64 `json:"load5"`
+	Load15 float64 `json:"load15"`
+}
+
+// SwapUsage es el uso de swap; TotalBytes 0 significa que el equipo no tiene swap.
+type SwapUsage struct {
+	UsedPercent float64 `json:"used_pct"`
+	UsedBytes   uint64  `json:"used_bytes"`
+	TotalBytes  uint64  `json:"total_bytes"`
+}
+
+// Pressure es la información de presión (PSI) de Linux: % del tiempo con
+// tareas esperando CPU, memoria o disco desde la recolección anterior.
+type Pressure struct {
+	CPUSome    float64 `json:"cpu_some"`
+	MemorySome float64 `json:"memory_some"`
+	MemoryFull float64 `json:"memory_full"`
+	IOSome     float64 `json:"io_some"`
+	IOFull     float64 `json:"io_full"`
+}
+
+// ProcessInfo es uno de los procesos que más CPU o memoria usan según el agente.
+type ProcessInfo struct {
+	PID        int32   `json:"pid"`
+	Name       string  `json:"name"`
+	User       string  `json:"user,omitempty"`
+	CPUPercent float64 `json:"cpu_pct"`
+	MemPercent float64 `json:"mem_pct"`
+	RSSBytes   uint64  `json:"rss_bytes"`
+	Cmdline    string  `json:"cmdline,omitempty"`
+}
+
+// MetricPoint es un punto de una serie temporal.
+type MetricPoint struct {
+	T time.Time `json:"t"`
+	V float64   `json:"v"`
+}
+
+// MetricScope limita las consultas a los dispositivos de un usuario;
+// con All (administradores) se ve todo.
+type MetricScope struct {
+	UserID uint
+	All    bool
+}
+
+// MetricStore es el almacenamiento de series temporales (Influx, Postgres, ...).
+type MetricStore interface {
+	WriteMetrics(ctx context.Context, metrics []Metric) error
+	LastStats(ctx context.Context, scope MetricScope, device string) (map[string]interface{}, error)
+	TimeSeries(ctx context.Context, scope MetricScope, device, field, instance, rangeDur, agg, interval string) ([]MetricPoint, error)
+	History(ctx context.Context, scope MetricScope, device, rangeDur string) ([]map[string]interface{}, error)
+	// LastInstances retorna el último valor de cada instancia de un grupo de
+	// series (ej. "mount": una fila por punto de montaje).
+	LastInstances(ctx context.Context, scope MetricScope, device, group string) ([]map[string]interface{}, error)
+}
+
+// InvalidQueryError indica un parámetro de consulta inválido (se responde 400).
+type InvalidQueryError struct {
+	Param  string
+	Reason string
+}
+
+func (e *InvalidQueryError) Error() string {
+	return "invalid " + e.Param + ": " + e.Reason
 }
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotifyDisabled is returned by a channel that has no configuration to deliver with.
+var ErrNotifyDisabled = errors.New("notifications not configured")
+
+// ErrBlockedDestination is returned when an HTTP channel resolves to an address
+// the server must not reach on a user's behalf (loopback, private networks,
+// link-local such as cloud metadata endpoints).
+var ErrBlockedDestination = errors.New("destination address not allowed")
+
+// ErrInvalidTemplate wraps parse and execution errors of a subject or body template.
+var ErrInvalidTemplate = errors.New("invalid template")
+
+// Sample is one observation of a rule's metric.
+type Sample struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// Notification is what a channel receives when a rule fires or resolves.
+type Notification struct {
+	RuleID      string     `json:"rule_id"`
+	TopologyID  uint       `json:"topology_id"`
+	Topology    string     `json:"topology,omitempty"` // topology name
+	Device      string     `json:"device"`
+	Metric      string     `json:"metric"`
+	Instance    string     `json:"instance,omitempty"` // per-instance metrics: the mount or interface
+	Operator    string     `json:"operator,omitempty"`
+	Expression  string     `json:"expression,omitempty"`  // composite rules: the whole condition, Value is 1 or 0
+	Aggregation string     `json:"aggregation,omitempty"` // avg, min or max over Window; empty for the raw value
+	Threshold   float64    `json:"threshold"`
+	Value       float64    `json:"value"`
+	Window      string     `json:"window,omitempty"`     // heartbeat (Value is then the seconds without data) or aggregation span
+	State       AlertState `json:"state"`                // firing or resolved
+	Subject     string     `json:"subject,omitempty"`    // rendered subject template
+	Message     string     `json:"message,omitempty"`    // rendered body template
+	HTML        string     `json:"-"`                    // rendered HTML body, email only
+	Samples     []Sample   `json:"samples,omitempty"`    // last observations, oldest first
+	Downstream  []string   `json:"downstream,omitempty"` // heartbeat: devices behind this one, whose alerts are held
+	Time        time.Time  `json:"time"`
+}
+
+// Notifier delivers a notification through one channel (email, webhook, ...).
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ProcessSnapshot es la última lista de procesos reportada por un dispositivo.
+// Sólo se guarda la más reciente; no hay historial.
+type ProcessSnapshot struct {
+	Device    string        `json:"device"`
+	OwnerID   uint          `json:"-"`
+	Time      time.Time     `json:"time"`
+	Processes []ProcessInfo `json:"processes"`
+}
+
+var ErrProcessesNotFound = errors.New("no process snapshot for device")
+
+type ProcessSnapshotRepository interface {
+	// Save reemplaza la instantánea del dispositivo (por dueño y nombre).
+	Save(s *ProcessSnapshot) error
+	Latest(scope MetricScope, device string) (*ProcessSnapshot, error)
+}
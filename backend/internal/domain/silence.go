@@ -0,0 +1,161 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrSilenceNotFound = errors.New("silence not found")
+	ErrInvalidSilence  = errors.New("invalid silence")
+)
+
+// Silence mutes the notifications of the rules it matches while it is active.
+// Alerts still fire and are recorded; their deliveries are marked suppressed.
+// With a From/To time range it is a recurring maintenance window, open on
+// Weekdays within StartsAt and EndsAt (EndsAt may then be left open).
+type Silence struct {
+	ID      uint   `json:"id"`
+	UserID  uint   `json:"user_id"` // owner; only their rules are matched unless Global
+	Global  bool   `json:"global"`  // matches every user's rules (admins only)
+	Author  string `json:"author"`
+	Comment string `json:"comment,omitempty"`
+
+	// Matchers: an empty one matches anything
+	TopologyID uint   `json:"topology_id,omitempty"`
+	RuleID     string `json:"rule_id,omitempty"`
+	Device     string `json:"device,omitempty"`
+	Metric     string `json:"metric,omitempty"`
+
+	StartsAt time.Time  `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at,omitempty"`
+
+	// Recurring maintenance window
+	Weekdays []time.Weekday `json:"weekdays,omitempty"` // days it opens on (0 = Sunday); empty means every day
+	From     string         `json:"from,omitempty"`     // "02:00"
+	To       string         `json:"to,omitempty"`       // "04:00"; earlier than From spans midnight
+	Timezone string         `json:"timezone,omitempty"` // IANA name, UTC by default
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SilenceRepository interface {
+	Create(s *Silence) error
+	Update(s *Silence) error
+	Delete(id uint, scope MetricScope) error
+	FindByID(id uint, scope MetricScope) (*Silence, error)
+	List(scope MetricScope) ([]Silence, error)
+	FindAll() ([]Silence, error)
+}
+
+const silenceClock = "15:04"
+
+// Recurring reports whether the silence is a maintenance window.
+func (s Silence) Recurring() bool {
+	return s.From != "" || s.To != ""
+}
+
+// Validate checks the time range and, for maintenance windows, the schedule.
+func (s Silence) Validate() error {
+	if s.StartsAt.IsZero() {
+		return fmt.Errorf("%w: starts_at is required", ErrInvalidSilence)
+	}
+	if s.EndsAt != nil && !s.EndsAt.After(s.StartsAt) {
+		return fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidSilence)
+	}
+	if !s.Recurring() {
+		if s.EndsAt == nil {
+			return fmt.Errorf("%w: ends_at is required", ErrInvalidSilence)
+		}
+		if len(s.Weekdays) > 0 {
+			return fmt.Errorf("%w: weekdays need a from/to time range", ErrInvalidSilence)
+		}
+		return nil
+	}
+
+	from, err := time.Parse(silenceClock, s.From)
+	if err != nil {
+		return fmt.Errorf("%w: from must be HH:MM", ErrInvalidSilence)
+	}
+	to, err := time.Parse(silenceClock, s.To)
+	if err != nil {
+		return fmt.Errorf("%w: to must be HH:MM", ErrInvalidSilence)
+	}
+	if from.Equal(to) {
+		return fmt.Errorf("%w: from and to must differ", ErrInvalidSilence)
+	}
+	for _, d := range s.Weekdays {
+		if d < time.Sunday || d > time.Saturday {
+			return fmt.Errorf("%w: weekday %d out of range (0 = Sunday ... 6 = Saturday)", ErrInvalidSilence, d)
+		}
+	}
+	if _, err := time.LoadLocation(s.Timezone); err != nil {
+		return fmt.Errorf("%w: unknown timezone %q", ErrInvalidSilence, s.Timezone)
+	}
+	return nil
+}
+
+// Matches reports whether the silence applies to the rule. A composite rule
+// has no device of its own: it matches when any condition it reads does.
+func (s Silence) Matches(rule AlertRule) bool {
+	if !(s.Global || s.UserID == rule.UserID) ||
+		!(s.TopologyID == 0 || s.TopologyID == rule.TopologyID) ||
+		!(s.RuleID == "" || s.RuleID == rule.ID) {
+		return false
+	}
+	if rule.Expr == nil || (s.Device == "" && s.Metric == "") {
+		return s.matchesCond(rule)
+	}
+	for _, c := range rule.Expr.Leaves() {
+		if s.matchesCond(*c) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s Silence) matchesCond(rule AlertRule) bool {
+	return (s.Device == "" || s.Device == rule.DeviceID) &&
+		(s.Metric == "" || s.Metric == rule.Metric)
+}
+
+// ActiveAt reports whether the silence mutes notifications at t.
+func (s Silence) ActiveAt(t time.Time) bool {
+	if t.Before(s.StartsAt) || (s.EndsAt != nil && !t.Before(*s.EndsAt)) {
+		return false
+	}
+	if !s.Recurring() {
+		return true
+	}
+
+	from, err1 := time.Parse(silenceClock, s.From)
+	to, err2 := time.Parse(silenceClock, s.To)
+	loc, err3 := time.LoadLocation(s.Timezone)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return false
+	}
+	local := t.In(loc)
+	now := local.Hour()*60 + local.Minute()
+	start := from.Hour()*60 + from.Minute()
+	end := to.Hour()*60 + to.Minute()
+
+	if start < end {
+		return s.opensOn(local.Weekday()) && now >= start && now < end
+	}
+	// spans midnight: open from start on an opening day until end the next day
+	yesterday := (local.Weekday() + 6) % 7
+	return (s.opensOn(local.Weekday()) && now >= start) || (s.opensOn(yesterday) && now < end)
+}
+
+func (s Silence) opensOn(d time.Weekday) bool {
+	if len(s.Weekdays) == 0 {
+		return true
+	}
+	for _, w := range s.Weekdays {
+		if w == d {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,13 @@
 package domain
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidTopology wraps the problems found in a flow graph, such as a
+// cycle or a logic node with unconnected inputs.
+var ErrInvalidTopology = errors.New("invalid topology")
 
 type Topology struct {
 	ID        uint
@@ -0,0 +1,24 @@
+package database
+
+import (
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// BackfillDeliveryTopologies copia la topología del evento a los envíos
+// guardados antes de que alert_deliveries tuviera topology_id, para que los
+// cooldowns restaurados al arrancar no se mezclen entre topologías.
+func BackfillDeliveryTopologies(db *gorm.DB) {
+	res := db.Exec(`UPDATE alert_deliveries SET topology_id = alert_events.topology_id
+		FROM alert_events
+		WHERE alert_events.id = alert_deliveries.event_id
+		AND alert_deliveries.topology_id = 0 AND alert_events.topology_id <> 0`)
+	if res.Error != nil {
+		log.Printf("alerts: error completando topology_id de alert_deliveries: %v", res.Error)
+		return
+	}
+	if res.RowsAffected > 0 {
+		log.Printf("alerts: topology_id completado en %d envíos", res.RowsAffected)
+	}
+}
@@ -30,9 +30,11 @@ func Connect() *gorm.DB {
 		log.Fatalf("cannot connect db: %v", err)
 	}
 
-	if err := db.AutoMigrate(&persistence.UserModel{}, &persistence.APITokenModel{}, &persistence.TopologyModel{}); err != nil {
+	if err := db.AutoMigrate(&persistence.UserModel{}, &persistence.APITokenModel{}, &persistence.TopologyModel{}, &persistence.MetricSampleModel{}, &persistence.DeviceModel{}, &persistence.AlertEventModel{}, &persistence.AlertDeliveryModel{}, &persistence.MailDeadLetterModel{}, &persistence.SilenceModel{}, &persistence.ProcessSnapshotModel{}); err != nil {
 		log.Fatalf("auto-migrate error: %v", err)
 	}
+	BackfillOwners(db)
+	BackfillDeliveryTopologies(db)
 
 	return db
 }
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"log"
+	"os"
+	"strconv"
+
+	"gorm.io/gorm"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/infrastructure/persistence"
+)
+
+// LegacyOwner devuelve el usuario al que se asignan los dispositivos y
+// métricas guardados antes de registrar dueños: LEGACY_METRICS_OWNER (ID de
+// usuario) o, si no está definido, el primer administrador.
+func LegacyOwner(db *gorm.DB) (uint, bool) {
+	if v := os.Getenv("LEGACY_METRICS_OWNER"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil || id == 0 {
+			log.Printf("owners: LEGACY_METRICS_OWNER inválido (%s)", v)
+			return 0, false
+		}
+		return uint(id), true
+	}
+
+	var admin persistence.UserModel
+	err := db.Where("role = ?", "admin").Order("id").Limit(1).Find(&admin).Error
+	if err != nil {
+		log.Printf("owners: no se pudo buscar un administrador: %v", err)
+		return 0, false
+	}
+	if admin.ID == 0 {
+		return 0, false
+	}
+	return admin.ID, true
+}
+
+// BackfillOwners asigna dueño a las filas sin owner (dispositivos con
+// owner_id = 0 y muestras con owner_id NULL). Sólo toca filas huérfanas, así
+// que correrlo en cada arranque no cambia nada una vez migrado.
+func BackfillOwners(db *gorm.DB) {
+	owner, ok := LegacyOwner(db)
+	if !ok {
+		var orphans int64
+		db.Model(&persistence.DeviceModel{}).Where("owner_id = 0").Count(&orphans)
+		if orphans > 0 {
+			log.Printf("owners: %d dispositivos sin dueño y ningún administrador; defina LEGACY_METRICS_OWNER", orphans)
+		}
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// si el dueño ya reporta un dispositivo con el mismo nombre, la fila
+		// huérfana queda como está para no romper el índice (owner_id, name)
+		devices := tx.Model(&persistence.DeviceModel{}).
+			Where("owner_id = 0").
+			Where("name NOT IN (?)", tx.Model(&persistence.DeviceModel{}).Select("name").Where("owner_id = ?", owner)).
+			Update("owner_id", owner)
+		if devices.Error != nil {
+			return devices.Error
+		}
+
+		samples := tx.Model(&persistence.MetricSampleModel{}).
+			Where("owner_id IS NULL").
+			Update("owner_id", owner)
+		if samples.Error != nil {
+			return samples.Error
+		}
+
+		if devices.RowsAffected > 0 || samples.RowsAffected > 0 {
+			log.Printf("owners: asignados al usuario %d: %d dispositivos, %d muestras", owner, devices.RowsAffected, samples.RowsAffected)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("owners: error asignando dueño a datos antiguos: %v", err)
+	}
+}
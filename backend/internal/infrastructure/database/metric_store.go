@@ -0,0 +1,60 @@
+package database
+
+import (
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/infrastructure/timeseries"
+)
+
+// NewMetricStoreFromEnv elige el almacenamiento de métricas según METRIC_STORE
+// ("influx" o "postgres"). Sin valor, usa Influx si está configurado y si no
+// las tablas de Postgres.
+func NewMetricStoreFromEnv(db *gorm.DB) domain.MetricStore {
+	kind := strings.ToLower(os.Getenv("METRIC_STORE"))
+
+	if kind == "" || kind == "influx" {
+		if w := NewInfluxFromEnv(); w != nil {
+			log.Println("metrics: usando InfluxDB")
+			// los puntos escritos antes de la etiqueta owner no se pueden
+			// reetiquetar; se muestran al dueño asignado a los datos antiguos
+			if owner, ok := LegacyOwner(db); ok {
+				w.SetLegacyOwner(owner)
+			}
+			return w
+		}
+		if kind == "influx" {
+			log.Println("metrics: METRIC_STORE=influx pero Influx no está configurado, se usa Postgres")
+		}
+	}
+
+	store := timeseries.NewSQLStore(db)
+	log.Println("metrics: usando Postgres (metric_samples)")
+
+	if retention := os.Getenv("METRIC_RETENTION"); retention != "" {
+		d, err := timeseries.ParseRetention(retention)
+		if err != nil {
+			log.Printf("metrics: METRIC_RETENTION inválido (%s): %v", retention, err)
+		} else {
+			go pruneLoop(store, d)
+		}
+	}
+
+	return store
+}
+
+func pruneLoop(store *timeseries.SQLStore, retention time.Duration) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		if err := store.Prune(time.Now().Add(-retention)); err != nil {
+			log.Printf("metrics: error borrando muestras antiguas: %v", err)
+		}
+		<-ticker.C
+	}
+}
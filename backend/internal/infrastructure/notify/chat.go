@@ -0,0 +1,217 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+)
+
+// Severity colors shared by the chat channels.
+const (
+	colorFiring    = 0xD32F2F // red
+	colorHeartbeat = 0xF57C00 // orange: device silent
+	colorResolved  = 0x2E7D32 // green
+)
+
+// NewSlackNotifier posts to a Slack incoming webhook (or any receiver that
+// speaks the same attachments format, e.g. Mattermost or Rocket.Chat).
+func NewSlackNotifier(cfg domain.WebhookConfig) *WebhookNotifier {
+	return newHTTPNotifier(cfg, slackPayload)
+}
+
+// NewDiscordNotifier posts an embed to a Discord channel webhook.
+func NewDiscordNotifier(cfg domain.WebhookConfig) *WebhookNotifier {
+	return newHTTPNotifier(cfg, discordPayload)
+}
+
+// NewNtfyNotifier publishes to an ntfy topic URL (e.g. https://ntfy.sh/my-alerts).
+func NewNtfyNotifier(cfg domain.WebhookConfig) *WebhookNotifier {
+	return newHTTPNotifier(cfg, ntfyPayload)
+}
+
+type chatField struct {
+	name  string
+	value string
+}
+
+func chatTitle(n domain.Notification) string {
+	prefix := "NocturneScope Alert"
+	if n.State == domain.AlertResolved {
+		prefix = "NocturneScope Resolved"
+	}
+	if n.Subject != "" {
+		return prefix + ": " + n.Subject
+	}
+	if n.Metric == metricHeartbeat {
+		return fmt.Sprintf("%s: %s heartbeat", prefix, n.Device)
+	}
+	if n.Expression != "" {
+		return fmt.Sprintf("%s: %s", prefix, n.Expression)
+	}
+	return fmt.Sprintf("%s: %s %s", prefix, n.Device, metricName(n))
+}
+
+func chatColor(n domain.Notification) int {
+	switch {
+	case n.State == domain.AlertResolved:
+		return colorResolved
+	case n.Metric == metricHeartbeat:
+		return colorHeartbeat
+	default:
+		return colorFiring
+	}
+}
+
+func chatFields(n domain.Notification) []chatField {
+	if n.Metric == metricHeartbeat {
+		status := "Device stopped reporting"
+		if n.State == domain.AlertResolved {
+			status = "Device reporting again"
+		}
+		silence := time.Duration(n.Value * float64(time.Second)).Round(time.Second)
+		fields := []chatField{
+			{"Device", n.Device},
+			{"Status", status},
+			{"No data for", fmt.Sprintf("%s (window %s)", silence, n.Window)},
+		}
+		if len(n.Downstream) > 0 {
+			fields = append(fields, chatField{"Devices behind it", strings.Join(n.Downstream, ", ")})
+		}
+		return fields
+	}
+	if n.Expression != "" {
+		return []chatField{
+			{"Devices", n.Device},
+			{"Condition", n.Expression},
+		}
+	}
+	return []chatField{
+		{"Device", n.Device},
+		{"Metric", metricName(n)},
+		{"Condition", condition(n)},
+		{"Current Value", fmt.Sprintf("%.2f", n.Value)},
+	}
+}
+
+// truncate keeps s within the limits the chat services enforce.
+func truncate(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max-1]) + "…"
+}
+
+func slackPayload(n domain.Notification) (payload, error) {
+	type field struct {
+		Title string `json:"title"`
+		Value string `json:"value"`
+		Short bool   `json:"short"`
+	}
+	type attachment struct {
+		Fallback string  `json:"fallback"`
+		Color    string  `json:"color"`
+		Title    string  `json:"title"`
+		Text     string  `json:"text,omitempty"`
+		Fields   []field `json:"fields"`
+		Footer   string  `json:"footer"`
+		Ts       int64   `json:"ts"`
+	}
+
+	title := chatTitle(n)
+	a := attachment{
+		Fallback: title,
+		Color:    fmt.Sprintf("#%06X", chatColor(n)),
+		Title:    title,
+		Text:     n.Message,
+		Footer:   "NocturneScope Monitoring System",
+		Ts:       n.Time.Unix(),
+	}
+	for _, f := range chatFields(n) {
+		a.Fields = append(a.Fields, field{Title: f.name, Value: f.value, Short: true})
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"text":        title,
+		"attachments": []attachment{a},
+	})
+	if err != nil {
+		return payload{}, err
+	}
+	return payload{body: body, contentType: "application/json"}, nil
+}
+
+func discordPayload(n domain.Notification) (payload, error) {
+	type field struct {
+		Name   string `json:"name"`
+		Value  string `json:"value"`
+		Inline bool   `json:"inline"`
+	}
+	type footer struct {
+		Text string `json:"text"`
+	}
+	type embed struct {
+		Title       string  `json:"title"`
+		Description string  `json:"description,omitempty"`
+		Color       int     `json:"color"`
+		Fields      []field `json:"fields"`
+		Footer      footer  `json:"footer"`
+		Timestamp   string  `json:"timestamp"`
+	}
+
+	e := embed{
+		Title:       truncate(chatTitle(n), 256),
+		Description: truncate(n.Message, 4096),
+		Color:       chatColor(n),
+		Footer:      footer{Text: "NocturneScope Monitoring System"},
+		Timestamp:   n.Time.UTC().Format(time.RFC3339),
+	}
+	for _, f := range chatFields(n) {
+		e.Fields = append(e.Fields, field{Name: f.name, Value: truncate(f.value, 1024), Inline: true})
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"username": "NocturneScope",
+		"embeds":   []embed{e},
+	})
+	if err != nil {
+		return payload{}, err
+	}
+	return payload{body: body, contentType: "application/json"}, nil
+}
+
+// ntfyPayload publishes the message as plain text; title, priority and tags
+// go in headers. ntfy has no colors, so severity maps to priority and an
+// emoji tag instead.
+func ntfyPayload(n domain.Notification) (payload, error) {
+	priority, tag := "high", "rotating_light"
+	switch {
+	case n.State == domain.AlertResolved:
+		priority, tag = "default", "white_check_mark"
+	case n.Metric == metricHeartbeat:
+		priority, tag = "urgent", "warning"
+	}
+
+	var b strings.Builder
+	if n.Message != "" {
+		b.WriteString(n.Message)
+		b.WriteString("\n\n")
+	}
+	for _, f := range chatFields(n) {
+		fmt.Fprintf(&b, "%s: %s\n", f.name, f.value)
+	}
+
+	return payload{
+		body:        []byte(b.String()),
+		contentType: "text/plain; charset=utf-8",
+		headers: map[string]string{
+			"Title":    mime.QEncoding.Encode("utf-8", chatTitle(n)),
+			"Priority": priority,
+			"Tags":     tag + "," + n.Metric,
+		},
+	}, nil
+}
@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+)
+
+// cgnat is the carrier-grade NAT range (RFC 6598), not covered by IsPrivate.
+var cgnat = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// allowPrivateDestinations lets on-prem installs deliver to internal services
+// (NOTIFY_ALLOW_PRIVATE=true).
+func allowPrivateDestinations() bool {
+	v := strings.ToLower(os.Getenv("NOTIFY_ALLOW_PRIVATE"))
+	return v == "true" || v == "1"
+}
+
+func publicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || cgnat.Contains(ip))
+}
+
+// checkDestination runs after DNS resolution, right before connecting, so it
+// also covers redirects and names that resolve to internal addresses.
+func checkDestination(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !publicIP(ip) {
+		return fmt.Errorf("%w: %s", domain.ErrBlockedDestination, host)
+	}
+	return nil
+}
+
+// newNotifyTransport builds the transport for HTTP channels. No proxy is used:
+// it would hide the real destination from the check.
+func newNotifyTransport() *http.Transport {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second}
+	if !allowPrivateDestinations() {
+		dialer.Control = checkDestination
+	}
+	return &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+		MaxIdleConns:        10,
+		IdleConnTimeout:     90 * time.Second,
+	}
+}
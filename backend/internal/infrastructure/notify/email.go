@@ -0,0 +1,178 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+)
+
+const metricHeartbeat = "heartbeat"
+
+// EmailNotifier delivers the message to a single envelope recipient. The
+// headers still list every To and Cc address (Bcc never appears), so sending
+// one notifier per recipient keeps the usual mail semantics while a rejected
+// address doesn't stop the others.
+type EmailNotifier struct {
+	queue *MailQueue
+	to    []string
+	cc    []string
+	rcpt  string
+}
+
+func NewEmailNotifier(queue *MailQueue, to, cc []string, rcpt string) *EmailNotifier {
+	return &EmailNotifier{queue: queue, to: to, cc: cc, rcpt: rcpt}
+}
+
+// Notify queues the message and waits until it is delivered or dead-lettered.
+func (e *EmailNotifier) Notify(ctx context.Context, n domain.Notification) error {
+	err := e.queue.Send(ctx, []string{e.rcpt}, emailSubject(n), emailMessage(e.to, e.cc, n))
+	if err != nil {
+		fmt.Printf("[notify] Error sending email to %s: %v\n", e.rcpt, err)
+	} else {
+		fmt.Printf("[notify] Email sent to %s\n", e.rcpt)
+	}
+	return err
+}
+
+func emailSubject(n domain.Notification) string {
+	subject := "[NocturneScope Alert]"
+	if n.State == domain.AlertResolved {
+		subject = "[NocturneScope Resolved]"
+	}
+	if n.Subject != "" {
+		subject += " " + n.Subject
+	}
+	return subject
+}
+
+func emailMessage(to, cc []string, n domain.Notification) []byte {
+	subject := emailSubject(n)
+
+	var b strings.Builder
+	if len(to) > 0 {
+		fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	} else {
+		b.WriteString("To: undisclosed-recipients:;\r\n")
+	}
+	if len(cc) > 0 {
+		fmt.Fprintf(&b, "Cc: %s\r\n", strings.Join(cc, ", "))
+	}
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	b.WriteString("MIME-Version: 1.0\r\n")
+
+	text := emailText(n)
+	if n.HTML == "" {
+		b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n\r\n")
+		b.WriteString(text)
+		return []byte(b.String())
+	}
+
+	// multipart/alternative: clients without HTML support show the text part
+	boundary := fmt.Sprintf("nocturne-%d", time.Now().UnixNano())
+	fmt.Fprintf(&b, "Content-Type: multipart/alternative; boundary=\"%s\"\r\n\r\n", boundary)
+	fmt.Fprintf(&b, "--%s\r\n", boundary)
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n\r\n")
+	b.WriteString(text)
+	fmt.Fprintf(&b, "\r\n--%s\r\n", boundary)
+	b.WriteString("Content-Type: text/html; charset=\"utf-8\"\r\n\r\n")
+	b.WriteString(n.HTML)
+	fmt.Fprintf(&b, "\r\n--%s--\r\n", boundary)
+	return []byte(b.String())
+}
+
+// metricName is the metric with its instance, e.g. "mount_used_pct (/data)".
+func metricName(n domain.Notification) string {
+	if n.Instance != "" {
+		return fmt.Sprintf("%s (%s)", n.Metric, n.Instance)
+	}
+	return n.Metric
+}
+
+// condition describes what the rule checks, e.g. "> 85.00", "avg over 5m > 85.00"
+// or, for a composite rule, its whole expression.
+func condition(n domain.Notification) string {
+	if n.Expression != "" {
+		return n.Expression
+	}
+	if n.Aggregation != "" {
+		return fmt.Sprintf("%s over %s %s %.2f", n.Aggregation, n.Window, n.Operator, n.Threshold)
+	}
+	return fmt.Sprintf("%s %.2f", n.Operator, n.Threshold)
+}
+
+// emailText is the plain-text body: a fixed summary followed by the rendered message.
+func emailText(n domain.Notification) string {
+	timestamp := n.Time.Format("2006-01-02 15:04:05")
+	resolved := n.State == domain.AlertResolved
+
+	if n.Metric == metricHeartbeat {
+		status := "Device stopped reporting"
+		if resolved {
+			status = "Device reporting again"
+		}
+		silence := time.Duration(n.Value * float64(time.Second)).Round(time.Second)
+		downstream := ""
+		if len(n.Downstream) > 0 {
+			downstream = fmt.Sprintf("Devices behind it: %s\r\n", strings.Join(n.Downstream, ", "))
+		}
+
+		return fmt.Sprintf("HEARTBEAT NOTIFICATION\r\n"+
+			"======================\r\n"+
+			"Time: %s\r\n"+
+			"Device: %s\r\n"+
+			"Status: %s\r\n"+
+			"No data for: %s (window %s)\r\n"+
+			"%s"+
+			"\r\n"+
+			"Message:\r\n"+
+			"%s\r\n"+
+			"\r\n"+
+			"--\r\n"+
+			"NocturneScope Monitoring System\r\n",
+			timestamp, n.Device, status, silence, n.Window, downstream, n.Message)
+	}
+
+	heading := "ALERT NOTIFICATION"
+	if resolved {
+		heading = "RESOLVED NOTIFICATION"
+	}
+
+	if n.Expression != "" {
+		status := "Condition met"
+		if resolved {
+			status = "Condition cleared"
+		}
+		return fmt.Sprintf("%s\r\n"+
+			"=====================\r\n"+
+			"Time: %s\r\n"+
+			"Devices: %s\r\n"+
+			"Condition: %s\r\n"+
+			"Status: %s\r\n"+
+			"\r\n"+
+			"Message:\r\n"+
+			"%s\r\n"+
+			"\r\n"+
+			"--\r\n"+
+			"NocturneScope Monitoring System\r\n",
+			heading, timestamp, n.Device, n.Expression, status, n.Message)
+	}
+
+	return fmt.Sprintf("%s\r\n"+
+		"=====================\r\n"+
+		"Time: %s\r\n"+
+		"Device: %s\r\n"+
+		"Metric: %s\r\n"+
+		"Condition: %s\r\n"+
+		"Current Value: %.2f\r\n"+
+		"\r\n"+
+		"Message:\r\n"+
+		"%s\r\n"+
+		"\r\n"+
+		"--\r\n"+
+		"NocturneScope Monitoring System\r\n",
+		heading, timestamp, n.Device, metricName(n), condition(n), n.Value, n.Message)
+}
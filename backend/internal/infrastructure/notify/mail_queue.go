@@ -0,0 +1,225 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+)
+
+const (
+	defaultMailWorkers     = 2
+	defaultMailQueueSize   = 256
+	defaultMailMaxAttempts = 5
+	defaultMailRetryBase   = 2 * time.Second
+	maxMailRetryDelay      = 5 * time.Minute
+	mailIdleTimeout        = 30 * time.Second
+)
+
+// MailQueueOptions configures the outbound queue. Zero values take the defaults.
+type MailQueueOptions struct {
+	Workers     int           // parallel SMTP sessions
+	Size        int           // messages waiting before Send fails fast
+	MaxAttempts int           // tries per message before it is dead-lettered
+	RetryBase   time.Duration // first retry delay, doubled on each attempt
+}
+
+// MailQueueOptionsFromEnv reads MAIL_WORKERS, MAIL_QUEUE_SIZE and MAIL_MAX_ATTEMPTS.
+func MailQueueOptionsFromEnv() MailQueueOptions {
+	atoi := func(key string) int {
+		n, _ := strconv.Atoi(os.Getenv(key))
+		return n
+	}
+	return MailQueueOptions{
+		Workers:     atoi("MAIL_WORKERS"),
+		Size:        atoi("MAIL_QUEUE_SIZE"),
+		MaxAttempts: atoi("MAIL_MAX_ATTEMPTS"),
+	}
+}
+
+// MailQueue sends mail through a fixed pool of workers, each keeping its SMTP
+// session open between messages, so an alert storm doesn't open a connection
+// per recipient. Temporary failures (network, 4xx) are retried with
+// exponential backoff; permanent ones (5xx) and messages out of attempts go
+// to the dead-letter store.
+type MailQueue struct {
+	mailer *Mailer
+	dead   domain.MailDeadLetterRepository
+	opts   MailQueueOptions
+	jobs   chan *mailJob
+}
+
+type mailJob struct {
+	to       []string
+	subject  string
+	msg      []byte
+	attempts int
+	done     chan error
+}
+
+func NewMailQueue(mailer *Mailer, dead domain.MailDeadLetterRepository, opts MailQueueOptions) *MailQueue {
+	if opts.Workers <= 0 {
+		opts.Workers = defaultMailWorkers
+	}
+	if opts.Size <= 0 {
+		opts.Size = defaultMailQueueSize
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultMailMaxAttempts
+	}
+	if opts.RetryBase <= 0 {
+		opts.RetryBase = defaultMailRetryBase
+	}
+	return &MailQueue{
+		mailer: mailer,
+		dead:   dead,
+		opts:   opts,
+		jobs:   make(chan *mailJob, opts.Size),
+	}
+}
+
+// Start launches the workers.
+func (q *MailQueue) Start() {
+	for i := 0; i < q.opts.Workers; i++ {
+		go q.worker(i)
+	}
+}
+
+// Send queues the message and waits for its final outcome, retries included.
+// It fails fast with domain.ErrMailQueueFull when the queue is saturated.
+func (q *MailQueue) Send(ctx context.Context, to []string, subject string, msg []byte) error {
+	if !q.mailer.Configured() {
+		return domain.ErrNotifyDisabled
+	}
+
+	job := &mailJob{to: to, subject: subject, msg: msg, done: make(chan error, 1)}
+	select {
+	case q.jobs <- job:
+	default:
+		q.deadLetter(job, domain.ErrMailQueueFull)
+		return domain.ErrMailQueueFull
+	}
+
+	select {
+	case err := <-job.done:
+		return err
+	case <-ctx.Done():
+		// the message stays queued; only the caller stops waiting
+		return ctx.Err()
+	}
+}
+
+// Pending reports how many messages are waiting for a worker.
+func (q *MailQueue) Pending() int {
+	return len(q.jobs)
+}
+
+func (q *MailQueue) worker(id int) {
+	var client *smtp.Client
+	idle := time.NewTimer(mailIdleTimeout)
+	defer idle.Stop()
+
+	closeClient := func() {
+		if client != nil {
+			_ = client.Quit()
+			client.Close()
+			client = nil
+		}
+	}
+
+	for {
+		select {
+		case <-idle.C:
+			closeClient()
+			continue
+		case job := <-q.jobs:
+			job.attempts++
+
+			var err error
+			if client == nil {
+				client, err = q.mailer.dial()
+			}
+			if err == nil {
+				if err = q.mailer.deliver(client, job.to, job.msg); err != nil {
+					// a rejected transaction leaves the session usable after RSET
+					if !isSMTPReply(err) || client.Reset() != nil {
+						client.Close()
+						client = nil
+					}
+				}
+			}
+
+			if !idle.Stop() {
+				select {
+				case <-idle.C:
+				default:
+				}
+			}
+			idle.Reset(mailIdleTimeout)
+
+			q.finish(job, err, id)
+		}
+	}
+}
+
+// finish reports the outcome, or schedules another attempt.
+func (q *MailQueue) finish(job *mailJob, err error, worker int) {
+	rcpt := strings.Join(job.to, ", ")
+	if err == nil {
+		fmt.Printf("[mailqueue] Worker %d delivered mail to %s (attempt %d)\n", worker, rcpt, job.attempts)
+		job.done <- nil
+		return
+	}
+
+	if job.attempts < q.opts.MaxAttempts && temporarySMTPError(err) {
+		delay := q.opts.RetryBase << (job.attempts - 1)
+		if delay > maxMailRetryDelay {
+			delay = maxMailRetryDelay
+		}
+		fmt.Printf("[mailqueue] Mail to %s failed (attempt %d/%d), retrying in %s: %v\n", rcpt, job.attempts, q.opts.MaxAttempts, delay, err)
+		time.AfterFunc(delay, func() { q.jobs <- job })
+		return
+	}
+
+	fmt.Printf("[mailqueue] Giving up on mail to %s after %d attempts: %v\n", rcpt, job.attempts, err)
+	q.deadLetter(job, err)
+	job.done <- fmt.Errorf("dead-lettered after %d attempts: %w", job.attempts, err)
+}
+
+func (q *MailQueue) deadLetter(job *mailJob, err error) {
+	if q.dead == nil {
+		return
+	}
+	letter := &domain.MailDeadLetter{
+		Recipients: strings.Join(job.to, ","),
+		Subject:    job.subject,
+		Message:    string(job.msg),
+		Error:      err.Error(),
+		Attempts:   job.attempts,
+	}
+	if err := q.dead.Create(letter); err != nil {
+		fmt.Printf("[mailqueue] Error saving dead letter: %v\n", err)
+	}
+}
+
+func isSMTPReply(err error) bool {
+	var tp *textproto.Error
+	return errors.As(err, &tp)
+}
+
+// temporarySMTPError tells 4xx replies and connection problems, worth
+// retrying, from 5xx rejections that will fail the same way again.
+func temporarySMTPError(err error) bool {
+	var tp *textproto.Error
+	if errors.As(err, &tp) {
+		return tp.Code < 500
+	}
+	return true
+}
@@ -0,0 +1,141 @@
+package notify
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+)
+
+const smtpDialTimeout = 10 * time.Second
+
+// Mailer sends mail through the SMTP server configured in the environment.
+//
+// SMTP_TLS selects the transport: "implicit" (TLS from the first byte, the
+// usual port 465) or "starttls" (plain connection upgraded with STARTTLS,
+// ports 587/25). When unset, port 465 means implicit and anything else
+// STARTTLS.
+type Mailer struct {
+	host        string
+	port        string
+	user        string
+	pass        string
+	implicitTLS bool
+}
+
+func NewMailerFromEnv() *Mailer {
+	m := &Mailer{
+		host: os.Getenv("SMTP_HOST"),
+		port: os.Getenv("SMTP_PORT"),
+		user: os.Getenv("SMTP_USER"),
+		pass: os.Getenv("SMTP_PASS"),
+	}
+	switch strings.ToLower(os.Getenv("SMTP_TLS")) {
+	case "implicit", "tls", "ssl":
+		m.implicitTLS = true
+	case "starttls":
+		m.implicitTLS = false
+	default:
+		m.implicitTLS = m.port == "465"
+	}
+	return m
+}
+
+func (m *Mailer) Configured() bool {
+	return m.host != "" && m.port != "" && m.user != "" && m.pass != ""
+}
+
+// Verify connects and authenticates once, to report bad settings at startup.
+func (m *Mailer) Verify() error {
+	client, err := m.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return client.Quit()
+}
+
+// Send delivers an already formatted message over a fresh connection.
+func (m *Mailer) Send(to []string, msg []byte) error {
+	if m.host == "" || m.user == "" {
+		return domain.ErrNotifyDisabled
+	}
+	client, err := m.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := m.deliver(client, to, msg); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// dial opens an authenticated session, over implicit TLS or upgraded with STARTTLS.
+func (m *Mailer) dial() (*smtp.Client, error) {
+	tlsConfig := &tls.Config{ServerName: m.host}
+	dialer := &net.Dialer{Timeout: smtpDialTimeout}
+
+	var conn net.Conn
+	var err error
+	if m.implicitTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", m.addr(), tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", m.addr())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to SMTP server: %w", err)
+	}
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("could not connect to SMTP server: %w", err)
+	}
+
+	if !m.implicitTLS {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("could not start TLS: %w", err)
+		}
+	}
+	if err := client.Auth(m.auth()); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("SMTP authentication failed: %w", err)
+	}
+	return client, nil
+}
+
+// deliver runs one mail transaction on an open session.
+func (m *Mailer) deliver(client *smtp.Client, to []string, msg []byte) error {
+	if err := client.Mail(m.user); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err := client.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+func (m *Mailer) addr() string {
+	return fmt.Sprintf("%s:%s", m.host, m.port)
+}
+
+func (m *Mailer) auth() smtp.Auth {
+	return smtp.PlainAuth("", m.user, m.pass, m.host)
+}
@@ -0,0 +1,149 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"math"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+)
+
+const templateTimeLayout = "2006-01-02 15:04:05"
+
+// TemplateContext is what subject and body templates can reference:
+//
+//	{{.Device}}      device name; for composite rules, every device involved
+//	{{.Metric}}      cpu, ram, disk, temp, heartbeat, composite, ...
+//	{{.Instance}}    per-instance metrics (mount_*, iface_*): the mount or interface
+//	{{.Operator}}    >, >=, <, <=, ==
+//	{{.Expression}}  composite rules: the and/or/not expression; Value is then 1 or 0
+//	{{.Aggregation}} avg, min or max when the rule aggregates over {{.Window}}, else empty
+//	{{.Threshold}}   configured threshold
+//	{{.Value}}       observed value: the aggregate for windowed rules, seconds
+//	                 without data for heartbeat rules
+//	{{.State}}       "firing" or "resolved"; {{.Resolved}} is the same as a bool
+//	{{.Window}}      heartbeat or aggregation window, e.g. "5m"
+//	{{.Time}}        time.Time of the notification; {{.Timestamp}} is it formatted
+//	{{.Topology}}    name of the topology the rule belongs to
+//	{{.Samples}}     last observations, oldest first, each with .Time and .Value
+//	{{.Downstream}}  heartbeat rules: devices behind this one, whose alerts are held
+//
+// Besides the text/template builtins (printf, len, index, ...) these functions
+// are available: round VALUE PLACES, date TIME LAYOUT, duration SECONDS,
+// upper, lower. Example subject:
+//
+//	{{.Device}}: {{.Metric}} at {{round .Value 1}} ({{.Operator}} {{.Threshold}})
+type TemplateContext struct {
+	Device      string
+	Metric      string
+	Instance    string
+	Operator    string
+	Expression  string
+	Aggregation string
+	Threshold   float64
+	Value       float64
+	State       string
+	Resolved    bool
+	Window      string
+	Time        time.Time
+	Timestamp   string
+	Topology    string
+	Samples     []domain.Sample
+	Downstream  []string
+}
+
+var templateFuncs = map[string]interface{}{
+	"round": func(v float64, places int) float64 {
+		p := math.Pow(10, float64(places))
+		return math.Round(v*p) / p
+	},
+	"date": func(t time.Time, layout string) string {
+		return t.Format(layout)
+	},
+	"duration": func(seconds float64) string {
+		return time.Duration(seconds * float64(time.Second)).Round(time.Second).String()
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+func newTemplateContext(n domain.Notification) TemplateContext {
+	return TemplateContext{
+		Device:      n.Device,
+		Metric:      n.Metric,
+		Instance:    n.Instance,
+		Operator:    n.Operator,
+		Expression:  n.Expression,
+		Aggregation: n.Aggregation,
+		Threshold:   n.Threshold,
+		Value:       n.Value,
+		State:       string(n.State),
+		Resolved:    n.State == domain.AlertResolved,
+		Window:      n.Window,
+		Time:        n.Time,
+		Timestamp:   n.Time.Format(templateTimeLayout),
+		Topology:    n.Topology,
+		Samples:     n.Samples,
+		Downstream:  n.Downstream,
+	}
+}
+
+// Render executes a text template against the notification.
+func Render(name, text string, n domain.Notification) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	t, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %v", domain.ErrInvalidTemplate, name, err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, newTemplateContext(n)); err != nil {
+		return "", fmt.Errorf("%w: %s: %v", domain.ErrInvalidTemplate, name, err)
+	}
+	return buf.String(), nil
+}
+
+// RenderHTML is Render with html/template, so values are escaped.
+func RenderHTML(name, text string, n domain.Notification) (string, error) {
+	t, err := htmltemplate.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %v", domain.ErrInvalidTemplate, name, err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, newTemplateContext(n)); err != nil {
+		return "", fmt.Errorf("%w: %s: %v", domain.ErrInvalidTemplate, name, err)
+	}
+	return buf.String(), nil
+}
+
+// ValidateTemplate parses the template and runs it against a sample
+// notification, which also catches references to fields that don't exist.
+func ValidateTemplate(name, text string, html bool) error {
+	if text == "" {
+		return nil
+	}
+	now := time.Now()
+	sample := domain.Notification{
+		Device:    "device",
+		Metric:    "cpu",
+		Operator:  ">",
+		Threshold: 80,
+		Value:     90,
+		State:     domain.AlertFiring,
+		Topology:  "topology",
+		Samples:   []domain.Sample{{Time: now.Add(-time.Minute), Value: 85}, {Time: now, Value: 90}},
+		Time:      now,
+	}
+	var err error
+	if html {
+		_, err = RenderHTML(name, text, sample)
+	} else {
+		_, err = Render(name, text, sample)
+	}
+	return err
+}
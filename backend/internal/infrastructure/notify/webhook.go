@@ -0,0 +1,189 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+)
+
+// notifyTransport is shared by every HTTP channel so connections are reused.
+var notifyTransport = newNotifyTransport()
+
+const (
+	defaultWebhookTimeout = 10 * time.Second
+	defaultWebhookRetries = 2
+	maxWebhookRetries     = 10
+	webhookRetryBase      = time.Second
+)
+
+// WebhookNotifier POSTs the notification as JSON to an HTTP endpoint.
+//
+// With a secret, each request carries X-Nocturne-Timestamp and
+// X-Nocturne-Signature: "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body)),
+// so the receiver can check origin and reject replays.
+type WebhookNotifier struct {
+	cfg     domain.WebhookConfig
+	format  formatter
+	timeout time.Duration
+	retries int
+	client  *http.Client
+}
+
+// payload is the request body a channel sends, with its content type and any
+// channel-specific headers.
+type payload struct {
+	body        []byte
+	contentType string
+	headers     map[string]string
+}
+
+// formatter renders a notification in the format a receiver expects.
+type formatter func(n domain.Notification) (payload, error)
+
+func NewWebhookNotifier(cfg domain.WebhookConfig) *WebhookNotifier {
+	return newHTTPNotifier(cfg, jsonPayload)
+}
+
+// newHTTPNotifier holds the delivery logic shared by every HTTP channel.
+func newHTTPNotifier(cfg domain.WebhookConfig, format formatter) *WebhookNotifier {
+	timeout, err := time.ParseDuration(cfg.Timeout)
+	if err != nil || timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	retries := defaultWebhookRetries
+	if cfg.Retries != nil && *cfg.Retries >= 0 {
+		retries = *cfg.Retries
+	}
+	if retries > maxWebhookRetries {
+		retries = maxWebhookRetries
+	}
+	if cfg.Method == "" {
+		cfg.Method = http.MethodPost
+	}
+
+	return &WebhookNotifier{
+		cfg:     cfg,
+		format:  format,
+		timeout: timeout,
+		retries: retries,
+		client:  &http.Client{Timeout: timeout, Transport: notifyTransport},
+	}
+}
+
+func (w *WebhookNotifier) Notify(ctx context.Context, n domain.Notification) error {
+	if w.cfg.URL == "" {
+		return domain.ErrNotifyDisabled
+	}
+	p, err := w.format(n)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= w.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(webhookRetryBase << (attempt - 1)):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		retry, err := w.send(ctx, p)
+		if err == nil {
+			fmt.Printf("[notify] Webhook delivered to %s\n", redactURL(w.cfg.URL))
+			return nil
+		}
+		lastErr = err
+		fmt.Printf("[notify] Webhook attempt %d/%d to %s failed: %v\n", attempt+1, w.retries+1, redactURL(w.cfg.URL), err)
+		if !retry {
+			break
+		}
+	}
+	return lastErr
+}
+
+// send makes one attempt; the bool reports whether the failure is worth retrying.
+func (w *WebhookNotifier) send(ctx context.Context, p payload) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, w.cfg.Method, w.cfg.URL, bytes.NewReader(p.body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", p.contentType)
+	req.Header.Set("User-Agent", "NocturneScope-Webhook/1.0")
+	for k, v := range p.headers {
+		req.Header.Set(k, v)
+	}
+	for k, v := range w.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	if w.cfg.Secret != "" {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set("X-Nocturne-Timestamp", ts)
+		req.Header.Set("X-Nocturne-Signature", "sha256="+Sign(w.cfg.Secret, ts, p.body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		// url.Error repeats the full URL, which may carry a token
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return !errors.Is(err, domain.ErrBlockedDestination), err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch {
+	case resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("webhook status %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("webhook status %d", resp.StatusCode)
+	}
+}
+
+// redactURL keeps only scheme and host: Slack/Discord webhook paths and ntfy
+// topics are credentials.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "(invalid url)"
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// jsonPayload sends the notification as is.
+func jsonPayload(n domain.Notification) (payload, error) {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return payload{}, err
+	}
+	return payload{body: body, contentType: "application/json"}, nil
+}
+
+// Sign computes the hex HMAC-SHA256 of timestamp + "." + body.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
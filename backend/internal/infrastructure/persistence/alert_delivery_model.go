@@ -0,0 +1,57 @@
+package persistence
+
+import (
+	"time"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+)
+
+type AlertDeliveryModel struct {
+	ID         uint   `gorm:"primaryKey;autoIncrement"`
+	EventID    uint   `gorm:"not null;index"`
+	TopologyID uint   `gorm:"not null;default:0;index:idx_alert_deliveries_output"`
+	RuleID     string `gorm:"not null;index:idx_alert_deliveries_output"`
+	NodeID     string `gorm:"not null;index:idx_alert_deliveries_output"`
+	Channel    string `gorm:"not null"`
+	Target     string
+	State      string `gorm:"not null"`
+	Status     string `gorm:"not null"`
+	Error      string `gorm:"type:text"`
+	CreatedAt  time.Time
+}
+
+func (AlertDeliveryModel) TableName() string {
+	return "alert_deliveries"
+}
+
+func (m *AlertDeliveryModel) ToDomain() domain.AlertDelivery {
+	return domain.AlertDelivery{
+		ID:         m.ID,
+		EventID:    m.EventID,
+		TopologyID: m.TopologyID,
+		RuleID:     m.RuleID,
+		NodeID:     m.NodeID,
+		Channel:    m.Channel,
+		Target:     m.Target,
+		State:      domain.AlertState(m.State),
+		Status:     m.Status,
+		Error:      m.Error,
+		CreatedAt:  m.CreatedAt,
+	}
+}
+
+func AlertDeliveryModelFromDomain(d domain.AlertDelivery) AlertDeliveryModel {
+	return AlertDeliveryModel{
+		ID:         d.ID,
+		EventID:    d.EventID,
+		TopologyID: d.TopologyID,
+		RuleID:     d.RuleID,
+		NodeID:     d.NodeID,
+		Channel:    d.Channel,
+		Target:     d.Target,
+		State:      string(d.State),
+		Status:     d.Status,
+		Error:      d.Error,
+		CreatedAt:  d.CreatedAt,
+	}
+}
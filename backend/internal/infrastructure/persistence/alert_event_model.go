@@ -0,0 +1,89 @@
+package persistence
+
+import (
+	"time"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+)
+
+type AlertEventModel struct {
+	ID           uint   `gorm:"primaryKey;autoIncrement"`
+	RuleID       string `gorm:"not null;index"`
+	TopologyID   uint   `gorm:"not null;index"`
+	UserID       uint   `gorm:"not null;index"`
+	DeviceID     string `gorm:"not null;index"`
+	Metric       string `gorm:"not null"`
+	Operator     string
+	Threshold    float64
+	Value        float64
+	State        string    `gorm:"not null;index"`
+	FiredAt      time.Time `gorm:"not null;index"`
+	ResolvedAt   *time.Time
+	NotifyStatus string `gorm:"not null;default:pending"`
+	NotifyError  string
+	AckedAt      *time.Time
+	AckedBy      *uint
+	AckComment   string `gorm:"type:text"`
+
+	Deliveries []AlertDeliveryModel `gorm:"foreignKey:EventID"`
+}
+
+func (AlertEventModel) TableName() string {
+	return "alert_events"
+}
+
+func (m *AlertEventModel) ToDomain() domain.AlertEvent {
+	return domain.AlertEvent{
+		ID:           m.ID,
+		RuleID:       m.RuleID,
+		TopologyID:   m.TopologyID,
+		UserID:       m.UserID,
+		DeviceID:     m.DeviceID,
+		Metric:       m.Metric,
+		Operator:     m.Operator,
+		Threshold:    m.Threshold,
+		Value:        m.Value,
+		State:        domain.AlertState(m.State),
+		FiredAt:      m.FiredAt,
+		ResolvedAt:   m.ResolvedAt,
+		NotifyStatus: m.NotifyStatus,
+		NotifyError:  m.NotifyError,
+		AckedAt:      m.AckedAt,
+		AckedBy:      m.AckedBy,
+		AckComment:   m.AckComment,
+		Deliveries:   deliveriesToDomain(m.Deliveries),
+	}
+}
+
+func deliveriesToDomain(models []AlertDeliveryModel) []domain.AlertDelivery {
+	if len(models) == 0 {
+		return nil
+	}
+	out := make([]domain.AlertDelivery, 0, len(models))
+	for _, m := range models {
+		out = append(out, m.ToDomain())
+	}
+	return out
+}
+
+func AlertEventModelFromDomain(e domain.AlertEvent) AlertEventModel {
+	return AlertEventModel{
+		ID:           e.ID,
+		RuleID:       e.RuleID,
+		TopologyID:   e.TopologyID,
+		UserID:       e.UserID,
+		DeviceID:     e.DeviceID,
+		Metric:       e.Metric,
+		Operator:     e.Operator,
+		Threshold:    e.Threshold,
+		Value:        e.Value,
+		State:        string(e.State),
+		FiredAt:      e.FiredAt,
+		ResolvedAt:   e.ResolvedAt,
+		NotifyStatus: e.NotifyStatus,
+		NotifyError:  e.NotifyError,
+		AckedAt:      e.AckedAt,
+		AckedBy:      e.AckedBy,
+		AckComment:   e.AckComment,
+	}
+}
@@ -12,6 +12,7 @@ type APITokenModel struct {
 	TokenHash  string     `gorm:"not null;uniqueIndex"`
 	UserID     *uint      `gorm:"index"`
 	DeviceName string     `gorm:"not null;index"`
+	Kind       string     `gorm:"not null;default:device"`
 	CreatedAt  time.Time  `gorm:"autoCreateTime"`
 	RevokedAt  *time.Time `gorm:"default:null"`
 }
@@ -23,6 +24,7 @@ func (m *APITokenModel) ToDomain() domain.APIToken {
 		TokenHash:  m.TokenHash,
 		UserID:     m.UserID,
 		DeviceName: m.DeviceName,
+		Kind:       m.Kind,
 		CreatedAt:  m.CreatedAt,
 		RevokedAt:  m.RevokedAt,
 	}
@@ -0,0 +1,57 @@
+package persistence
+
+import (
+	"time"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+)
+
+type DeviceModel struct {
+	ID           uint   `gorm:"primaryKey;autoIncrement"`
+	OwnerID      uint   `gorm:"not null;default:0;uniqueIndex:idx_devices_owner_name"`
+	Name         string `gorm:"not null;uniqueIndex:idx_devices_owner_name"`
+	IpAddress    string
+	Gateway      string
+	OS           string
+	DeviceType   string
+	AgentVersion string
+	FirstSeen    time.Time `gorm:"not null"`
+	LastSeen     time.Time `gorm:"not null;index"`
+	IntervalSec  float64
+}
+
+func (DeviceModel) TableName() string {
+	return "devices"
+}
+
+func (m *DeviceModel) ToDomain() domain.Device {
+	return domain.Device{
+		ID:           m.ID,
+		OwnerID:      m.OwnerID,
+		Name:         m.Name,
+		IpAddress:    m.IpAddress,
+		Gateway:      m.Gateway,
+		OS:           m.OS,
+		DeviceType:   m.DeviceType,
+		AgentVersion: m.AgentVersion,
+		FirstSeen:    m.FirstSeen,
+		LastSeen:     m.LastSeen,
+		IntervalSec:  m.IntervalSec,
+	}
+}
+
+func DeviceModelFromDomain(d domain.Device) DeviceModel {
+	return DeviceModel{
+		ID:           d.ID,
+		OwnerID:      d.OwnerID,
+		Name:         d.Name,
+		IpAddress:    d.IpAddress,
+		Gateway:      d.Gateway,
+		OS:           d.OS,
+		DeviceType:   d.DeviceType,
+		AgentVersion: d.AgentVersion,
+		FirstSeen:    d.FirstSeen,
+		LastSeen:     d.LastSeen,
+		IntervalSec:  d.IntervalSec,
+	}
+}
@@ -0,0 +1,45 @@
+package persistence
+
+import (
+	"time"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+)
+
+type MailDeadLetterModel struct {
+	ID         uint   `gorm:"primaryKey;autoIncrement"`
+	Recipients string `gorm:"type:text;not null"`
+	Subject    string
+	Message    string `gorm:"type:text"`
+	Error      string `gorm:"type:text"`
+	Attempts   int
+	CreatedAt  time.Time `gorm:"index"`
+}
+
+func (MailDeadLetterModel) TableName() string {
+	return "mail_dead_letters"
+}
+
+func (m *MailDeadLetterModel) ToDomain() domain.MailDeadLetter {
+	return domain.MailDeadLetter{
+		ID:         m.ID,
+		Recipients: m.Recipients,
+		Subject:    m.Subject,
+		Message:    m.Message,
+		Error:      m.Error,
+		Attempts:   m.Attempts,
+		CreatedAt:  m.CreatedAt,
+	}
+}
+
+func MailDeadLetterModelFromDomain(d domain.MailDeadLetter) MailDeadLetterModel {
+	return MailDeadLetterModel{
+		ID:         d.ID,
+		Recipients: d.Recipients,
+		Subject:    d.Subject,
+		Message:    d.Message,
+		Error:      d.Error,
+		Attempts:   d.Attempts,
+		CreatedAt:  d.CreatedAt,
+	}
+}
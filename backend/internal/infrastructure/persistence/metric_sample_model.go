@@ -0,0 +1,25 @@
+package persistence
+
+import "time"
+
+// MetricSampleModel guarda un valor de una métrica (formato largo: una fila por campo),
+// para instalaciones sin InfluxDB.
+type MetricSampleModel struct {
+	ID      uint      `gorm:"primaryKey;autoIncrement"`
+	Device  string    `gorm:"not null;index:idx_metric_samples_device_field_time,priority:1"`
+	Field   string    `gorm:"not null;index:idx_metric_samples_device_field_time,priority:2"`
+	Time    time.Time `gorm:"not null;index:idx_metric_samples_device_field_time,priority:3;index"`
+	Value   float64   `gorm:"not null"`
+	OwnerID *uint     `gorm:"index"`
+	IP      string
+	Gateway string
+	OS      string
+	Type    string
+	// Instance es el punto de montaje, interfaz, ... de las series por
+	// instancia; vacío en las métricas del equipo.
+	Instance string `gorm:"not null;default:''"`
+}
+
+func (MetricSampleModel) TableName() string {
+	return "metric_samples"
+}
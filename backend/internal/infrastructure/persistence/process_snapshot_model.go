@@ -0,0 +1,49 @@
+package persistence
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+)
+
+type ProcessSnapshotModel struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	OwnerID   uint      `gorm:"not null;default:0;uniqueIndex:idx_device_processes_owner_device"`
+	Device    string    `gorm:"not null;uniqueIndex:idx_device_processes_owner_device"`
+	Time      time.Time `gorm:"not null"`
+	Processes string    `gorm:"type:text;not null"` // JSON de []domain.ProcessInfo
+}
+
+func (ProcessSnapshotModel) TableName() string {
+	return "device_processes"
+}
+
+func (m *ProcessSnapshotModel) ToDomain() (domain.ProcessSnapshot, error) {
+	s := domain.ProcessSnapshot{
+		Device:  m.Device,
+		OwnerID: m.OwnerID,
+		Time:    m.Time,
+	}
+	if err := json.Unmarshal([]byte(m.Processes), &s.Processes); err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+func ProcessSnapshotModelFromDomain(s domain.ProcessSnapshot) (ProcessSnapshotModel, error) {
+	procs := s.Processes
+	if procs == nil {
+		procs = []domain.ProcessInfo{}
+	}
+	data, err := json.Marshal(procs)
+	if err != nil {
+		return ProcessSnapshotModel{}, err
+	}
+	return ProcessSnapshotModel{
+		OwnerID:   s.OwnerID,
+		Device:    s.Device,
+		Time:      s.Time,
+		Processes: string(data),
+	}, nil
+}
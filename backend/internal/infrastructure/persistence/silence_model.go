@@ -0,0 +1,87 @@
+package persistence
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+)
+
+type SilenceModel struct {
+	ID      uint `gorm:"primaryKey;autoIncrement"`
+	UserID  uint `gorm:"not null;index"`
+	Global  bool `gorm:"not null;default:false"`
+	Author  string
+	Comment string `gorm:"type:text"`
+
+	TopologyID uint
+	RuleID     string
+	Device     string
+	Metric     string
+
+	StartsAt time.Time `gorm:"not null"`
+	EndsAt   *time.Time
+
+	Weekdays string // "0,6"
+	From     string
+	To       string
+	Timezone string
+
+	CreatedAt time.Time
+}
+
+func (SilenceModel) TableName() string {
+	return "silences"
+}
+
+func (m *SilenceModel) ToDomain() domain.Silence {
+	s := domain.Silence{
+		ID:         m.ID,
+		UserID:     m.UserID,
+		Global:     m.Global,
+		Author:     m.Author,
+		Comment:    m.Comment,
+		TopologyID: m.TopologyID,
+		RuleID:     m.RuleID,
+		Device:     m.Device,
+		Metric:     m.Metric,
+		StartsAt:   m.StartsAt,
+		EndsAt:     m.EndsAt,
+		From:       m.From,
+		To:         m.To,
+		Timezone:   m.Timezone,
+		CreatedAt:  m.CreatedAt,
+	}
+	for _, d := range strings.Split(m.Weekdays, ",") {
+		if n, err := strconv.Atoi(d); err == nil {
+			s.Weekdays = append(s.Weekdays, time.Weekday(n))
+		}
+	}
+	return s
+}
+
+func SilenceModelFromDomain(s domain.Silence) SilenceModel {
+	days := make([]string, len(s.Weekdays))
+	for i, d := range s.Weekdays {
+		days[i] = strconv.Itoa(int(d))
+	}
+	return SilenceModel{
+		ID:         s.ID,
+		UserID:     s.UserID,
+		Global:     s.Global,
+		Author:     s.Author,
+		Comment:    s.Comment,
+		TopologyID: s.TopologyID,
+		RuleID:     s.RuleID,
+		Device:     s.Device,
+		Metric:     s.Metric,
+		StartsAt:   s.StartsAt,
+		EndsAt:     s.EndsAt,
+		Weekdays:   strings.Join(days, ","),
+		From:       s.From,
+		To:         s.To,
+		Timezone:   s.Timezone,
+		CreatedAt:  s.CreatedAt,
+	}
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/infrastructure/persistence"
+	"gorm.io/gorm"
+)
+
+type AlertEventGormRepository struct {
+	db *gorm.DB
+}
+
+func NewAlertEventGormRepository(db *gorm.DB) *AlertEventGormRepository {
+	return &AlertEventGormRepository{db: db}
+}
+
+func (r *AlertEventGormRepository) Create(e *domain.AlertEvent) error {
+	m := persistence.AlertEventModelFromDomain(*e)
+	if err := r.db.Create(&m).Error; err != nil {
+		return err
+	}
+	e.ID = m.ID
+	return nil
+}
+
+func (r *AlertEventGormRepository) Resolve(id uint, at time.Time, value float64) error {
+	return r.db.Model(&persistence.AlertEventModel{}).
+		Where("id = ? AND resolved_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"state":       string(domain.AlertResolved),
+			"resolved_at": at,
+			"value":       value,
+		}).Error
+}
+
+func (r *AlertEventGormRepository) SetNotification(id uint, status, errMsg string) error {
+	return r.db.Model(&persistence.AlertEventModel{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"notify_status": status,
+			"notify_error":  errMsg,
+		}).Error
+}
+
+func (r *AlertEventGormRepository) AddDelivery(d *domain.AlertDelivery) error {
+	m := persistence.AlertDeliveryModelFromDomain(*d)
+	if err := r.db.Create(&m).Error; err != nil {
+		return err
+	}
+	d.ID = m.ID
+	d.CreatedAt = m.CreatedAt
+	return nil
+}
+
+func (r *AlertEventGormRepository) Ack(id uint, scope domain.MetricScope, by uint, comment string, at time.Time) error {
+	q := r.db.Model(&persistence.AlertEventModel{}).Where("id = ?", id)
+	if !scope.All {
+		q = q.Where("user_id = ?", scope.UserID)
+	}
+	res := q.Updates(map[string]interface{}{
+		"acked_at":    at,
+		"acked_by":    by,
+		"ack_comment": comment,
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrAlertEventNotFound
+	}
+	return nil
+}
+
+func (r *AlertEventGormRepository) List(f domain.AlertEventFilter) ([]domain.AlertEvent, int64, error) {
+	q := r.db.Model(&persistence.AlertEventModel{})
+	if !f.Scope.All {
+		q = q.Where("user_id = ?", f.Scope.UserID)
+	}
+	if f.DeviceID != "" {
+		q = q.Where("device_id = ?", f.DeviceID)
+	}
+	if f.RuleID != "" {
+		q = q.Where("rule_id = ?", f.RuleID)
+	}
+	if f.TopologyID != 0 {
+		q = q.Where("topology_id = ?", f.TopologyID)
+	}
+	if f.State != "" {
+		q = q.Where("state = ?", string(f.State))
+	}
+	if !f.From.IsZero() {
+		q = q.Where("fired_at >= ?", f.From)
+	}
+	if !f.To.IsZero() {
+		q = q.Where("fired_at < ?", f.To)
+	}
+	if f.Acked != nil {
+		if *f.Acked {
+			q = q.Where("acked_at IS NOT NULL")
+		} else {
+			q = q.Where("acked_at IS NULL")
+		}
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var models []persistence.AlertEventModel
+	if err := q.Preload("Deliveries", orderDeliveries).Order("fired_at DESC").Limit(f.Limit).Offset(f.Offset).Find(&models).Error; err != nil {
+		return nil, 0, err
+	}
+	res := make([]domain.AlertEvent, 0, len(models))
+	for _, m := range models {
+		res = append(res, m.ToDomain())
+	}
+	return res, total, nil
+}
+
+func (r *AlertEventGormRepository) Open() ([]domain.AlertEvent, error) {
+	var models []persistence.AlertEventModel
+	if err := r.db.Preload("Deliveries", orderDeliveries).Where("resolved_at IS NULL").Order("fired_at").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	res := make([]domain.AlertEvent, 0, len(models))
+	for _, m := range models {
+		res = append(res, m.ToDomain())
+	}
+	return res, nil
+}
+
+func (r *AlertEventGormRepository) LastNotified() (map[string]time.Time, error) {
+	var rows []struct {
+		TopologyID uint
+		RuleID     string
+		NodeID     string
+		Last       time.Time
+	}
+	err := r.db.Model(&persistence.AlertDeliveryModel{}).
+		Select("topology_id, rule_id, node_id, MAX(created_at) AS last").
+		Where("state = ? AND status IN ?", string(domain.AlertFiring), []string{domain.NotifySent, domain.NotifyFailed}).
+		Group("topology_id, rule_id, node_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]time.Time, len(rows))
+	for _, row := range rows {
+		out[fmt.Sprintf("%d/%s/%s", row.TopologyID, row.RuleID, row.NodeID)] = row.Last
+	}
+	return out, nil
+}
+
+func orderDeliveries(db *gorm.DB) *gorm.DB {
+	return db.Order("id")
+}
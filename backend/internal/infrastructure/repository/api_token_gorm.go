@@ -22,6 +22,7 @@ func (r *APITokenGormRepository) Create(t *domain.APIToken) error {
 		TokenHash:  t.TokenHash,
 		UserID:     t.UserID,
 		DeviceName: t.DeviceName,
+		Kind:       t.Kind,
 		RevokedAt:  t.RevokedAt,
 	}
 	return r.db.Create(&m).Error
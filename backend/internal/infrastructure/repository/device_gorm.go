@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/infrastructure/persistence"
+	"gorm.io/gorm"
+)
+
+type DeviceGormRepository struct {
+	db *gorm.DB
+}
+
+func NewDeviceGormRepository(db *gorm.DB) *DeviceGormRepository {
+	return &DeviceGormRepository{db: db}
+}
+
+// Find busca un dispositivo por dueño y nombre; devuelve nil si no existe.
+func (r *DeviceGormRepository) Find(ownerID uint, name string) (*domain.Device, error) {
+	var m persistence.DeviceModel
+	err := r.db.Where("owner_id = ? AND name = ?", ownerID, name).First(&m).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	d := m.ToDomain()
+	return &d, nil
+}
+
+func (r *DeviceGormRepository) Save(d *domain.Device) error {
+	m := persistence.DeviceModelFromDomain(*d)
+	if err := r.db.Save(&m).Error; err != nil {
+		return err
+	}
+	d.ID = m.ID
+	return nil
+}
+
+func (r *DeviceGormRepository) List(scope domain.MetricScope) ([]domain.Device, error) {
+	var models []persistence.DeviceModel
+	if err := r.scoped(scope).Order("name").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	res := make([]domain.Device, 0, len(models))
+	for _, m := range models {
+		res = append(res, m.ToDomain())
+	}
+	return res, nil
+}
+
+func (r *DeviceGormRepository) FindByName(scope domain.MetricScope, name string) (*domain.Device, error) {
+	var m persistence.DeviceModel
+	err := r.scoped(scope).Where("name = ?", name).Order("last_seen DESC").First(&m).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrDeviceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	d := m.ToDomain()
+	return &d, nil
+}
+
+// Delete borra un dispositivo por su ID: el nombre solo es único por dueño.
+func (r *DeviceGormRepository) Delete(id uint) error {
+	res := r.db.Delete(&persistence.DeviceModel{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrDeviceNotFound
+	}
+	return nil
+}
+
+func (r *DeviceGormRepository) scoped(scope domain.MetricScope) *gorm.DB {
+	if scope.All {
+		return r.db
+	}
+	return r.db.Where("owner_id = ?", scope.UserID)
+}
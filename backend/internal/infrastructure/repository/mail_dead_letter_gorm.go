@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/infrastructure/persistence"
+	"gorm.io/gorm"
+)
+
+type MailDeadLetterGormRepository struct {
+	db *gorm.DB
+}
+
+func NewMailDeadLetterGormRepository(db *gorm.DB) *MailDeadLetterGormRepository {
+	return &MailDeadLetterGormRepository{db: db}
+}
+
+func (r *MailDeadLetterGormRepository) Create(d *domain.MailDeadLetter) error {
+	m := persistence.MailDeadLetterModelFromDomain(*d)
+	if err := r.db.Create(&m).Error; err != nil {
+		return err
+	}
+	d.ID = m.ID
+	d.CreatedAt = m.CreatedAt
+	return nil
+}
+
+func (r *MailDeadLetterGormRepository) List(limit, offset int) ([]domain.MailDeadLetter, int64, error) {
+	var total int64
+	if err := r.db.Model(&persistence.MailDeadLetterModel{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var models []persistence.MailDeadLetterModel
+	if err := r.db.Order("created_at DESC").Limit(limit).Offset(offset).Find(&models).Error; err != nil {
+		return nil, 0, err
+	}
+	res := make([]domain.MailDeadLetter, 0, len(models))
+	for _, m := range models {
+		res = append(res, m.ToDomain())
+	}
+	return res, total, nil
+}
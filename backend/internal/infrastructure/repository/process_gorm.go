@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/infrastructure/persistence"
+	"gorm.io/gorm"
+)
+
+type ProcessSnapshotGormRepository struct {
+	db *gorm.DB
+}
+
+func NewProcessSnapshotGormRepository(db *gorm.DB) *ProcessSnapshotGormRepository {
+	return &ProcessSnapshotGormRepository{db: db}
+}
+
+// Save reemplaza la instantánea guardada para el mismo dueño y dispositivo.
+func (r *ProcessSnapshotGormRepository) Save(s *domain.ProcessSnapshot) error {
+	m, err := persistence.ProcessSnapshotModelFromDomain(*s)
+	if err != nil {
+		return err
+	}
+	var existing persistence.ProcessSnapshotModel
+	err = r.db.Where("owner_id = ? AND device = ?", s.OwnerID, s.Device).First(&existing).Error
+	switch {
+	case err == nil:
+		m.ID = existing.ID
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return err
+	}
+	return r.db.Save(&m).Error
+}
+
+func (r *ProcessSnapshotGormRepository) Latest(scope domain.MetricScope, device string) (*domain.ProcessSnapshot, error) {
+	var m persistence.ProcessSnapshotModel
+	err := r.scoped(scope).Where("device = ?", device).Order("time DESC").First(&m).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrProcessesNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	s, err := m.ToDomain()
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *ProcessSnapshotGormRepository) scoped(scope domain.MetricScope) *gorm.DB {
+	if scope.All {
+		return r.db
+	}
+	return r.db.Where("owner_id = ?", scope.UserID)
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/infrastructure/persistence"
+	"gorm.io/gorm"
+)
+
+type SilenceGormRepository struct {
+	db *gorm.DB
+}
+
+func NewSilenceGormRepository(db *gorm.DB) *SilenceGormRepository {
+	return &SilenceGormRepository{db: db}
+}
+
+func (r *SilenceGormRepository) scoped(scope domain.MetricScope) *gorm.DB {
+	q := r.db.Model(&persistence.SilenceModel{})
+	if !scope.All {
+		q = q.Where("user_id = ?", scope.UserID)
+	}
+	return q
+}
+
+func (r *SilenceGormRepository) Create(s *domain.Silence) error {
+	m := persistence.SilenceModelFromDomain(*s)
+	if err := r.db.Create(&m).Error; err != nil {
+		return err
+	}
+	s.ID = m.ID
+	s.CreatedAt = m.CreatedAt
+	return nil
+}
+
+func (r *SilenceGormRepository) Update(s *domain.Silence) error {
+	m := persistence.SilenceModelFromDomain(*s)
+	return r.db.Save(&m).Error
+}
+
+func (r *SilenceGormRepository) Delete(id uint, scope domain.MetricScope) error {
+	res := r.scoped(scope).Where("id = ?", id).Delete(&persistence.SilenceModel{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrSilenceNotFound
+	}
+	return nil
+}
+
+func (r *SilenceGormRepository) FindByID(id uint, scope domain.MetricScope) (*domain.Silence, error) {
+	var m persistence.SilenceModel
+	err := r.scoped(scope).Where("id = ?", id).First(&m).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrSilenceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	s := m.ToDomain()
+	return &s, nil
+}
+
+func (r *SilenceGormRepository) List(scope domain.MetricScope) ([]domain.Silence, error) {
+	var models []persistence.SilenceModel
+	if err := r.scoped(scope).Order("starts_at DESC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	res := make([]domain.Silence, 0, len(models))
+	for _, m := range models {
+		res = append(res, m.ToDomain())
+	}
+	return res, nil
+}
+
+func (r *SilenceGormRepository) FindAll() ([]domain.Silence, error) {
+	return r.List(domain.MetricScope{All: true})
+}
@@ -0,0 +1,62 @@
+package timeseries
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/influxdb-client-go/v2/api"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+)
+
+// fluxQuery arma una consulta Flux cuyos valores variables (bucket, device,
+// field, tiempos) viajan como parámetros y se leen con params.<nombre>.
+// Sólo se escriben en el texto literales que salen de listas blancas.
+type fluxQuery struct {
+	stages []string
+	params map[string]interface{}
+
+	legacyOwner uint
+}
+
+func newFluxQuery(bucket string) *fluxQuery {
+	return &fluxQuery{
+		stages: []string{`from(bucket: params.bucket)`},
+		params: map[string]interface{}{"bucket": bucket},
+	}
+}
+
+// param registra un valor y devuelve la referencia para usar en el texto.
+func (q *fluxQuery) param(name string, v interface{}) string {
+	q.params[name] = v
+	return "params." + name
+}
+
+// pipe agrega una etapa `|> ...`.
+func (q *fluxQuery) pipe(stage string) *fluxQuery {
+	q.stages = append(q.stages, stage)
+	return q
+}
+
+// ownerFilter agrega la condición de dueño al predicado de un filter, salvo que
+// el alcance sea global. El dueño de los datos antiguos ve también los puntos
+// sin etiqueta owner.
+func (q *fluxQuery) ownerFilter(scope domain.MetricScope) string {
+	if scope.All {
+		return ""
+	}
+	cond := `r.owner == ` + q.param("owner", strconv.FormatUint(uint64(scope.UserID), 10))
+	if q.legacyOwner != 0 && scope.UserID == q.legacyOwner {
+		return ` and (` + cond + ` or not exists r.owner)`
+	}
+	return ` and ` + cond
+}
+
+func (q *fluxQuery) String() string {
+	return strings.Join(q.stages, "\n  |> ") + "\n"
+}
+
+func (q *fluxQuery) run(ctx context.Context, qa api.QueryAPI) (*api.QueryTableResult, error) {
+	return qa.QueryWithParams(ctx, q.String(), q.params)
+}
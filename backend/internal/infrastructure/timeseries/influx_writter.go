@@ -2,19 +2,34 @@ package timeseries
 
 import (
 	"context"
+	"strconv"
 	"time"
 
 	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
 	"github.com/influxdata/influxdb-client-go/v2/api"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
 
 	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
 )
 
+const measurement = "system_metrics"
+
+// statFields son los campos que devuelven LastStats e History.
+var statFields = []string{
+	"cpu", "ram", "disk", "net_rx", "net_tx", "net_rx_rate", "net_tx_rate", "temp", "uptime",
+	"load1", "load5", "load15", "swap", "swap_used_bytes",
+	"psi_cpu_some", "psi_memory_some", "psi_memory_full", "psi_io_some", "psi_io_full",
+	"ctx_switch_rate", "fork_rate",
+}
+
 type InfluxWriter struct {
 	client influxdb2.Client
 	org    string
 	bucket string
 	write  api.WriteAPIBlocking
+
+	// legacyOwner ve además los puntos sin etiqueta owner (anteriores a ella)
+	legacyOwner uint
 }
 
 func NewInfluxWriter(url, token, org, bucket string) *InfluxWriter {
@@ -27,6 +42,9 @@ func NewInfluxWriter(url, token, org, bucket string) *InfluxWriter {
 	}
 }
 
+// SetLegacyOwner indica el usuario dueño de los puntos sin etiqueta owner.
+func (w *InfluxWriter) SetLegacyOwner(id uint) { w.legacyOwner = id }
+
 func (w *InfluxWriter) Client() influxdb2.Client { return w.client }
 func (w *InfluxWriter) Org() string              { return w.org }
 func (w *InfluxWriter) Bucket() string           { return w.bucket }
@@ -38,15 +56,73 @@ func (w *InfluxWriter) Close() {
 }
 
 func (w *InfluxWriter) WriteMetric(m domain.Metric) error {
+	return w.WriteMetrics(context.Background(), []domain.Metric{m})
+}
+
+// WriteMetrics escribe un lote de métricas en una sola llamada a Influx.
+func (w *InfluxWriter) WriteMetrics(ctx context.Context, ms []domain.Metric) error {
 	if w == nil || w.client == nil || w.write == nil {
 		return nil
 	}
 
+	points := make([]*write.Point, 0, len(ms))
+	for _, m := range ms {
+		if p := metricPoint(m); p != nil {
+			points = append(points, p)
+		}
+		points = append(points, instancePoints(m)...)
+	}
+	if len(points) == 0 {
+		return nil
+	}
+	return w.write.WritePoint(ctx, points...)
+}
+
+func metricPoint(m domain.Metric) *write.Point {
 	ts := m.Timestamp
 	if ts.IsZero() {
 		ts = time.Now().UTC()
 	}
 
+	values := metricFields(m)
+	if len(values) == 0 {
+		return nil
+	}
+
+	fields := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		fields[k] = v
+	}
+	return influxdb2.NewPoint(measurement, metricTags(m), fields, ts)
+}
+
+// instancePoints escribe cada instancia (punto de montaje, ...) en la
+// measurement de su grupo, con la instancia como tag.
+func instancePoints(m domain.Metric) []*write.Point {
+	samples := instanceSamples(m)
+	if len(samples) == 0 {
+		return nil
+	}
+	ts := m.Timestamp
+	if ts.IsZero() {
+		ts = time.Now().UTC()
+	}
+
+	points := make([]*write.Point, 0, len(samples))
+	for _, s := range samples {
+		tags := metricTags(m)
+		tags[s.group.tag] = s.instance
+		fields := make(map[string]interface{}, len(s.fields))
+		for k, v := range s.fields {
+			fields[k] = v
+		}
+		points = append(points, influxdb2.NewPoint(s.group.measurement, tags, fields, ts))
+	}
+	return points
+}
+
+// metricTags devuelve los tags (strings indexados) de una métrica.
+func metricTags(m domain.Metric) map[string]string {
 	tags := map[string]string{
 		"device": m.DeviceName,
 		"ip":     m.IpAddress,
@@ -60,8 +136,15 @@ func (w *InfluxWriter) WriteMetric(m domain.Metric) error {
 	if m.DeviceType != "" {
 		tags["type"] = m.DeviceType
 	}
+	if m.OwnerID != nil {
+		tags["owner"] = strconv.FormatUint(uint64(*m.OwnerID), 10)
+	}
+	return tags
+}
 
-	fields := map[string]interface{}{}
+// metricFields devuelve los valores numéricos de una métrica, por nombre de campo.
+func metricFields(m domain.Metric) map[string]float64 {
+	fields := map[string]float64{}
 
 	if m.CPUUsage != 0 {
 		fields["cpu"] = m.CPUUsage
@@ -79,6 +162,12 @@ func (w *InfluxWriter) WriteMetric(m domain.Metric) error {
 	if m.NetTxBytes != 0 {
 		fields["net_tx"] = float64(m.NetTxBytes)
 	}
+	if m.NetRxRate != nil {
+		fields["net_rx_rate"] = *m.NetRxRate
+	}
+	if m.NetTxRate != nil {
+		fields["net_tx_rate"] = *m.NetTxRate
+	}
 	if m.Temperature != 0 {
 		fields["temp"] = m.Temperature
 	}
@@ -86,16 +175,35 @@ func (w *InfluxWriter) WriteMetric(m domain.Metric) error {
 		fields["uptime"] = float64(m.UptimeSec)
 	}
 
+	// saturación: 0 es un valor válido, así que se escribe si el agente lo reportó
+	if l := m.Load; l != nil {
+		fields["load1"] = l.Load1
+		fields["load5"] = l.Load5
+		fields["load15"] = l.Load15
+	}
+	if sw := m.Swap; sw != nil && sw.TotalBytes > 0 {
+		fields["swap"] = sw.UsedPercent
+		fields["swap_used_bytes"] = float64(sw.UsedBytes)
+	}
+	if p := m.Pressure; p != nil {
+		fields["psi_cpu_some"] = p.CPUSome
+		fields["psi_memory_some"] = p.MemorySome
+		fields["psi_memory_full"] = p.MemoryFull
+		fields["psi_io_some"] = p.IOSome
+		fields["psi_io_full"] = p.IOFull
+	}
+	if m.CtxSwitchRate != nil {
+		fields["ctx_switch_rate"] = *m.CtxSwitchRate
+	}
+	if m.ForkRate != nil {
+		fields["fork_rate"] = *m.ForkRate
+	}
+
 	// if len(m.CPUPerCore) > 0 {
 	// 	for i, v := range m.CPUPerCore {
 	// 		fields[fmt.Sprintf("cpu_core_%d", i)] = v
 	// 	}
 	// }
 
-	if len(fields) == 0 {
-		return nil
-	}
-
-	p := influxdb2.NewPoint("system_metrics", tags, fields, ts)
-	return w.write.WritePoint(context.Background(), p)
+	return fields
 }
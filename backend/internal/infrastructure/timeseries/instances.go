@@ -0,0 +1,187 @@
+package timeseries
+
+import (
+	"sort"
+	"time"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+)
+
+// seriesGroup es una familia de series con una instancia por valor de tag
+// (un punto de montaje, una interfaz, ...). En Influx vive en su propia
+// measurement; en SQL la instancia va en la columna instance.
+type seriesGroup struct {
+	name        string // nombre en la API, ej. "mount"
+	measurement string
+	tag         string
+	fields      []string
+}
+
+var mountGroup = &seriesGroup{
+	name:        "mount",
+	measurement: "disk_mounts",
+	tag:         "mount",
+	fields:      []string{"mount_used_pct", "mount_free_bytes", "mount_total_bytes", "mount_inodes_used_pct", "mount_read_only"},
+}
+
+var ifaceGroup = &seriesGroup{
+	name:        "iface",
+	measurement: "net_ifaces",
+	tag:         "iface",
+	fields: []string{
+		"iface_up",
+		"iface_rx_bytes_rate", "iface_tx_bytes_rate",
+		"iface_rx_packets_rate", "iface_tx_packets_rate",
+		"iface_rx_errors_rate", "iface_tx_errors_rate",
+		"iface_rx_drops_rate", "iface_tx_drops_rate",
+	},
+}
+
+var diskGroup = &seriesGroup{
+	name:        "disk",
+	measurement: "disk_io",
+	tag:         "disk",
+	fields: []string{
+		"disk_read_bytes_rate", "disk_write_bytes_rate",
+		"disk_read_iops", "disk_write_iops",
+		"disk_await_ms", "disk_util_pct",
+	},
+}
+
+// seriesGroups es el catálogo de grupos, por nombre.
+var seriesGroups = map[string]*seriesGroup{
+	mountGroup.name: mountGroup,
+	ifaceGroup.name: ifaceGroup,
+	diskGroup.name:  diskGroup,
+}
+
+// fieldGroups indexa los campos por instancia con su grupo.
+var fieldGroups = func() map[string]*seriesGroup {
+	m := map[string]*seriesGroup{}
+	for _, g := range seriesGroups {
+		for _, f := range g.fields {
+			m[f] = g
+		}
+	}
+	return m
+}()
+
+// instanceSample son los valores de una instancia en una métrica recibida.
+type instanceSample struct {
+	group    *seriesGroup
+	instance string
+	fields   map[string]float64
+}
+
+// instanceSamples separa de la métrica las series por instancia.
+func instanceSamples(m domain.Metric) []instanceSample {
+	var out []instanceSample
+	for _, mt := range m.Mounts {
+		if mt.Path == "" {
+			continue
+		}
+		readOnly := 0.0
+		if mt.ReadOnly {
+			readOnly = 1
+		}
+		out = append(out, instanceSample{
+			group:    mountGroup,
+			instance: mt.Path,
+			fields: map[string]float64{
+				"mount_used_pct":        mt.UsedPercent,
+				"mount_free_bytes":      float64(mt.FreeBytes),
+				"mount_total_bytes":     float64(mt.TotalBytes),
+				"mount_inodes_used_pct": mt.InodesUsed,
+				"mount_read_only":       readOnly,
+			},
+		})
+	}
+	for _, ifc := range m.Interfaces {
+		if ifc.Name == "" {
+			continue
+		}
+		fields := map[string]float64{"iface_up": 0}
+		if ifc.Up {
+			fields["iface_up"] = 1
+		}
+		if r := ifc.Rates; r != nil {
+			fields["iface_rx_bytes_rate"] = r.RxBytes
+			fields["iface_tx_bytes_rate"] = r.TxBytes
+			fields["iface_rx_packets_rate"] = r.RxPackets
+			fields["iface_tx_packets_rate"] = r.TxPackets
+			fields["iface_rx_errors_rate"] = r.RxErrors
+			fields["iface_tx_errors_rate"] = r.TxErrors
+			fields["iface_rx_drops_rate"] = r.RxDrops
+			fields["iface_tx_drops_rate"] = r.TxDrops
+		}
+		out = append(out, instanceSample{group: ifaceGroup, instance: ifc.Name, fields: fields})
+	}
+	for _, d := range m.DiskIO {
+		if d.Name == "" {
+			continue
+		}
+		out = append(out, instanceSample{
+			group:    diskGroup,
+			instance: d.Name,
+			fields: map[string]float64{
+				"disk_read_bytes_rate":  d.ReadBytes,
+				"disk_write_bytes_rate": d.WriteBytes,
+				"disk_read_iops":        d.ReadIOPS,
+				"disk_write_iops":       d.WriteIOPS,
+				"disk_await_ms":         d.AwaitMs,
+				"disk_util_pct":         d.UtilPct,
+			},
+		})
+	}
+	return out
+}
+
+func parseGroup(name string) (*seriesGroup, error) {
+	g, ok := seriesGroups[name]
+	if !ok {
+		return nil, invalid("group", "unknown group %q", name)
+	}
+	return g, nil
+}
+
+// instanceRow es el último valor conocido de una instancia.
+type instanceRow struct {
+	instance string
+	time     time.Time
+	values   map[string]float64
+}
+
+// latestSnapshot se queda con las instancias de la última recolección (las que
+// desaparecieron, como un disco desmontado, dejan de listarse) ordenadas por nombre.
+func latestSnapshot(g *seriesGroup, rows []instanceRow) []map[string]interface{} {
+	var newest time.Time
+	for _, r := range rows {
+		if r.time.After(newest) {
+			newest = r.time
+		}
+	}
+
+	byInstance := map[string]map[string]interface{}{}
+	for _, r := range rows {
+		if !r.time.Equal(newest) {
+			continue
+		}
+		rec, ok := byInstance[r.instance]
+		if !ok {
+			rec = map[string]interface{}{g.tag: r.instance, "time": r.time}
+			byInstance[r.instance] = rec
+		}
+		for k, v := range r.values {
+			rec[k] = v
+		}
+	}
+
+	out := make([]map[string]interface{}, 0, len(byInstance))
+	for _, rec := range byInstance {
+		out = append(out, rec)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i][g.tag].(string) < out[j][g.tag].(string)
+	})
+	return out
+}
@@ -0,0 +1,152 @@
+package timeseries
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+)
+
+const (
+	maxRange    = 366 * 24 * time.Hour
+	minInterval = time.Second
+	maxPoints   = 20000 // máximo de ventanas por serie (7d a 1m son ~10k)
+	maxNameLen  = 255
+)
+
+// knownFields es la lista blanca de campos que se pueden consultar.
+var knownFields = func() map[string]bool {
+	m := map[string]bool{}
+	for _, f := range statFields {
+		m[f] = true
+	}
+	for f := range fieldGroups {
+		m[f] = true
+	}
+	return m
+}()
+
+// aggFuncs mapea la agregación pedida a la función Flux equivalente.
+var aggFuncs = map[string]string{
+	"mean": "mean",
+	"min":  "min",
+	"max":  "max",
+	"last": "last",
+	"sum":  "sum",
+}
+
+// seriesParams son los parámetros ya validados de TimeSeries.
+type seriesParams struct {
+	device   string
+	field    string
+	group    *seriesGroup // nil para las métricas del equipo
+	instance string
+	rng      time.Duration
+	every    time.Duration
+	agg      string
+}
+
+func invalid(param, format string, args ...interface{}) error {
+	return &domain.InvalidQueryError{Param: param, Reason: fmt.Sprintf(format, args...)}
+}
+
+func validateDevice(device string) error {
+	if device == "" {
+		return invalid("device", "required")
+	}
+	if len(device) > maxNameLen {
+		return invalid("device", "too long")
+	}
+	return nil
+}
+
+func validateField(field string) error {
+	if !knownFields[field] {
+		return invalid("field", "unknown field %q", field)
+	}
+	return nil
+}
+
+// validateInstance exige la instancia en los campos por instancia (ej. el punto
+// de montaje de mount_used_pct) y la rechaza en el resto.
+func validateInstance(g *seriesGroup, field, instance string) error {
+	if g == nil {
+		if instance != "" {
+			return invalid("instance", "field %q has no instances", field)
+		}
+		return nil
+	}
+	if instance == "" {
+		return invalid("instance", "required for field %q (a %s)", field, g.tag)
+	}
+	if len(instance) > maxNameLen {
+		return invalid("instance", "too long")
+	}
+	return nil
+}
+
+func parseRange(s string) (time.Duration, error) {
+	d, err := parseDuration(durOrDefault(s, "1h"))
+	if err != nil {
+		return 0, invalid("range", "%q is not a valid duration", s)
+	}
+	if d > maxRange {
+		return 0, invalid("range", "must be at most %s", "366d")
+	}
+	return d, nil
+}
+
+func parseInterval(s string) (time.Duration, error) {
+	d, err := parseDuration(intervalOrDefault(s, "1m"))
+	if err != nil {
+		return 0, invalid("interval", "%q is not a valid duration", s)
+	}
+	if d < minInterval {
+		return 0, invalid("interval", "must be at least 1s")
+	}
+	return d, nil
+}
+
+func parseAgg(s string) (string, error) {
+	if s == "" {
+		return "mean", nil
+	}
+	fn, ok := aggFuncs[s]
+	if !ok {
+		return "", invalid("agg", "must be one of mean, min, max, last, sum")
+	}
+	return fn, nil
+}
+
+func parseSeriesParams(device, field, instance, rangeDur, agg, interval string) (seriesParams, error) {
+	p := seriesParams{device: device, field: field, group: fieldGroups[field], instance: instance}
+	var err error
+
+	if err = validateDevice(device); err != nil {
+		return p, err
+	}
+	if err = validateField(field); err != nil {
+		return p, err
+	}
+	if err = validateInstance(p.group, field, instance); err != nil {
+		return p, err
+	}
+	if p.rng, err = parseRange(rangeDur); err != nil {
+		return p, err
+	}
+	if p.every, err = parseInterval(interval); err != nil {
+		return p, err
+	}
+	if p.agg, err = parseAgg(agg); err != nil {
+		return p, err
+	}
+	if p.rng/p.every > maxPoints {
+		return p, invalid("interval", "too small for range %s (max %d points)", rangeDur, maxPoints)
+	}
+	return p, nil
+}
+
+// fluxDuration escribe la duración como literal Flux en segundos ("90s").
+func fluxDuration(d time.Duration) string {
+	return fmt.Sprintf("%ds", int64(d/time.Second))
+}
@@ -2,11 +2,18 @@ package timeseries
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
 	"github.com/influxdata/influxdb-client-go/v2/api"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
 )
 
+// historyLimit es la cantidad máxima de filas que devuelve History.
+const historyLimit = 100
+
 type InfluxQuery struct {
 	Client influxdb2.Client
 	Org    string
@@ -29,21 +36,247 @@ func durOrDefault(s, def string) string {
 	return s
 }
 
-func aggOrDefault(s, def string) string {
-	switch s {
-	case "mean", "min", "max", "last", "sum":
-		return s
-	default:
-		if def == "" {
-			return "mean"
-		}
-		return def
-	}
-}
-
 func intervalOrDefault(s, def string) string {
 	if s == "" {
 		return def
 	}
 	return s
 }
+
+// ====== Consultas de InfluxWriter (implementa domain.MetricStore) ======
+
+func (w *InfluxWriter) queryAPI() (api.QueryAPI, error) {
+	if w == nil || w.client == nil || w.org == "" || w.bucket == "" {
+		return nil, fmt.Errorf("influx query no inicializado")
+	}
+	return w.client.QueryAPI(w.org), nil
+}
+
+// newQuery arranca una consulta sobre el bucket del writer.
+func (w *InfluxWriter) newQuery() *fluxQuery {
+	q := newFluxQuery(w.bucket)
+	q.legacyOwner = w.legacyOwner
+	return q
+}
+
+// LastStats retorna el último valor conocido de cada métrica para un dispositivo.
+func (w *InfluxWriter) LastStats(ctx context.Context, scope domain.MetricScope, device string) (map[string]interface{}, error) {
+	if err := validateDevice(device); err != nil {
+		return nil, err
+	}
+	qa, err := w.queryAPI()
+	if err != nil {
+		return nil, err
+	}
+
+	keep := append([]string{"_time"}, statFields...)
+	keep = append(keep, "os", "ip", "gateway")
+
+	q := w.newQuery()
+	q.pipe(`range(start: -24h)`).
+		pipe(`filter(fn: (r) => r._measurement == ` + q.param("measurement", measurement) + ` and r.device == ` + q.param("device", device) + q.ownerFilter(scope) + `)`).
+		pipe(`filter(fn: (r) => contains(value: r._field, set: ` + fieldsToFluxArray(statFields) + `))`).
+		pipe(`last()`).
+		pipe(`pivot(rowKey:["_time"], columnKey:["_field"], valueColumn:"_value")`).
+		pipe(`group()`).
+		pipe(`keep(columns: ` + fieldsToFluxArray(keep) + `)`)
+
+	res, err := q.run(ctx, qa)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+
+	out := map[string]interface{}{}
+	for res.Next() {
+		rec := res.Record()
+
+		// Tags (strings)
+		for _, tag := range []string{"os", "ip", "gateway"} {
+			if v, ok := rec.ValueByKey(tag).(string); ok {
+				out[tag] = v
+			}
+		}
+
+		// Fields (floats)
+		for _, k := range statFields {
+			if v := rec.ValueByKey(k); v != nil {
+				if f, ok := toFloat(v); ok {
+					out[k] = f
+				}
+			}
+		}
+	}
+	return out, res.Err()
+}
+
+// TimeSeries retorna una serie temporal para un `field` dado, con rango/agg/interval configurables.
+func (w *InfluxWriter) TimeSeries(ctx context.Context, scope domain.MetricScope, device, field, instance, rangeDur, agg, interval string) ([]domain.MetricPoint, error) {
+	p, err := parseSeriesParams(device, field, instance, rangeDur, agg, interval)
+	if err != nil {
+		return nil, err
+	}
+	qa, err := w.queryAPI()
+	if err != nil {
+		return nil, err
+	}
+
+	// fn sale de aggFuncs y el tag del catálogo, así que es seguro escribirlos en el texto
+	q := w.newQuery()
+	meas, instanceFilter := measurement, ""
+	if p.group != nil {
+		meas = p.group.measurement
+		instanceFilter = ` and r.` + p.group.tag + ` == ` + q.param("instance", p.instance)
+	}
+	q.pipe(`range(start: time(v: ` + q.param("start", time.Now().UTC().Add(-p.rng)) + `))`).
+		pipe(`filter(fn: (r) => r._measurement == ` + q.param("measurement", meas) +
+			` and r.device == ` + q.param("device", p.device) +
+			` and r._field == ` + q.param("field", p.field) + instanceFilter + q.ownerFilter(scope) + `)`).
+		pipe(`aggregateWindow(every: duration(v: ` + q.param("every", fluxDuration(p.every)) + `), fn: ` + p.agg + `, createEmpty: false)`).
+		pipe(`keep(columns: ["_time","_value"])`)
+
+	res, err := q.run(ctx, qa)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+
+	out := []domain.MetricPoint{}
+	for res.Next() {
+		rec := res.Record()
+		if f, ok := toFloat(rec.Value()); ok {
+			out = append(out, domain.MetricPoint{T: rec.Time(), V: f})
+		}
+	}
+	return out, res.Err()
+}
+
+// History retorna los últimos N registros para un dispositivo.
+func (w *InfluxWriter) History(ctx context.Context, scope domain.MetricScope, device, rangeDur string) ([]map[string]interface{}, error) {
+	if err := validateDevice(device); err != nil {
+		return nil, err
+	}
+	r, err := parseRange(rangeDur)
+	if err != nil {
+		return nil, err
+	}
+	qa, err := w.queryAPI()
+	if err != nil {
+		return nil, err
+	}
+
+	keep := append([]string{"_time"}, statFields...)
+
+	q := w.newQuery()
+	q.pipe(`range(start: time(v: ` + q.param("start", time.Now().UTC().Add(-r)) + `))`).
+		pipe(`filter(fn: (r) => r._measurement == ` + q.param("measurement", measurement) + ` and r.device == ` + q.param("device", device) + q.ownerFilter(scope) + `)`).
+		pipe(`filter(fn: (r) => contains(value: r._field, set: ` + fieldsToFluxArray(statFields) + `))`).
+		pipe(`pivot(rowKey:["_time"], columnKey:["_field"], valueColumn:"_value")`).
+		pipe(`keep(columns: ` + fieldsToFluxArray(keep) + `)`).
+		pipe(`sort(columns: ["_time"], desc: true)`).
+		pipe(fmt.Sprintf(`limit(n: %d)`, historyLimit))
+
+	res, err := q.run(ctx, qa)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+
+	out := make([]map[string]interface{}, 0)
+	for res.Next() {
+		rec := res.Record()
+		row := map[string]interface{}{
+			"time": rec.Time(),
+		}
+		for _, k := range statFields {
+			if v := rec.ValueByKey(k); v != nil {
+				if f, ok := toFloat(v); ok {
+					row[k] = f
+				}
+			}
+		}
+		out = append(out, row)
+	}
+	return out, res.Err()
+}
+
+// LastInstances retorna el último valor de cada instancia de un grupo
+// (ej. cada punto de montaje) para un dispositivo.
+func (w *InfluxWriter) LastInstances(ctx context.Context, scope domain.MetricScope, device, group string) ([]map[string]interface{}, error) {
+	if err := validateDevice(device); err != nil {
+		return nil, err
+	}
+	g, err := parseGroup(group)
+	if err != nil {
+		return nil, err
+	}
+	qa, err := w.queryAPI()
+	if err != nil {
+		return nil, err
+	}
+
+	keep := append([]string{"_time", g.tag}, g.fields...)
+
+	q := w.newQuery()
+	q.pipe(`range(start: -24h)`).
+		pipe(`filter(fn: (r) => r._measurement == ` + q.param("measurement", g.measurement) + ` and r.device == ` + q.param("device", device) + q.ownerFilter(scope) + `)`).
+		pipe(`filter(fn: (r) => contains(value: r._field, set: ` + fieldsToFluxArray(g.fields) + `))`).
+		pipe(`last()`).
+		pipe(`pivot(rowKey:["_time"], columnKey:["_field"], valueColumn:"_value")`).
+		pipe(`group()`).
+		pipe(`keep(columns: ` + fieldsToFluxArray(keep) + `)`)
+
+	res, err := q.run(ctx, qa)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+
+	var rows []instanceRow
+	for res.Next() {
+		rec := res.Record()
+		instance, _ := rec.ValueByKey(g.tag).(string)
+		row := instanceRow{instance: instance, time: rec.Time(), values: map[string]float64{}}
+		for _, k := range g.fields {
+			if f, ok := toFloat(rec.ValueByKey(k)); ok {
+				row.values[k] = f
+			}
+		}
+		rows = append(rows, row)
+	}
+	if err := res.Err(); err != nil {
+		return nil, err
+	}
+	return latestSnapshot(g, rows), nil
+}
+
+func toFloat(v any) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case float32:
+		return float64(x), true
+	case int64:
+		return float64(x), true
+	case int32:
+		return float64(x), true
+	case int:
+		return float64(x), true
+	default:
+		return 0, false
+	}
+}
+
+// fieldsToFluxArray escribe un arreglo Flux literal; usar sólo con listas fijas
+// (nunca con valores que vengan del request).
+func fieldsToFluxArray(fields []string) string {
+	out := "["
+	for i, f := range fields {
+		if i > 0 {
+			out += ","
+		}
+		out += `"` + f + `"`
+	}
+	out += "]"
+	return out
+}
@@ -0,0 +1,287 @@
+package timeseries
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/infrastructure/persistence"
+)
+
+// SQLStore guarda las métricas en tablas de Postgres usando la conexión GORM
+// existente. Pensado para instalaciones chicas y CI, donde no hay un InfluxDB.
+type SQLStore struct {
+	db *gorm.DB
+}
+
+func NewSQLStore(db *gorm.DB) *SQLStore {
+	return &SQLStore{db: db}
+}
+
+func (s *SQLStore) WriteMetrics(ctx context.Context, ms []domain.Metric) error {
+	var rows []persistence.MetricSampleModel
+	for _, m := range ms {
+		ts := m.Timestamp
+		if ts.IsZero() {
+			ts = time.Now().UTC()
+		}
+		row := persistence.MetricSampleModel{
+			Device:  m.DeviceName,
+			Time:    ts,
+			IP:      m.IpAddress,
+			Gateway: m.Gateway,
+			OS:      m.OS,
+			Type:    m.DeviceType,
+			OwnerID: m.OwnerID,
+		}
+		for field, v := range metricFields(m) {
+			row.Field, row.Value = field, v
+			rows = append(rows, row)
+		}
+		for _, is := range instanceSamples(m) {
+			row.Instance = is.instance
+			for field, v := range is.fields {
+				row.Field, row.Value = field, v
+				rows = append(rows, row)
+			}
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return s.db.WithContext(ctx).CreateInBatches(&rows, 500).Error
+}
+
+// LastStats retorna el último valor conocido de cada métrica para un dispositivo.
+func (s *SQLStore) LastStats(ctx context.Context, scope domain.MetricScope, device string) (map[string]interface{}, error) {
+	if err := validateDevice(device); err != nil {
+		return nil, err
+	}
+	var rows []persistence.MetricSampleModel
+	err := s.scoped(ctx, scope).
+		Select("DISTINCT ON (field) *").
+		Where("device = ? AND field IN ? AND time >= ?", device, statFields, time.Now().Add(-24*time.Hour)).
+		Order("field").
+		Order("time DESC").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	out := map[string]interface{}{}
+	var newest time.Time
+	for _, r := range rows {
+		out[r.Field] = r.Value
+		if r.Time.After(newest) {
+			newest = r.Time
+			out["os"] = r.OS
+			out["ip"] = r.IP
+			out["gateway"] = r.Gateway
+		}
+	}
+	return out, nil
+}
+
+// TimeSeries agrega en ventanas de `interval` los valores de un campo, igual que
+// aggregateWindow de Flux (cada punto lleva la hora de cierre de su ventana).
+func (s *SQLStore) TimeSeries(ctx context.Context, scope domain.MetricScope, device, field, instance, rangeDur, agg, interval string) ([]domain.MetricPoint, error) {
+	p, err := parseSeriesParams(device, field, instance, rangeDur, agg, interval)
+	if err != nil {
+		return nil, err
+	}
+	every := p.every
+
+	var rows []persistence.MetricSampleModel
+	err = s.scoped(ctx, scope).
+		Select("time", "value").
+		Where("device = ? AND field = ? AND instance = ? AND time >= ?", p.device, p.field, p.instance, time.Now().Add(-p.rng)).
+		Order("time").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	out := []domain.MetricPoint{}
+	var (
+		window time.Time
+		values []float64
+	)
+	flush := func() {
+		if len(values) > 0 {
+			out = append(out, domain.MetricPoint{T: window.Add(every), V: aggregate(p.agg, values)})
+		}
+		values = values[:0]
+	}
+	for _, row := range rows {
+		w := row.Time.Truncate(every)
+		if !w.Equal(window) {
+			flush()
+			window = w
+		}
+		values = append(values, row.Value)
+	}
+	flush()
+
+	return out, nil
+}
+
+// History retorna los últimos N registros para un dispositivo.
+func (s *SQLStore) History(ctx context.Context, scope domain.MetricScope, device, rangeDur string) ([]map[string]interface{}, error) {
+	if err := validateDevice(device); err != nil {
+		return nil, err
+	}
+	r, err := parseRange(rangeDur)
+	if err != nil {
+		return nil, err
+	}
+
+	// se leen los instantes más recientes y luego todos sus campos
+	var times []time.Time
+	err = s.scoped(ctx, scope).
+		Model(&persistence.MetricSampleModel{}).
+		Where("device = ? AND time >= ?", device, time.Now().Add(-r)).
+		Distinct("time").
+		Order("time DESC").
+		Limit(historyLimit).
+		Pluck("time", &times).Error
+	if err != nil || len(times) == 0 {
+		return []map[string]interface{}{}, err
+	}
+
+	var rows []persistence.MetricSampleModel
+	err = s.scoped(ctx, scope).
+		Where("device = ? AND field IN ? AND time IN ?", device, statFields, times).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byTime := make(map[int64]map[string]interface{}, len(times))
+	for _, t := range times {
+		byTime[t.UnixNano()] = map[string]interface{}{"time": t}
+	}
+	for _, row := range rows {
+		if rec, ok := byTime[row.Time.UnixNano()]; ok {
+			rec[row.Field] = row.Value
+		}
+	}
+
+	out := make([]map[string]interface{}, 0, len(times))
+	for _, t := range times {
+		out = append(out, byTime[t.UnixNano()])
+	}
+	return out, nil
+}
+
+// LastInstances retorna el último valor de cada instancia de un grupo
+// (ej. cada punto de montaje) para un dispositivo.
+func (s *SQLStore) LastInstances(ctx context.Context, scope domain.MetricScope, device, group string) ([]map[string]interface{}, error) {
+	if err := validateDevice(device); err != nil {
+		return nil, err
+	}
+	g, err := parseGroup(group)
+	if err != nil {
+		return nil, err
+	}
+	var samples []persistence.MetricSampleModel
+	err = s.scoped(ctx, scope).
+		Select("DISTINCT ON (instance, field) *").
+		Where("device = ? AND field IN ? AND time >= ?", device, g.fields, time.Now().Add(-24*time.Hour)).
+		Order("instance").
+		Order("field").
+		Order("time DESC").
+		Find(&samples).Error
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]instanceRow, 0, len(samples))
+	for _, r := range samples {
+		rows = append(rows, instanceRow{instance: r.Instance, time: r.Time, values: map[string]float64{r.Field: r.Value}})
+	}
+	return latestSnapshot(g, rows), nil
+}
+
+// scoped filtra por dueño salvo que el alcance sea global.
+func (s *SQLStore) scoped(ctx context.Context, scope domain.MetricScope) *gorm.DB {
+	db := s.db.WithContext(ctx)
+	if !scope.All {
+		db = db.Where("owner_id = ?", scope.UserID)
+	}
+	return db
+}
+
+// Prune borra las muestras anteriores a `before`.
+func (s *SQLStore) Prune(before time.Time) error {
+	return s.db.Where("time < ?", before).Delete(&persistence.MetricSampleModel{}).Error
+}
+
+func aggregate(fn string, values []float64) float64 {
+	switch fn {
+	case "min":
+		m := values[0]
+		for _, v := range values[1:] {
+			if v < m {
+				m = v
+			}
+		}
+		return m
+	case "max":
+		m := values[0]
+		for _, v := range values[1:] {
+			if v > m {
+				m = v
+			}
+		}
+		return m
+	case "last":
+		return values[len(values)-1]
+	case "sum":
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		return sum
+	default:
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		return sum / float64(len(values))
+	}
+}
+
+// parseDuration acepta lo mismo que time.ParseDuration más días y semanas ("7d", "2w"),
+// que son las unidades que usa el frontend y Flux.
+func parseDuration(s string) (time.Duration, error) {
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+	if unit == 0 {
+		d, err := time.ParseDuration(s)
+		if err == nil && d <= 0 {
+			err = fmt.Errorf("duración inválida: %s", s)
+		}
+		return d, err
+	}
+
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("duración inválida: %s", s)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// ParseRetention interpreta un período de retención ("30d", "720h", ...).
+func ParseRetention(s string) (time.Duration, error) {
+	return parseDuration(s)
+}
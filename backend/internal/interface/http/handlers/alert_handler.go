@@ -1,9 +1,14 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/usecase/service"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -26,6 +31,157 @@ func (h *AlertHandler) GetRecentAlerts(c *fiber.Ctx) error {
 	})
 }
 
+// GetActiveAlerts returns the caller's rules that are pending or firing.
+// GET /api/alerts/active?state=firing
+func (h *AlertHandler) GetActiveAlerts(c *fiber.Ctx) error {
+	scope, ok := metricScope(c)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	state := domain.AlertState(c.Query("state"))
+
+	alerts := make([]domain.ActiveAlert, 0)
+	for _, a := range h.service.GetActiveAlerts() {
+		if !scope.All && a.UserID != scope.UserID {
+			continue
+		}
+		if state != "" && a.State != state {
+			continue
+		}
+		alerts = append(alerts, a)
+	}
+
+	return c.JSON(fiber.Map{
+		"alerts": alerts,
+	})
+}
+
+const (
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 500
+)
+
+// GetHistory lists persisted alert events, newest first.
+// GET /api/alerts/history?device=&rule=&topology=&state=&acked=&from=&to=&page=1&limit=50
+func (h *AlertHandler) GetHistory(c *fiber.Ctx) error {
+	scope, ok := metricScope(c)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+	limit := c.QueryInt("limit", defaultHistoryLimit)
+	if limit < 1 || limit > maxHistoryLimit {
+		return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("limit must be between 1 and %d", maxHistoryLimit)})
+	}
+
+	filter := domain.AlertEventFilter{
+		Scope:      scope,
+		DeviceID:   c.Query("device"),
+		RuleID:     c.Query("rule"),
+		TopologyID: uint(c.QueryInt("topology", 0)),
+		State:      domain.AlertState(c.Query("state")),
+		Limit:      limit,
+		Offset:     (page - 1) * limit,
+	}
+	switch filter.State {
+	case "", domain.AlertFiring, domain.AlertResolved:
+	default:
+		return c.Status(400).JSON(fiber.Map{"error": "state must be firing or resolved"})
+	}
+	if v := c.Query("acked"); v != "" {
+		acked, err := strconv.ParseBool(v)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid acked"})
+		}
+		filter.Acked = &acked
+	}
+	for param, dst := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if v := c.Query(param); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return c.Status(400).JSON(fiber.Map{"error": "invalid " + param + ": expected RFC3339"})
+			}
+			*dst = t
+		}
+	}
+
+	events, total, err := h.service.History(filter)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"items": events,
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	})
+}
+
+// GetDeadLetters lists the mail the outbound queue gave up on (admin only).
+// GET /api/alerts/dead-letters?page=1&limit=50
+func (h *AlertHandler) GetDeadLetters(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+	limit := c.QueryInt("limit", defaultHistoryLimit)
+	if limit < 1 || limit > maxHistoryLimit {
+		return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("limit must be between 1 and %d", maxHistoryLimit)})
+	}
+
+	letters, total, err := h.service.DeadLetters(limit, (page-1)*limit)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"items": letters,
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	})
+}
+
+// Acknowledge marks an alert event as seen, with an optional comment.
+// POST /api/alerts/:id/ack
+func (h *AlertHandler) Acknowledge(c *fiber.Ctx) error {
+	scope, ok := metricScope(c)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid id"})
+	}
+
+	var req struct {
+		Comment string `json:"comment"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+	}
+
+	err = h.service.Acknowledge(uint(id), scope, req.Comment)
+	if errors.Is(err, domain.ErrAlertEventNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Alert acknowledged",
+	})
+}
+
 func (h *AlertHandler) SendTestEmail(c *fiber.Ctx) error {
 	var req struct {
 		Email string `json:"email"`
@@ -47,3 +203,71 @@ func (h *AlertHandler) SendTestEmail(c *fiber.Ctx) error {
 		"message": "Test email sent",
 	})
 }
+
+// SendTestNotification sends a sample alert through any output channel
+// (email, webhook, slack, discord or ntfy) using the node's configuration.
+func (h *AlertHandler) SendTestNotification(c *fiber.Ctx) error {
+	var req struct {
+		Channel string            `json:"channel"`
+		To      interface{}       `json:"to"` // string "a@x, b@y" or list
+		Cc      interface{}       `json:"cc"`
+		Bcc     interface{}       `json:"bcc"`
+		Subject string            `json:"subject"`
+		Body    string            `json:"body"`
+		HTML    string            `json:"html"`
+		URL     string            `json:"url"`
+		Method  string            `json:"method"`
+		Headers map[string]string `json:"headers"`
+		Secret  string            `json:"secret"`
+		Timeout string            `json:"timeout"`
+		Retries *int              `json:"retries"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if req.Channel == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Channel is required"})
+	}
+
+	out := domain.AlertOutput{
+		NodeID:  "test",
+		Channel: req.Channel,
+		Subject: req.Subject,
+		Body:    req.Body,
+		HTML:    req.HTML,
+		Webhook: domain.WebhookConfig{
+			URL:     strings.TrimSpace(req.URL),
+			Method:  strings.ToUpper(strings.TrimSpace(req.Method)),
+			Headers: req.Headers,
+			Secret:  req.Secret,
+			Timeout: req.Timeout,
+			Retries: req.Retries,
+		},
+	}
+
+	var invalid []string
+	for _, f := range []struct {
+		raw  interface{}
+		list *[]string
+	}{{req.To, &out.To}, {req.Cc, &out.Cc}, {req.Bcc, &out.Bcc}} {
+		var bad []string
+		*f.list, bad = service.ParseRecipients(f.raw)
+		invalid = append(invalid, bad...)
+	}
+	if len(invalid) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid recipients: " + strings.Join(invalid, ", ")})
+	}
+
+	if err := h.service.SendTestNotification(out); err != nil {
+		if errors.Is(err, domain.ErrBlockedDestination) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Test notification sent",
+	})
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
@@ -20,6 +21,7 @@ func (h *APITokenHandler) Create(c *fiber.Ctx) error {
 	var body struct {
 		Name       string `json:"name"`
 		DeviceName string `json:"device_name"`
+		Kind       string `json:"kind"`
 	}
 	if err := c.BodyParser(&body); err != nil || body.Name == "" || body.DeviceName == "" {
 		return c.Status(400).JSON(fiber.Map{"error": "name and device_name required"})
@@ -35,7 +37,10 @@ func (h *APITokenHandler) Create(c *fiber.Ctx) error {
 	}
 	uid := uint(uidFloat)
 
-	raw, err := h.svc.GenerateForUser(body.Name, body.DeviceName, uid)
+	raw, err := h.svc.GenerateForUser(body.Name, body.DeviceName, body.Kind, uid)
+	if errors.Is(err, service.ErrInvalidTokenKind) || errors.Is(err, service.ErrInvalidDevicePattern) {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
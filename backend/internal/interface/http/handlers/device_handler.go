@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/usecase/service"
+)
+
+type DeviceHandler struct {
+	svc *service.DeviceService
+}
+
+func NewDeviceHandler(svc *service.DeviceService) *DeviceHandler {
+	return &DeviceHandler{svc: svc}
+}
+
+// List devuelve los dispositivos registrados con su estado online/offline.
+// GET /api/devices
+func (h *DeviceHandler) List(c *fiber.Ctx) error {
+	scope, ok := metricScope(c)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	devs, err := h.svc.List(scope)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(devs)
+}
+
+// Get devuelve un dispositivo por nombre.
+// GET /api/devices/:name
+func (h *DeviceHandler) Get(c *fiber.Ctx) error {
+	scope, ok := metricScope(c)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	d, err := h.svc.Get(scope, c.Params("name"))
+	if errors.Is(err, domain.ErrDeviceNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(d)
+}
+
+// Delete quita un dispositivo del registro. Los admins pueden elegir el dueño
+// con ?owner= cuando hay varios equipos con ese nombre.
+// DELETE /api/devices/:name
+func (h *DeviceHandler) Delete(c *fiber.Ctx) error {
+	scope, ok := metricScope(c)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	err := h.svc.Delete(scope, uint(c.QueryInt("owner", 0)), c.Params("name"))
+	if errors.Is(err, domain.ErrDeviceNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.SendStatus(204)
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+	metricuc "github.com/BenjaminAGH/nocturnescope/backend/internal/usecase/service"
+)
+
+const maxBatchSize = 1000
+
+type batchItemResult struct {
+	Index  int    `json:"index"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// CreateBatch recibe un arreglo JSON (o un stream NDJSON) de métricas.
+// POST /api/metrics/batch
+func (h *MetricHandler) CreateBatch(c *fiber.Ctx) error {
+	raw, err := splitBatch(c.Body(), strings.Contains(c.Get("Content-Type"), "ndjson"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if len(raw) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "empty batch"})
+	}
+	if len(raw) > maxBatchSize {
+		return c.Status(413).JSON(fiber.Map{"error": fmt.Sprintf("batch too large (max %d)", maxBatchSize)})
+	}
+
+	tok := ingestToken(c)
+	if tok == nil {
+		return c.Status(401).JSON(fiber.Map{"error": "invalid api token"})
+	}
+
+	results := make([]batchItemResult, len(raw))
+	valid := make([]domain.Metric, 0, len(raw))
+	for i, item := range raw {
+		results[i] = batchItemResult{Index: i, Status: "ok"}
+
+		var m domain.Metric
+		if err := json.Unmarshal(item, &m); err != nil {
+			results[i].Status, results[i].Error = "error", "invalid json"
+			continue
+		}
+		if m.Timestamp.IsZero() {
+			m.Timestamp = time.Now().UTC()
+		}
+		device, err := h.tokens.ResolveDevice(tok, m.DeviceName)
+		if err != nil {
+			results[i].Status, results[i].Error = "error", err.Error()
+			continue
+		}
+		m.DeviceName = device
+		m.OwnerID = tok.UserID
+
+		if err := metricuc.ValidateMetric(m); err != nil {
+			results[i].Status, results[i].Error = "error", err.Error()
+			continue
+		}
+		valid = append(valid, m)
+	}
+
+	if len(valid) > 0 {
+		if err := h.svc.StoreBatch(valid); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
+	status := 201
+	switch {
+	case len(valid) == 0:
+		status = 400
+	case len(valid) < len(raw):
+		status = 207
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"accepted": len(valid),
+		"rejected": len(raw) - len(valid),
+		"results":  results,
+	})
+}
+
+// splitBatch separa el cuerpo en items sin decodificarlos, para poder
+// reportar errores por item.
+func splitBatch(body []byte, ndjson bool) ([]json.RawMessage, error) {
+	body = bytes.TrimSpace(body)
+	if !ndjson && len(body) > 0 && body[0] == '[' {
+		var items []json.RawMessage
+		if err := json.Unmarshal(body, &items); err != nil {
+			return nil, fmt.Errorf("invalid json array")
+		}
+		return items, nil
+	}
+
+	var items []json.RawMessage
+	sc := bufio.NewScanner(bytes.NewReader(body))
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		items = append(items, append(json.RawMessage(nil), line...))
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
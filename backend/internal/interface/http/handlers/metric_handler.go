@@ -10,11 +10,12 @@ import (
 )
 
 type MetricHandler struct {
-	svc *metricuc.MetricService
+	svc    *metricuc.MetricService
+	tokens *metricuc.TokenService
 }
 
-func NewMetricHandler(svc *metricuc.MetricService) *MetricHandler {
-	return &MetricHandler{svc: svc}
+func NewMetricHandler(svc *metricuc.MetricService, tokens *metricuc.TokenService) *MetricHandler {
+	return &MetricHandler{svc: svc, tokens: tokens}
 }
 
 func (h *MetricHandler) Create(c *fiber.Ctx) error {
@@ -23,13 +24,34 @@ func (h *MetricHandler) Create(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
 	}
 
+	tok := ingestToken(c)
+	if tok == nil {
+		return c.Status(401).JSON(fiber.Map{"error": "invalid api token"})
+	}
+	device, err := h.tokens.ResolveDevice(tok, body.DeviceName)
+	if err != nil {
+		return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+	}
+	body.DeviceName = device
+	body.OwnerID = tok.UserID
+
 	if body.Timestamp.IsZero() {
 		body.Timestamp = time.Now().UTC()
 	}
 
+	if err := metricuc.ValidateMetric(body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
 	if err := h.svc.Store(body); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
 	return c.Status(201).JSON(fiber.Map{"message": "metric stored"})
 }
+
+// ingestToken devuelve el API token que validó el middleware.
+func ingestToken(c *fiber.Ctx) *domain.APIToken {
+	tok, _ := c.Locals("api_token").(*domain.APIToken)
+	return tok
+}
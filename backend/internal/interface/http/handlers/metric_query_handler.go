@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"context"
+	"errors"
 
 	"github.com/gofiber/fiber/v2"
 
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
 	"github.com/BenjaminAGH/nocturnescope/backend/internal/usecase/service"
 )
 
@@ -17,9 +19,13 @@ func NewMetricQueryHandler(s *service.MetricService) *MetricQueryHandler {
 }
 
 func (h *MetricQueryHandler) Devices(c *fiber.Ctx) error {
-	devs, err := h.svc.ListDevices(context.Background())
+	scope, ok := metricScope(c)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	devs, err := h.svc.ListDevices(context.Background(), scope)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return queryError(c, err)
 	}
 	return c.JSON(devs)
 }
@@ -29,27 +35,37 @@ func (h *MetricQueryHandler) Last(c *fiber.Ctx) error {
 	if device == "" {
 		return c.Status(400).JSON(fiber.Map{"error": "missing device"})
 	}
-	stats, err := h.svc.LastStats(context.Background(), device)
+	scope, ok := metricScope(c)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	stats, err := h.svc.LastStats(context.Background(), scope, device)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return queryError(c, err)
 	}
 	return c.JSON(stats)
 }
 
 // GET /api/metrics/timeseries?device=...&field=cpu&range=30m&interval=1m&agg=mean
+// Las series por instancia llevan además &instance=, ej. field=mount_used_pct&instance=/data
 func (h *MetricQueryHandler) TimeSeries(c *fiber.Ctx) error {
 	device := c.Query("device")
 	field := c.Query("field")
 	if device == "" || field == "" {
 		return c.Status(400).JSON(fiber.Map{"error": "missing device or field"})
 	}
+	scope, ok := metricScope(c)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
 	rangeDur := c.Query("range", "1h")
 	interval := c.Query("interval", "1m")
 	agg := c.Query("agg", "mean")
+	instance := c.Query("instance")
 
-	points, err := h.svc.TimeSeries(context.Background(), device, field, rangeDur, agg, interval)
+	points, err := h.svc.TimeSeries(context.Background(), scope, device, field, instance, rangeDur, agg, interval)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return queryError(c, err)
 	}
 	return c.JSON(fiber.Map{"points": points})
 }
@@ -59,11 +75,87 @@ func (h *MetricQueryHandler) History(c *fiber.Ctx) error {
 	if device == "" {
 		return c.Status(400).JSON(fiber.Map{"error": "missing device"})
 	}
+	scope, ok := metricScope(c)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
 	rangeDur := c.Query("range", "1h")
 
-	data, err := h.svc.History(context.Background(), device, rangeDur)
+	data, err := h.svc.History(context.Background(), scope, device, rangeDur)
+	if err != nil {
+		return queryError(c, err)
+	}
+	return c.JSON(data)
+}
+
+// GET /api/metrics/mounts?device=...
+func (h *MetricQueryHandler) Mounts(c *fiber.Ctx) error {
+	return h.lastInstances(c, "mount")
+}
+
+// GET /api/metrics/interfaces?device=...
+func (h *MetricQueryHandler) Interfaces(c *fiber.Ctx) error {
+	return h.lastInstances(c, "iface")
+}
+
+// GET /api/metrics/disks?device=...
+func (h *MetricQueryHandler) Disks(c *fiber.Ctx) error {
+	return h.lastInstances(c, "disk")
+}
+
+// GET /api/metrics/processes?device=...
+func (h *MetricQueryHandler) Processes(c *fiber.Ctx) error {
+	device := c.Query("device")
+	if device == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "missing device"})
+	}
+	scope, ok := metricScope(c)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	snap, err := h.svc.Processes(context.Background(), scope, device)
+	if errors.Is(err, domain.ErrProcessesNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return queryError(c, err)
+	}
+	return c.JSON(snap)
+}
+
+// lastInstances responde el último valor de cada instancia del grupo.
+func (h *MetricQueryHandler) lastInstances(c *fiber.Ctx, group string) error {
+	device := c.Query("device")
+	if device == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "missing device"})
+	}
+	scope, ok := metricScope(c)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	data, err := h.svc.LastInstances(context.Background(), scope, device, group)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return queryError(c, err)
 	}
 	return c.JSON(data)
 }
+
+// metricScope arma el alcance de la consulta a partir del JWT: los admin ven
+// todos los dispositivos, el resto sólo los que empujaron sus propios tokens.
+func metricScope(c *fiber.Ctx) (domain.MetricScope, bool) {
+	uidFloat, ok := c.Locals("user_id").(float64)
+	if !ok {
+		return domain.MetricScope{}, false
+	}
+	role, _ := c.Locals("role").(string)
+	return domain.MetricScope{UserID: uint(uidFloat), All: role == "admin"}, true
+}
+
+// queryError responde 400 para parámetros inválidos y 500 para el resto.
+func queryError(c *fiber.Ctx, err error) error {
+	var invalid *domain.InvalidQueryError
+	if errors.As(err, &invalid) {
+		return c.Status(400).JSON(fiber.Map{"error": invalid.Error(), "param": invalid.Param})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+}
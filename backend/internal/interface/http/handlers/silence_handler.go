@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/usecase/service"
+)
+
+type SilenceHandler struct {
+	svc *service.SilenceService
+}
+
+func NewSilenceHandler(svc *service.SilenceService) *SilenceHandler {
+	return &SilenceHandler{svc: svc}
+}
+
+// List returns the caller's silences (every silence for admins).
+// GET /api/silences
+func (h *SilenceHandler) List(c *fiber.Ctx) error {
+	scope, ok := metricScope(c)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	silences, err := h.svc.List(scope)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(silences)
+}
+
+// Get returns one silence.
+// GET /api/silences/:id
+func (h *SilenceHandler) Get(c *fiber.Ctx) error {
+	scope, ok := metricScope(c)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid id"})
+	}
+	silence, err := h.svc.Get(uint(id), scope)
+	if err != nil {
+		return silenceError(c, err)
+	}
+	return c.JSON(silence)
+}
+
+// Create adds a silence or a recurring maintenance window.
+// POST /api/silences
+func (h *SilenceHandler) Create(c *fiber.Ctx) error {
+	scope, ok := metricScope(c)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	var silence domain.Silence
+	if err := c.BodyParser(&silence); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if err := h.svc.Create(scope, &silence); err != nil {
+		return silenceError(c, err)
+	}
+	return c.Status(201).JSON(silence)
+}
+
+// Update replaces the matchers and schedule of a silence.
+// PUT /api/silences/:id
+func (h *SilenceHandler) Update(c *fiber.Ctx) error {
+	scope, ok := metricScope(c)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid id"})
+	}
+	var silence domain.Silence
+	if err := c.BodyParser(&silence); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if err := h.svc.Update(uint(id), scope, &silence); err != nil {
+		return silenceError(c, err)
+	}
+	return c.JSON(silence)
+}
+
+// Delete removes a silence; rules it held start notifying on their next evaluation.
+// DELETE /api/silences/:id
+func (h *SilenceHandler) Delete(c *fiber.Ctx) error {
+	scope, ok := metricScope(c)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid id"})
+	}
+	if err := h.svc.Delete(uint(id), scope); err != nil {
+		return silenceError(c, err)
+	}
+	return c.SendStatus(204)
+}
+
+func silenceError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrSilenceNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidSilence):
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+}
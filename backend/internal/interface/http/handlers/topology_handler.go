@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
 	"github.com/BenjaminAGH/nocturnescope/backend/internal/usecase/service"
 )
 
@@ -46,6 +48,9 @@ func (h *TopologyHandler) Create(c *fiber.Ctx) error {
 	}
 
 	topology, err := h.svc.Save(uid, body.Name, string(dataBytes))
+	if errors.Is(err, domain.ErrInvalidTemplate) || errors.Is(err, domain.ErrInvalidTopology) {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -127,6 +132,9 @@ func (h *TopologyHandler) Update(c *fiber.Ctx) error {
 	}
 
 	topology, err := h.svc.Update(uint(id), uid, body.Name, string(dataBytes))
+	if errors.Is(err, domain.ErrInvalidTemplate) || errors.Is(err, domain.ErrInvalidTopology) {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
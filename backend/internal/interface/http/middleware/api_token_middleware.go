@@ -13,11 +13,14 @@ func APITokenRequired(tokenService *service.TokenService) fiber.Handler {
 			return c.Status(401).JSON(fiber.Map{"error": "missing api token"})
 		}
 
-		_, err := tokenService.Validate(token)
+		tok, err := tokenService.Validate(token)
 		if err != nil {
 			return c.Status(401).JSON(fiber.Map{"error": "invalid api token"})
 		}
 
+		// el token validado identifica al dueño de las métricas que se ingieren
+		c.Locals("api_token", tok)
+
 		return c.Next()
 	}
 }
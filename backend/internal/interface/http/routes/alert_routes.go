@@ -3,6 +3,7 @@ package routes
 import (
 	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
 	"github.com/BenjaminAGH/nocturnescope/backend/internal/interface/http/handlers"
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/interface/http/middleware"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -10,5 +11,10 @@ func RegisterAlertRoutes(router fiber.Router, service domain.AlertService) {
 	handler := handlers.NewAlertHandler(service)
 
 	router.Get("/alerts/recent", handler.GetRecentAlerts)
+	router.Get("/alerts/active", handler.GetActiveAlerts)
+	router.Get("/alerts/history", handler.GetHistory)
+	router.Get("/alerts/dead-letters", middleware.RequireRole("admin"), handler.GetDeadLetters)
+	router.Post("/alerts/:id/ack", handler.Acknowledge)
 	router.Post("/alerts/test-email", handler.SendTestEmail)
+	router.Post("/alerts/test-notification", handler.SendTestNotification)
 }
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/interface/http/handlers"
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/usecase/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+func RegisterDeviceRoutes(r fiber.Router, svc *service.DeviceService) {
+	h := handlers.NewDeviceHandler(svc)
+	g := r.Group("/devices")
+
+	g.Get("/", h.List)
+	g.Get("/:name", h.Get)
+	g.Delete("/:name", h.Delete)
+}
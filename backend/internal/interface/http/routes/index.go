@@ -15,9 +15,11 @@ func Register(
 	authService *service.AuthService,
 	jwtService *security.JWTService,
 	metricService *service.MetricService,
+	deviceService *service.DeviceService,
 	apiTokenService *service.TokenService,
 	topologyService *service.TopologyService,
 	alertService *service.AlertService,
+	silenceService *service.SilenceService,
 ) {
 	api := app.Group("/api")
 
@@ -35,6 +37,9 @@ func Register(
 
 	RegisterMetricQueryRoutes(protected, metricService)
 
+	// registro de dispositivos
+	RegisterDeviceRoutes(protected, deviceService)
+
 	// api tokens del usuario
 	apiTokenHandler := handlers.NewAPITokenHandler(apiTokenService)
 	protected.Post("/api-tokens", apiTokenHandler.Create)
@@ -48,4 +53,7 @@ func Register(
 
 	// alertas
 	RegisterAlertRoutes(protected, alertService)
+
+	// silencios y ventanas de mantenimiento
+	RegisterSilenceRoutes(protected, silenceService)
 }
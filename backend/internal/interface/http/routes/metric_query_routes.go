@@ -13,4 +13,8 @@ func RegisterMetricQueryRoutes(r fiber.Router, svc *service.MetricService) {
     g.Get("/last",        h.Last)
     g.Get("/timeseries",  h.TimeSeries)
     g.Get("/history",     h.History)
+    g.Get("/mounts",      h.Mounts)
+    g.Get("/interfaces",  h.Interfaces)
+    g.Get("/disks",       h.Disks)
+    g.Get("/processes",   h.Processes)
 }
\ No newline at end of file
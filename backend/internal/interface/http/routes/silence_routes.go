@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/interface/http/handlers"
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/usecase/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+func RegisterSilenceRoutes(r fiber.Router, svc *service.SilenceService) {
+	h := handlers.NewSilenceHandler(svc)
+	g := r.Group("/silences")
+
+	g.Post("/", h.Create)
+	g.Get("/", h.List)
+
+	g.Get("/:id", h.Get)
+	g.Put("/:id", h.Update)
+	g.Delete("/:id", h.Delete)
+}
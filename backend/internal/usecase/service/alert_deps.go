@@ -0,0 +1,107 @@
+package service
+
+import (
+	"slices"
+	"time"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+)
+
+// deviceAddr is what an agent last reported about its network position.
+type deviceAddr struct {
+	ip      string
+	gateway string
+}
+
+func (s *AlertService) recordAddr(m domain.Metric) {
+	if m.IpAddress == "" && m.Gateway == "" {
+		return
+	}
+	s.addrsMu.Lock()
+	defer s.addrsMu.Unlock()
+	s.addrs[metricDevice(m)] = deviceAddr{ip: m.IpAddress, gateway: m.Gateway}
+}
+
+// gatewayDevice returns the monitored device whose address is the gateway
+// the given device reports, if any. Private addresses repeat across users, so
+// only the owner's own devices are considered.
+func (s *AlertService) gatewayDevice(device deviceKey) string {
+	s.addrsMu.Lock()
+	defer s.addrsMu.Unlock()
+
+	gw := s.addrs[device].gateway
+	if gw == "" {
+		return ""
+	}
+	for key, addr := range s.addrs {
+		if key.owner == device.owner && key != device && addr.ip == gw {
+			return key.name
+		}
+	}
+	return ""
+}
+
+// heldBy returns the upstream device whose outage holds the notifications of
+// rule, or "" when it should notify as usual. Parents are the devices drawn
+// upstream in the topology plus the one acting as its reported gateway, all
+// of the rule's owner.
+func (s *AlertService) heldBy(rule domain.AlertRule, now time.Time) string {
+	if rule.Expr != nil {
+		return ""
+	}
+	device := ruleDevice(rule)
+	parents := rule.Upstream
+	if gw := s.gatewayDevice(device); gw != "" && !slices.Contains(parents, gw) {
+		parents = append(slices.Clip(parents), gw)
+	}
+
+	for _, parent := range parents {
+		if parent != rule.DeviceID && s.deviceDown(deviceKey{owner: rule.UserID, name: parent}, now) {
+			return parent
+		}
+	}
+	return ""
+}
+
+// deviceDown reports whether a device stopped reporting: silent for longer
+// than its heartbeat window, or the default one when no rule watches it. A
+// device not heard from since startup only counts as down when a heartbeat
+// rule expects it; otherwise it may just not run an agent (a router).
+func (s *AlertService) deviceDown(device deviceKey, now time.Time) bool {
+	s.seenMu.Lock()
+	defer s.seenMu.Unlock()
+
+	window, expected := s.heartbeatWindows[device]
+	if !expected {
+		window = defaultHeartbeatWindow
+	}
+	last, ok := s.lastSeen[device]
+	if !ok {
+		if !expected {
+			return false
+		}
+		last = s.startedAt
+	}
+	return now.Sub(last) > window
+}
+
+// indexHeartbeats records the shortest heartbeat window of each device, so
+// deviceDown doesn't need the rules lock. Must be called with s.mu held.
+func (s *AlertService) indexHeartbeats() {
+	windows := make(map[deviceKey]time.Duration)
+	for _, rules := range s.rules {
+		for _, rule := range rules {
+			if rule.Metric != MetricHeartbeat {
+				continue
+			}
+			key, window := ruleDevice(rule), heartbeatWindow(rule)
+			if w, ok := windows[key]; !ok || window < w {
+				windows[key] = window
+			}
+		}
+	}
+
+	s.seenMu.Lock()
+	defer s.seenMu.Unlock()
+	s.heartbeatWindows = windows
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+)
+
+func TestHeldBy(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	child := domain.AlertRule{ID: "cpu", TopologyID: 1, UserID: 1, DeviceID: "web-1", Metric: "cpu"}
+	behindRouter := child
+	behindRouter.Upstream = []string{"router"}
+
+	type report struct {
+		owner  uint
+		device string
+		ago    time.Duration
+		ip, gw string
+	}
+
+	tests := []struct {
+		name    string
+		rule    domain.AlertRule
+		rules   []domain.AlertRule // heartbeat rules, with or without outputs
+		reports []report
+		want    string
+	}{
+		{"upstream reporting", behindRouter, nil, []report{{1, "router", time.Minute, "", ""}}, ""},
+		{"upstream silent past the default window", behindRouter, nil, []report{{1, "router", 10 * time.Minute, "", ""}}, "router"},
+		{"upstream silent past its heartbeat window", behindRouter,
+			[]domain.AlertRule{{ID: "hb", TopologyID: 1, UserID: 1, DeviceID: "router", Metric: MetricHeartbeat, Window: "1m"}},
+			[]report{{1, "router", 2 * time.Minute, "", ""}}, "router"},
+		{"upstream never seen and not expected", behindRouter, nil, nil, ""},
+		{"upstream never seen but expected", behindRouter,
+			[]domain.AlertRule{{ID: "hb", TopologyID: 1, UserID: 1, DeviceID: "router", Metric: MetricHeartbeat, Window: "1m"}},
+			nil, "router"},
+		{"same name silent under another owner", behindRouter, nil,
+			[]report{{1, "router", time.Minute, "", ""}, {2, "router", time.Hour, "", ""}}, ""},
+		{"gateway of the owner down", child, nil, []report{
+			{1, "web-1", 0, "192.168.1.10", "192.168.1.1"},
+			{1, "gw", 10 * time.Minute, "192.168.1.1", ""},
+		}, "gw"},
+		{"gateway address of another owner down", child, nil, []report{
+			{1, "web-1", 0, "192.168.1.10", "192.168.1.1"},
+			{2, "other-gw", 10 * time.Minute, "192.168.1.1", ""},
+		}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestAlertService()
+			s.startedAt = now.Add(-time.Hour)
+			s.UpdateRules(1, tt.rules)
+			for _, r := range tt.reports {
+				owner := r.owner
+				m := domain.Metric{DeviceName: r.device, OwnerID: &owner, IpAddress: r.ip, Gateway: r.gw}
+				s.recordAddr(m)
+				s.lastSeen[metricDevice(m)] = now.Add(-r.ago)
+			}
+
+			if got := s.heldBy(tt.rule, now); got != tt.want {
+				t.Fatalf("heldBy = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
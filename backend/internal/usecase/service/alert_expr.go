@@ -0,0 +1,115 @@
+package service
+
+import (
+	"time"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+)
+
+// condResult is the last evaluation of a rule's condition.
+type condResult struct {
+	breached bool
+	value    float64
+}
+
+// observe feeds a condition result to every rule that depends on it: the rule
+// itself when it has outputs of its own, and the composite rules reading it.
+func (s *AlertService) observe(rule domain.AlertRule, breached bool, val float64, now time.Time) {
+	if len(rule.Outputs) > 0 {
+		s.transition(rule, breached, val, now)
+	}
+
+	type result struct {
+		rule     domain.AlertRule
+		breached bool
+	}
+	key := stateKey(rule)
+
+	s.condMu.Lock()
+	s.conds[key] = condResult{breached: breached, value: val}
+	var results []result
+	for _, composite := range s.dependents[key] {
+		if v, known := s.evalExpr(composite.Expr); known {
+			results = append(results, result{rule: composite, breached: v})
+		}
+	}
+	s.condMu.Unlock()
+
+	for _, r := range results {
+		val := 0.0
+		if r.breached {
+			val = 1
+		}
+		s.transition(r.rule, r.breached, val, now)
+	}
+}
+
+// evalExpr evaluates with three-valued logic: a condition not observed yet is
+// unknown, and so is any and/or/not whose outcome depends on it, so a
+// composite rule doesn't fire (or resolve) on missing data. Must be called
+// with s.condMu held.
+func (s *AlertService) evalExpr(e *domain.AlertExpr) (value, known bool) {
+	if e.Cond != nil {
+		r, ok := s.conds[stateKey(*e.Cond)]
+		return r.breached, ok
+	}
+
+	switch e.Op {
+	case domain.ExprNot:
+		if len(e.Args) != 1 {
+			return false, false
+		}
+		v, k := s.evalExpr(e.Args[0])
+		return !v, k
+	case domain.ExprAnd:
+		known = true
+		for _, a := range e.Args {
+			v, k := s.evalExpr(a)
+			if k && !v {
+				return false, true
+			}
+			known = known && k
+		}
+		return known, known
+	case domain.ExprOr:
+		known = true
+		for _, a := range e.Args {
+			v, k := s.evalExpr(a)
+			if k && v {
+				return true, true
+			}
+			known = known && k
+		}
+		return false, known
+	}
+	return false, false
+}
+
+// indexDependents maps each condition to the composite rules reading it, and
+// forgets the results of conditions no rule has anymore. Must be called with
+// s.mu held.
+func (s *AlertService) indexDependents() {
+	dependents := make(map[string][]domain.AlertRule)
+	leaves := make(map[string]bool)
+	for _, rules := range s.rules {
+		for _, rule := range rules {
+			if rule.Expr == nil {
+				leaves[stateKey(rule)] = true
+				continue
+			}
+			for _, cond := range rule.Expr.Leaves() {
+				key := stateKey(*cond)
+				dependents[key] = append(dependents[key], rule)
+			}
+		}
+	}
+
+	s.condMu.Lock()
+	defer s.condMu.Unlock()
+	s.dependents = dependents
+	for key := range s.conds {
+		if !leaves[key] {
+			delete(s.conds, key)
+		}
+	}
+}
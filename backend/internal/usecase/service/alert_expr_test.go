@@ -0,0 +1,137 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+)
+
+// tri is the outcome of a condition or expression: true, false or unknown.
+type tri int
+
+const (
+	unknown tri = iota
+	yes
+	no
+)
+
+func (v tri) String() string {
+	return [...]string{"unknown", "true", "false"}[v]
+}
+
+func triOf(value, known bool) tri {
+	switch {
+	case !known:
+		return unknown
+	case value:
+		return yes
+	}
+	return no
+}
+
+var (
+	condA = &domain.AlertRule{ID: "a", TopologyID: 1, DeviceID: "web-1", Metric: "cpu"}
+	condB = &domain.AlertRule{ID: "b", TopologyID: 1, DeviceID: "web-1", Metric: "ram"}
+	condC = &domain.AlertRule{ID: "c", TopologyID: 1, DeviceID: "db-1", Metric: "disk"}
+)
+
+func leaf(r *domain.AlertRule) *domain.AlertExpr { return &domain.AlertExpr{Cond: r} }
+
+func op(name string, args ...*domain.AlertExpr) *domain.AlertExpr {
+	return &domain.AlertExpr{Op: name, Args: args}
+}
+
+func TestEvalExpr(t *testing.T) {
+	and := op(domain.ExprAnd, leaf(condA), leaf(condB))
+	or := op(domain.ExprOr, leaf(condA), leaf(condB))
+	not := op(domain.ExprNot, leaf(condA))
+	nested := op(domain.ExprOr, op(domain.ExprAnd, leaf(condA), leaf(condB)), op(domain.ExprNot, leaf(condC)))
+
+	tests := []struct {
+		name    string
+		expr    *domain.AlertExpr
+		a, b, c tri
+		want    tri
+	}{
+		{"leaf known", leaf(condA), yes, unknown, unknown, yes},
+		{"leaf unknown", leaf(condA), unknown, unknown, unknown, unknown},
+
+		{"and true true", and, yes, yes, unknown, yes},
+		{"and true false", and, yes, no, unknown, no},
+		{"and false unknown", and, no, unknown, unknown, no},
+		{"and unknown false", and, unknown, no, unknown, no},
+		{"and true unknown", and, yes, unknown, unknown, unknown},
+		{"and unknown unknown", and, unknown, unknown, unknown, unknown},
+
+		{"or false false", or, no, no, unknown, no},
+		{"or false true", or, no, yes, unknown, yes},
+		{"or true unknown", or, yes, unknown, unknown, yes},
+		{"or unknown true", or, unknown, yes, unknown, yes},
+		{"or false unknown", or, no, unknown, unknown, unknown},
+		{"or unknown unknown", or, unknown, unknown, unknown, unknown},
+
+		{"not true", not, yes, unknown, unknown, no},
+		{"not false", not, no, unknown, unknown, yes},
+		{"not unknown", not, unknown, unknown, unknown, unknown},
+
+		{"nested and decides", nested, yes, yes, unknown, yes},
+		{"nested not decides", nested, unknown, unknown, no, yes},
+		{"nested both false", nested, no, unknown, yes, no},
+		{"nested still unknown", nested, yes, unknown, yes, unknown},
+
+		{"not without input", op(domain.ExprNot), yes, yes, yes, unknown},
+		{"unknown operator", op("xor", leaf(condA), leaf(condB)), yes, no, unknown, unknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestAlertService()
+			for rule, v := range map[*domain.AlertRule]tri{condA: tt.a, condB: tt.b, condC: tt.c} {
+				if v != unknown {
+					s.conds[stateKey(*rule)] = condResult{breached: v == yes}
+				}
+			}
+
+			s.condMu.Lock()
+			got := triOf(s.evalExpr(tt.expr))
+			s.condMu.Unlock()
+			if got != tt.want {
+				t.Fatalf("a=%s b=%s c=%s: got %s, want %s", tt.a, tt.b, tt.c, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestObserveComposite(t *testing.T) {
+	s := newTestAlertService()
+	composite := domain.AlertRule{ID: "both", TopologyID: 1, Expr: op(domain.ExprAnd, leaf(condA), leaf(condB))}
+	s.UpdateRules(1, []domain.AlertRule{*condA, *condB, composite})
+
+	now := time.Now()
+	steps := []struct {
+		cond     *domain.AlertRule
+		breached bool
+		want     domain.AlertState
+	}{
+		// b was never observed: the composite doesn't move
+		{condA, true, domain.AlertInactive},
+		{condB, true, domain.AlertFiring},
+		{condA, false, domain.AlertResolved},
+		{condB, false, domain.AlertResolved},
+		{condA, true, domain.AlertResolved},
+		{condB, true, domain.AlertFiring},
+	}
+	for i, st := range steps {
+		now = now.Add(time.Minute)
+		s.observe(*st.cond, st.breached, 0, now)
+		if got, _ := s.stateOf(composite); got != st.want {
+			t.Fatalf("step %d (%s breached=%v): composite = %s, want %s", i, st.cond.ID, st.breached, got, st.want)
+		}
+	}
+
+	// rules without outputs of their own only feed the composite
+	if _, st := s.stateOf(*condA); st != nil {
+		t.Fatalf("condition a got a state machine of its own: %s", st.state)
+	}
+}
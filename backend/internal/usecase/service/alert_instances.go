@@ -0,0 +1,121 @@
+package service
+
+import "github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+
+// mountValue reads a per-mount metric. With an instance it is that mount's
+// value; without one, the worst mount's: the fullest, the one with the least
+// free space, 1 if any is read-only. -1 when no mount matches.
+func mountValue(mounts []domain.MountUsage, metric, instance string) float64 {
+	val := -1.0
+	for _, mt := range mounts {
+		if instance != "" && mt.Path != instance {
+			continue
+		}
+		var v float64
+		switch metric {
+		case "mount_used_pct":
+			v = mt.UsedPercent
+		case "mount_inodes_used_pct":
+			v = mt.InodesUsed
+		case "mount_free_bytes":
+			v = float64(mt.FreeBytes)
+		case "mount_read_only":
+			if mt.ReadOnly {
+				v = 1
+			}
+		default:
+			return -1
+		}
+
+		lowerIsWorse := metric == "mount_free_bytes"
+		if val == -1 || (lowerIsWorse && v < val) || (!lowerIsWorse && v > val) {
+			val = v
+		}
+	}
+	return val
+}
+
+// ifaceValue reads a per-interface metric. With an instance it is that
+// interface's value; without one, the worst: the highest rate, 0 if any link
+// is down. -1 when no interface matches or it has no rates yet.
+func ifaceValue(ifaces []domain.InterfaceStats, metric, instance string) float64 {
+	val := -1.0
+	for _, ifc := range ifaces {
+		if instance != "" && ifc.Name != instance {
+			continue
+		}
+		if metric == "iface_up" {
+			v := 0.0
+			if ifc.Up {
+				v = 1
+			}
+			if val == -1 || v < val {
+				val = v
+			}
+			continue
+		}
+
+		r := ifc.Rates
+		if r == nil {
+			continue
+		}
+		var v float64
+		switch metric {
+		case "iface_rx_bytes_rate":
+			v = r.RxBytes
+		case "iface_tx_bytes_rate":
+			v = r.TxBytes
+		case "iface_rx_packets_rate":
+			v = r.RxPackets
+		case "iface_tx_packets_rate":
+			v = r.TxPackets
+		case "iface_rx_errors_rate":
+			v = r.RxErrors
+		case "iface_tx_errors_rate":
+			v = r.TxErrors
+		case "iface_rx_drops_rate":
+			v = r.RxDrops
+		case "iface_tx_drops_rate":
+			v = r.TxDrops
+		default:
+			return -1
+		}
+		if v > val {
+			val = v
+		}
+	}
+	return val
+}
+
+// diskValue reads a per-disk I/O metric. With an instance it is that disk's
+// value; without one, the busiest disk's. -1 when no disk matches, which
+// includes the agent's first sample.
+func diskValue(disks []domain.DiskIO, metric, instance string) float64 {
+	val := -1.0
+	for _, d := range disks {
+		if instance != "" && d.Name != instance {
+			continue
+		}
+		var v float64
+		switch metric {
+		case "disk_read_bytes_rate":
+			v = d.ReadBytes
+		case "disk_write_bytes_rate":
+			v = d.WriteBytes
+		case "disk_read_iops":
+			v = d.ReadIOPS
+		case "disk_write_iops":
+			v = d.WriteIOPS
+		case "disk_await_ms":
+			v = d.AwaitMs
+		case "disk_util_pct":
+			v = d.UtilPct
+		default:
+			return -1
+		}
+		if v > val {
+			val = v
+		}
+	}
+	return val
+}
@@ -1,80 +1,133 @@
 package service
 
 import (
-	"crypto/tls"
+	"context"
+	"errors"
 	"fmt"
-	"net/smtp"
-	"os"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/infrastructure/notify"
 )
 
 type AlertService struct {
 	rules      map[uint][]domain.AlertRule
 	mu         sync.RWMutex
-	smtpHost   string
-	smtpPort   string
-	smtpUser   string
-	smtpPass   string
-	lastSent   map[string]time.Time // Key: ruleID, Value: last sent time
+	mailer     *notify.Mailer
+	mailQueue  *notify.MailQueue
+	deadMail   domain.MailDeadLetterRepository
+	lastSent   map[string]time.Time // Key: outputKey(rule, output), Value: last sent time
 	lastSentMu sync.Mutex
+
+	// heartbeat: last ingest per device
+	startedAt        time.Time
+	lastSeen         map[deviceKey]time.Time
+	heartbeatWindows map[deviceKey]time.Duration // shortest heartbeat window per device
+	seenMu           sync.Mutex
+
+	states  map[string]*ruleState // Key: stateKey(rule)
+	stateMu sync.Mutex
+
+	// rolling buffers for rules aggregating over a window
+	buffers map[bufferKey]*sampleBuffer
+	windows map[bufferKey]time.Duration // longest window needed per series
+	bufMu   sync.Mutex
+
+	// address each device last reported, for implicit gateway dependencies
+	addrs   map[deviceKey]deviceAddr
+	addrsMu sync.Mutex
+
+	// silences and maintenance windows muting notifications
+	silences  []domain.Silence
+	silenceMu sync.RWMutex
+
+	// composite rules: last result of each condition and the rules reading it
+	conds      map[string]condResult         // Key: stateKey(rule)
+	dependents map[string][]domain.AlertRule // Key: stateKey(condition)
+	condMu     sync.Mutex
+
+	events domain.AlertEventRepository
 }
 
-func NewAlertService() *AlertService {
-	host := os.Getenv("SMTP_HOST")
-	port := os.Getenv("SMTP_PORT")
-	user := os.Getenv("SMTP_USER")
-	pass := os.Getenv("SMTP_PASS")
+const (
+	MetricHeartbeat = "heartbeat"
+	MetricComposite = "composite" // rules built from and/or/not logic nodes
+
+	defaultHeartbeatWindow = 5 * time.Minute
+	heartbeatCheckEvery    = 15 * time.Second
+)
+
+func NewAlertService(events domain.AlertEventRepository, deadMail domain.MailDeadLetterRepository) *AlertService {
+	mailer := notify.NewMailerFromEnv()
 
 	// Validate SMTP configuration
-	if host != "" && port != "" && user != "" && pass != "" {
+	if mailer.Configured() {
 		fmt.Println("[AlertService] Validating SMTP configuration...")
-		auth := smtp.PlainAuth("", user, pass, host)
-		client, err := smtp.Dial(fmt.Sprintf("%s:%s", host, port))
-		if err != nil {
-			fmt.Printf("[AlertService] WARNING: Could not connect to SMTP server: %v\n", err)
+		if err := mailer.Verify(); err != nil {
+			fmt.Printf("[AlertService] WARNING: %v\n", err)
 		} else {
-			if err = client.StartTLS(&tls.Config{ServerName: host}); err != nil {
-				fmt.Printf("[AlertService] WARNING: Could not start TLS: %v\n", err)
-			} else {
-				if err = client.Auth(auth); err != nil {
-					fmt.Printf("[AlertService] WARNING: SMTP Authentication failed: %v\n", err)
-				} else {
-					fmt.Println("[AlertService] SMTP Configuration Verified Successfully ✅")
-					client.Quit()
-				}
-			}
+			fmt.Println("[AlertService] SMTP Configuration Verified Successfully ✅")
 		}
 	} else {
 		fmt.Println("[AlertService] SMTP configuration missing or incomplete. Email alerts will be disabled.")
 	}
 
-	return &AlertService{
+	s := &AlertService{
 		rules:    make(map[uint][]domain.AlertRule),
-		smtpHost: host,
-		smtpPort: port,
-		smtpUser: user,
-		smtpPass: pass,
+		mailer:   mailer,
+		deadMail: deadMail,
 		lastSent: make(map[string]time.Time),
+
+		startedAt: time.Now(),
+		lastSeen:  make(map[deviceKey]time.Time),
+
+		states: make(map[string]*ruleState),
+
+		buffers: make(map[bufferKey]*sampleBuffer),
+		windows: make(map[bufferKey]time.Duration),
+
+		addrs: make(map[deviceKey]deviceAddr),
+
+		conds:      make(map[string]condResult),
+		dependents: make(map[string][]domain.AlertRule),
+
+		events: events,
 	}
+	s.mailQueue = notify.NewMailQueue(mailer, deadMail, notify.MailQueueOptionsFromEnv())
+	s.mailQueue.Start()
+	s.restore()
+	return s
 }
 
 func (s *AlertService) UpdateRules(topologyID uint, rules []domain.AlertRule) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.rules[topologyID] = rules
+	s.pruneStates(topologyID, rules)
+	s.resizeBuffers()
+	s.indexDependents()
+	s.indexHeartbeats()
 	fmt.Printf("[AlertService] Updated rules for topology %d: %d rules active\n", topologyID, len(rules))
 }
 
 func (s *AlertService) Evaluate(m domain.Metric) {
+	device := metricDevice(m)
+	s.recordAddr(m)
+	s.markSeen(device)
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	now := time.Now()
+	at := sampleTime(m, now)
+	s.bufferSample(m, at, now)
+
 	for _, rules := range s.rules {
 		for _, rule := range rules {
-			if rule.DeviceID != m.DeviceName {
+			if rule.Expr != nil || ruleDevice(rule) != device || rule.Metric == MetricHeartbeat {
 				continue
 			}
 
@@ -82,22 +135,45 @@ func (s *AlertService) Evaluate(m domain.Metric) {
 			// The metric struct usually has fields like CPU, RAM. We need to check the specific field.
 			// Since domain.Metric is a struct, we might need to reflect or check based on the rule.Metric string.
 
-			val := getMetricValue(m, rule.Metric)
+			val := getMetricValue(m, rule.Metric, rule.Instance)
 			if val == -1 {
 				// fmt.Printf("[AlertService] DEBUG: Metric %s not found in data for device %s\n", rule.Metric, m.DeviceName)
 				continue
 			}
 
+			if window, ok := aggregationWindow(rule); ok {
+				if now.Sub(at) > window {
+					// a replayed sample too old to say anything about the window
+					continue
+				}
+				if val, ok = s.windowValue(rule, window, now); !ok {
+					continue
+				}
+			}
+
 			fmt.Printf("[AlertService] DEBUG: Evaluating Rule %s: %s %s %f (Current: %f)\n", rule.ID, rule.Metric, rule.Operator, rule.Threshold, val)
 
-			if checkThreshold(val, rule.Operator, rule.Threshold) {
-				s.triggerAlert(rule, val)
-			}
+			s.observe(rule, checkThreshold(val, rule.Operator, rule.Threshold), val, now)
 		}
 	}
 }
 
-func getMetricValue(m domain.Metric, metricType string) float64 {
+// getMetricValue extracts the value a rule reads from a metric, or -1 when the
+// metric doesn't carry it. instance selects the mount or interface of
+// per-instance metrics.
+func getMetricValue(m domain.Metric, metricType, instance string) float64 {
+	if strings.HasPrefix(metricType, "mount_") {
+		return mountValue(m.Mounts, metricType, instance)
+	}
+	if strings.HasPrefix(metricType, "iface_") {
+		return ifaceValue(m.Interfaces, metricType, instance)
+	}
+	if strings.HasPrefix(metricType, "disk_") {
+		return diskValue(m.DiskIO, metricType, instance)
+	}
+	if strings.HasPrefix(metricType, "psi_") {
+		return pressureValue(m.Pressure, metricType)
+	}
 	switch metricType {
 	case "cpu":
 		return m.CPUUsage
@@ -107,6 +183,90 @@ func getMetricValue(m domain.Metric, metricType string) float64 {
 		return m.DiskUsage
 	case "temp":
 		return m.Temperature
+	case "net_rx_rate":
+		if m.NetRxRate == nil {
+			return -1
+		}
+		return *m.NetRxRate
+	case "net_tx_rate":
+		if m.NetTxRate == nil {
+			return -1
+		}
+		return *m.NetTxRate
+	case "uptime":
+		if m.UptimeSec == 0 {
+			return -1
+		}
+		return float64(m.UptimeSec)
+	case "reboot":
+		// 1 on the first sample after the device rebooted, 0 otherwise
+		if m.UptimeSec == 0 {
+			return -1
+		}
+		if m.Rebooted {
+			return 1
+		}
+		return 0
+	case "load1", "load5", "load15":
+		if m.Load == nil {
+			return -1
+		}
+		switch metricType {
+		case "load1":
+			return m.Load.Load1
+		case "load5":
+			return m.Load.Load5
+		}
+		return m.Load.Load15
+	case "swap":
+		// devices without swap never match
+		if m.Swap == nil || m.Swap.TotalBytes == 0 {
+			return -1
+		}
+		return m.Swap.UsedPercent
+	case "ctx_switch_rate":
+		if m.CtxSwitchRate == nil {
+			return -1
+		}
+		return *m.CtxSwitchRate
+	case "fork_rate":
+		if m.ForkRate == nil {
+			return -1
+		}
+		return *m.ForkRate
+	case "cpu_core_max":
+		if len(m.CPUPerCore) == 0 {
+			return -1
+		}
+		max := m.CPUPerCore[0]
+		for _, v := range m.CPUPerCore[1:] {
+			if v > max {
+				max = v
+			}
+		}
+		return max
+	default:
+		return -1
+	}
+}
+
+// pressureValue reads a Linux PSI percentage, or -1 when the device doesn't
+// report pressure (non-Linux or a kernel without PSI).
+func pressureValue(p *domain.Pressure, metric string) float64 {
+	if p == nil {
+		return -1
+	}
+	switch metric {
+	case "psi_cpu_some":
+		return p.CPUSome
+	case "psi_memory_some":
+		return p.MemorySome
+	case "psi_memory_full":
+		return p.MemoryFull
+	case "psi_io_some":
+		return p.IOSome
+	case "psi_io_full":
+		return p.IOFull
 	default:
 		return -1
 	}
@@ -129,29 +289,113 @@ func checkThreshold(val float64, op string, threshold float64) bool {
 	}
 }
 
-func (s *AlertService) triggerAlert(rule domain.AlertRule, val float64) {
-	s.lastSentMu.Lock()
-	last, ok := s.lastSent[rule.ID]
+// ruleDevice returns the key of the device a rule watches. Device names only
+// identify a device within its owner, so rules only see their user's metrics.
+func ruleDevice(rule domain.AlertRule) deviceKey {
+	return deviceKey{owner: rule.UserID, name: rule.DeviceID}
+}
 
-	// Parse cooldown duration
-	cooldown, err := time.ParseDuration(rule.Cooldown)
-	if err != nil {
-		cooldown = 1 * time.Hour // Fallback default
+// markSeen records an ingest for the device, which clears any heartbeat rule
+// that was alerting on it.
+func (s *AlertService) markSeen(device deviceKey) {
+	now := time.Now()
+
+	s.seenMu.Lock()
+	last, known := s.lastSeen[device]
+	s.lastSeen[device] = now
+	s.seenMu.Unlock()
+
+	silence := time.Duration(0)
+	if known {
+		silence = now.Sub(last)
+	}
+	for _, rule := range s.heartbeatRules(device) {
+		s.observe(rule, false, silence.Seconds(), now)
+	}
+}
+
+// heartbeatRules returns the heartbeat rules for a device (all devices if zero).
+func (s *AlertService) heartbeatRules(device deviceKey) []domain.AlertRule {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var out []domain.AlertRule
+	for _, rules := range s.rules {
+		for _, rule := range rules {
+			if rule.Metric == MetricHeartbeat && (device == deviceKey{} || ruleDevice(rule) == device) {
+				out = append(out, rule)
+			}
+		}
 	}
+	return out
+}
 
-	// Cooldown logic
-	if ok && time.Since(last) < cooldown {
-		fmt.Printf("[AlertService] Cooldown active for rule %s. Time remaining: %v\n", rule.ID, cooldown-time.Since(last))
-		s.lastSentMu.Unlock()
-		return
+// StartHeartbeatMonitor runs the absence evaluator in the background. Metrics
+// arriving only trigger Evaluate, so a device that goes silent needs this loop.
+func (s *AlertService) StartHeartbeatMonitor() {
+	go func() {
+		ticker := time.NewTicker(heartbeatCheckEvery)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			s.checkHeartbeats(now)
+		}
+	}()
+}
+
+func (s *AlertService) checkHeartbeats(now time.Time) {
+	rules := s.heartbeatRules(deviceKey{})
+	// upstream devices first (more devices behind them), so when a whole
+	// branch goes silent the parent is already down when its children fire
+	sort.SliceStable(rules, func(i, j int) bool {
+		return len(rules[i].Downstream) > len(rules[j].Downstream)
+	})
+	for _, rule := range rules {
+		window := heartbeatWindow(rule)
+
+		s.seenMu.Lock()
+		last, ok := s.lastSeen[ruleDevice(rule)]
+		if !ok {
+			// nothing received since startup: count from then
+			last = s.startedAt
+		}
+		s.seenMu.Unlock()
+
+		silence := now.Sub(last)
+		s.observe(rule, silence > window, silence.Seconds(), now)
 	}
+}
 
-	s.lastSent[rule.ID] = time.Now()
-	s.lastSentMu.Unlock()
+// heartbeatWindow is how long a device may stay silent before rule fires.
+func heartbeatWindow(rule domain.AlertRule) time.Duration {
+	window, err := time.ParseDuration(rule.Window)
+	if err != nil || window <= 0 {
+		return defaultHeartbeatWindow
+	}
+	return window
+}
 
-	fmt.Printf("[AlertService] Triggering alert for rule %s: %s %s %f (Value: %f)\n", rule.ID, rule.Metric, rule.Operator, rule.Threshold, val)
+// outputKey identifies one output of a rule for cooldown purposes. Node IDs
+// repeat across topologies, so it starts with the topology like stateKey.
+func outputKey(rule domain.AlertRule, out domain.AlertOutput) string {
+	return stateKey(rule) + "/" + out.NodeID
+}
+
+// allowSend applies the output cooldown and records the send time.
+func (s *AlertService) allowSend(rule domain.AlertRule, out domain.AlertOutput) bool {
+	s.lastSentMu.Lock()
+	defer s.lastSentMu.Unlock()
 
-	go s.sendEmail(rule, val)
+	cooldown, err := time.ParseDuration(out.Cooldown)
+	if err != nil {
+		cooldown = 1 * time.Hour // Fallback default
+	}
+	key := outputKey(rule, out)
+	if last, ok := s.lastSent[key]; ok && time.Since(last) < cooldown {
+		fmt.Printf("[AlertService] Cooldown active for rule %s output %s. Time remaining: %v\n", rule.ID, out.NodeID, cooldown-time.Since(last))
+		return false
+	}
+	s.lastSent[key] = time.Now()
+	return true
 }
 
 func (s *AlertService) GetRecentAlerts(window time.Duration) []string {
@@ -159,60 +403,25 @@ func (s *AlertService) GetRecentAlerts(window time.Duration) []string {
 	defer s.lastSentMu.Unlock()
 
 	var recent []string
+	seen := make(map[string]bool)
 	now := time.Now()
-	for id, t := range s.lastSent {
-		if now.Sub(t) < window {
+	for key, t := range s.lastSent {
+		// key is topology/rule/node
+		_, rest, _ := strings.Cut(key, "/")
+		id, _, _ := strings.Cut(rest, "/")
+		if now.Sub(t) < window && !seen[id] {
+			seen[id] = true
 			recent = append(recent, id)
 		}
 	}
 	return recent
 }
 
-func (s *AlertService) sendEmail(rule domain.AlertRule, val float64) {
-	if s.smtpHost == "" || s.smtpUser == "" {
-		return
-	}
-
-	auth := smtp.PlainAuth("", s.smtpUser, s.smtpPass, s.smtpHost)
-	to := []string{rule.EmailTo}
-
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-
-	msg := []byte(fmt.Sprintf("To: %s\r\n"+
-		"Subject: [NocturneScope Alert] %s\r\n"+
-		"MIME-Version: 1.0\r\n"+
-		"Content-Type: text/plain; charset=\"utf-8\"\r\n"+
-		"\r\n"+
-		"ALERT NOTIFICATION\r\n"+
-		"==================\r\n"+
-		"Time: %s\r\n"+
-		"Device: %s\r\n"+
-		"Metric: %s\r\n"+
-		"Condition: %s %.2f\r\n"+
-		"Current Value: %.2f\r\n"+
-		"\r\n"+
-		"Message:\r\n"+
-		"%s\r\n"+
-		"\r\n"+
-		"--\r\n"+
-		"NocturneScope Monitoring System\r\n",
-		rule.EmailTo, rule.EmailSubject, timestamp, rule.DeviceID, rule.Metric, rule.Operator, rule.Threshold, val, rule.EmailBody))
-
-	addr := fmt.Sprintf("%s:%s", s.smtpHost, s.smtpPort)
-	err := smtp.SendMail(addr, auth, s.smtpUser, to, msg)
-	if err != nil {
-		fmt.Printf("[AlertService] Error sending email: %v\n", err)
-	} else {
-		fmt.Printf("[AlertService] Email sent to %s\n", rule.EmailTo)
-	}
-}
-
 func (s *AlertService) SendTestEmail(toEmail string) error {
-	if s.smtpHost == "" || s.smtpUser == "" {
+	if !s.mailer.Configured() {
 		return fmt.Errorf("SMTP not configured")
 	}
 
-	auth := smtp.PlainAuth("", s.smtpUser, s.smtpPass, s.smtpHost)
 	to := []string{toEmail}
 	timestamp := time.Now().Format("2006-01-02 15:04:05")
 
@@ -232,8 +441,7 @@ func (s *AlertService) SendTestEmail(toEmail string) error {
 		"NocturneScope Monitoring System\r\n",
 		toEmail, timestamp))
 
-	addr := fmt.Sprintf("%s:%s", s.smtpHost, s.smtpPort)
-	err := smtp.SendMail(addr, auth, s.smtpUser, to, msg)
+	err := s.mailer.Send(to, msg)
 	if err != nil {
 		fmt.Printf("[AlertService] Error sending test email: %v\n", err)
 		return err
@@ -242,3 +450,59 @@ func (s *AlertService) SendTestEmail(toEmail string) error {
 	fmt.Printf("[AlertService] Test email sent to %s\n", toEmail)
 	return nil
 }
+
+// SendTestNotification delivers a sample notification through an output
+// (any node type), rendering its templates, so it can be checked before saving.
+func (s *AlertService) SendTestNotification(out domain.AlertOutput) error {
+	switch out.Channel {
+	case domain.ChannelEmail:
+		if len(out.To)+len(out.Cc)+len(out.Bcc) == 0 {
+			return fmt.Errorf("email recipient is required")
+		}
+	case domain.ChannelWebhook, domain.ChannelSlack, domain.ChannelDiscord, domain.ChannelNtfy:
+		if !validWebhookURL(out.Webhook.URL) {
+			return fmt.Errorf("invalid %s URL %q: must be an http(s) endpoint", out.Channel, out.Webhook.URL)
+		}
+	default:
+		return fmt.Errorf("unknown channel %q", out.Channel)
+	}
+	if out.Subject == "" {
+		out.Subject = "Test notification"
+	}
+	if out.Body == "" {
+		out.Body = "This is a test notification from your NocturneScope instance."
+	}
+
+	n := domain.Notification{
+		RuleID:    "test",
+		Topology:  "test",
+		Device:    "test-device",
+		Metric:    "cpu",
+		Operator:  ">",
+		Threshold: 80,
+		Value:     92.5,
+		State:     domain.AlertFiring,
+		Samples:   []domain.Sample{{Time: time.Now().Add(-time.Minute), Value: 85}, {Time: time.Now(), Value: 92.5}},
+		Time:      time.Now(),
+	}
+	render("test", out, &n)
+
+	// every target is tried; the errors of those that failed are reported together
+	var errs []error
+	for _, t := range s.targets(out) {
+		err := t.notifier.Notify(context.Background(), n)
+		if errors.Is(err, domain.ErrNotifyDisabled) {
+			return fmt.Errorf("%s channel not configured", out.Channel)
+		}
+		if err != nil {
+			fmt.Printf("[AlertService] Error sending test %s notification to %s: %v\n", out.Channel, t.name, err)
+			errs = append(errs, fmt.Errorf("%s: %w", t.name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	fmt.Printf("[AlertService] Test %s notification sent\n", out.Channel)
+	return nil
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+)
+
+func TestAllowSendCooldown(t *testing.T) {
+	out := domain.AlertOutput{NodeID: "email-1", Cooldown: "1h"}
+	first := domain.AlertRule{ID: "act-1", TopologyID: 1}
+
+	tests := []struct {
+		name string
+		rule domain.AlertRule
+		out  domain.AlertOutput
+		want bool
+	}{
+		{"same rule and output", first, out, false},
+		{"same node IDs in another topology", domain.AlertRule{ID: "act-1", TopologyID: 2}, out, true},
+		{"another output of the rule", first, domain.AlertOutput{NodeID: "email-2", Cooldown: "1h"}, true},
+		{"another rule", domain.AlertRule{ID: "act-2", TopologyID: 1}, out, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestAlertService()
+			if !s.allowSend(first, out) {
+				t.Fatal("first send was held by the cooldown")
+			}
+			if got := s.allowSend(tt.rule, tt.out); got != tt.want {
+				t.Fatalf("allowSend = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetRecentAlerts(t *testing.T) {
+	s := newTestAlertService()
+	s.allowSend(domain.AlertRule{ID: "act-1", TopologyID: 1}, domain.AlertOutput{NodeID: "email-1"})
+	s.allowSend(domain.AlertRule{ID: "act-1", TopologyID: 1}, domain.AlertOutput{NodeID: "email-2"})
+	s.allowSend(domain.AlertRule{ID: "act-3", TopologyID: 12}, domain.AlertOutput{NodeID: "email-1"})
+
+	got := map[string]bool{}
+	for _, id := range s.GetRecentAlerts(time.Hour) {
+		if got[id] {
+			t.Fatalf("rule %s listed twice", id)
+		}
+		got[id] = true
+	}
+	if len(got) != 2 || !got["act-1"] || !got["act-3"] {
+		t.Fatalf("GetRecentAlerts = %v, want act-1 and act-3", got)
+	}
+}
+
+// restoredEvents is an event repository holding only the cooldowns to restore.
+type restoredEvents struct {
+	domain.AlertEventRepository
+	last map[string]time.Time
+}
+
+func (r restoredEvents) LastNotified() (map[string]time.Time, error) { return r.last, nil }
+func (r restoredEvents) Open() ([]domain.AlertEvent, error)          { return nil, nil }
+
+func TestRestoreCooldowns(t *testing.T) {
+	s := newTestAlertService()
+	s.events = restoredEvents{last: map[string]time.Time{"1/act-1/email-1": time.Now()}}
+	s.restore()
+
+	out := domain.AlertOutput{NodeID: "email-1", Cooldown: "1h"}
+	if s.allowSend(domain.AlertRule{ID: "act-1", TopologyID: 1}, out) {
+		t.Error("restored cooldown was not applied")
+	}
+	if !s.allowSend(domain.AlertRule{ID: "act-1", TopologyID: 2}, out) {
+		t.Error("cooldown of topology 1 held topology 2")
+	}
+}
+
+// silentOutput gives a rule a state machine of its own without anyone to
+// deliver to (an email output with no recipients).
+var silentOutput = []domain.AlertOutput{{NodeID: "email-1", Channel: domain.ChannelEmail}}
+
+func owned(owner uint, device string, cpu float64) domain.Metric {
+	return domain.Metric{DeviceName: device, OwnerID: &owner, CPUUsage: cpu}
+}
+
+func TestEvaluateOnlyOwnDevices(t *testing.T) {
+	rule := domain.AlertRule{ID: "cpu", TopologyID: 1, UserID: 1, DeviceID: "web-1", Metric: "cpu", Operator: ">", Threshold: 90, Outputs: silentOutput}
+
+	tests := []struct {
+		name   string
+		metric domain.Metric
+		want   domain.AlertState
+	}{
+		{"owner's device", owned(1, "web-1", 95), domain.AlertFiring},
+		{"same name, another owner", owned(2, "web-1", 95), domain.AlertInactive},
+		{"unowned metric", domain.Metric{DeviceName: "web-1", CPUUsage: 95}, domain.AlertInactive},
+		{"another device of the owner", owned(1, "web-2", 95), domain.AlertInactive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestAlertService()
+			s.UpdateRules(1, []domain.AlertRule{rule})
+			s.Evaluate(tt.metric)
+			if got, _ := s.stateOf(rule); got != tt.want {
+				t.Fatalf("state = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeartbeatPerOwner(t *testing.T) {
+	rule := domain.AlertRule{ID: "hb", TopologyID: 1, UserID: 1, DeviceID: "web-1", Metric: MetricHeartbeat, Window: "1m", Outputs: silentOutput}
+
+	tests := []struct {
+		name string
+		seen []domain.Metric
+		want domain.AlertState
+	}{
+		{"device reporting", []domain.Metric{owned(1, "web-1", 0)}, domain.AlertInactive},
+		{"only another owner's device of the same name", []domain.Metric{owned(2, "web-1", 0)}, domain.AlertFiring},
+		{"nothing received", nil, domain.AlertFiring},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestAlertService()
+			s.startedAt = time.Now().Add(-time.Hour)
+			s.UpdateRules(1, []domain.AlertRule{rule})
+			for _, m := range tt.seen {
+				s.Evaluate(m)
+			}
+
+			s.checkHeartbeats(time.Now().Add(30 * time.Second))
+			if got, _ := s.stateOf(rule); got != tt.want {
+				t.Fatalf("state = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+)
+
+// UpdateSilences replaces the silences and maintenance windows in effect.
+func (s *AlertService) UpdateSilences(silences []domain.Silence) {
+	s.silenceMu.Lock()
+	defer s.silenceMu.Unlock()
+	s.silences = silences
+	fmt.Printf("[AlertService] Updated silences: %d configured\n", len(silences))
+}
+
+// silencedBy returns the silence muting the rule at now, if any.
+func (s *AlertService) silencedBy(rule domain.AlertRule, now time.Time) *domain.Silence {
+	s.silenceMu.RLock()
+	defer s.silenceMu.RUnlock()
+	for i := range s.silences {
+		if s.silences[i].Matches(rule) && s.silences[i].ActiveAt(now) {
+			silence := s.silences[i]
+			return &silence
+		}
+	}
+	return nil
+}
+
+// holdMessage explains why a firing was not notified.
+func holdMessage(parent string, silence *domain.Silence) string {
+	switch {
+	case silence != nil && silence.Comment != "":
+		return fmt.Sprintf("silenced by #%d (%s): %s", silence.ID, silence.Author, silence.Comment)
+	case silence != nil:
+		return fmt.Sprintf("silenced by #%d (%s)", silence.ID, silence.Author)
+	case parent != "":
+		return "upstream device " + parent + " is down"
+	}
+	return ""
+}
@@ -0,0 +1,544 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/infrastructure/notify"
+)
+
+// ruleState tracks where a rule is in the inactive → pending → firing → resolved cycle.
+type ruleState struct {
+	rule     domain.AlertRule
+	state    domain.AlertState
+	since    time.Time       // when the current state was entered
+	value    float64         // last observed value
+	notified map[string]bool // output nodes that got the firing, so they hear the resolution too
+	eventID  uint            // persisted AlertEvent for the current firing
+	samples  []domain.Sample
+	heldBy   string // upstream device that was down when the rule fired; nobody was notified
+	silenced uint   // silence active when the rule fired; nobody was notified either
+}
+
+// maxTemplateSamples is how many recent observations templates see as .Samples.
+const maxTemplateSamples = 10
+
+// stateKey identifies a rule across topologies (node IDs are only unique within one).
+func stateKey(rule domain.AlertRule) string {
+	return fmt.Sprintf("%d/%s", rule.TopologyID, rule.ID)
+}
+
+// transition feeds a new observation of the rule condition into its state machine.
+func (s *AlertService) transition(rule domain.AlertRule, breached bool, val float64, now time.Time) {
+	forDur, err := time.ParseDuration(rule.For)
+	if err != nil || forDur < 0 {
+		forDur = 0
+	}
+
+	key := stateKey(rule)
+
+	var held string
+	var silence *domain.Silence
+	if breached {
+		held = s.heldBy(rule, now)
+		silence = s.silencedBy(rule, now)
+	}
+	muted := held != "" || silence != nil
+
+	s.stateMu.Lock()
+	st, ok := s.states[key]
+	if !ok {
+		st = &ruleState{state: domain.AlertInactive, since: now}
+		s.states[key] = st
+	}
+	st.rule = rule
+	st.value = val
+	st.samples = append(st.samples, domain.Sample{Time: now, Value: val})
+	if len(st.samples) > maxTemplateSamples {
+		st.samples = st.samples[len(st.samples)-maxTemplateSamples:]
+	}
+	samples := append([]domain.Sample(nil), st.samples...)
+
+	var fire, release bool
+	var releaseEvent uint
+	var resolveTo []domain.AlertOutput
+	var resolvedEvent uint
+	if breached {
+		if st.state == domain.AlertInactive || st.state == domain.AlertResolved {
+			st.state, st.since = domain.AlertPending, now
+		}
+		switch {
+		case st.state == domain.AlertPending && now.Sub(st.since) >= forDur:
+			st.state, st.since = domain.AlertFiring, now
+			st.heldBy = held
+			if silence != nil {
+				st.silenced = silence.ID
+			}
+			fire = true
+		case st.state == domain.AlertFiring && (st.heldBy != "" || st.silenced != 0) && !muted:
+			// the upstream device is back or the silence is over, and this is
+			// still failing: time to tell someone
+			st.heldBy, st.silenced = "", 0
+			release, releaseEvent = true, st.eventID
+		}
+	} else {
+		switch st.state {
+		case domain.AlertPending:
+			st.state, st.since = domain.AlertInactive, now
+		case domain.AlertFiring:
+			st.state, st.since = domain.AlertResolved, now
+			if st.heldBy == "" && st.silenced == 0 {
+				// a firing nobody was told about resolves quietly too
+				resolveTo = notifiedOutputs(rule, st.notified)
+			}
+			st.notified = nil
+			resolvedEvent, st.eventID = st.eventID, 0
+			st.heldBy, st.silenced = "", 0
+		}
+	}
+	s.stateMu.Unlock()
+
+	if fire {
+		fmt.Printf("[AlertService] Rule %s firing: %s %s %f (Value: %f)\n", rule.ID, rule.Metric, rule.Operator, rule.Threshold, val)
+		eventID := s.recordFiring(rule, val, now)
+
+		var send []domain.AlertOutput
+		if !muted {
+			send = s.cooledDown(eventID, rule)
+		} else {
+			fmt.Printf("[AlertService] Rule %s not notified: %s\n", rule.ID, holdMessage(held, silence))
+			for _, out := range rule.Outputs {
+				s.recordDelivery(eventID, rule, out, "", domain.AlertFiring, domain.NotifySuppressed, nil)
+			}
+		}
+
+		s.stateMu.Lock()
+		// the condition may have cleared concurrently while the event was saved
+		stillFiring := st.state == domain.AlertFiring
+		if stillFiring {
+			st.eventID = eventID
+			st.notified = make(map[string]bool, len(send))
+			for _, out := range send {
+				st.notified[out.NodeID] = true
+			}
+		}
+		s.stateMu.Unlock()
+
+		if !stillFiring {
+			s.recordResolved(eventID, val, now)
+		}
+		if len(send) == 0 {
+			s.recordNotification(eventID, domain.NotifySuppressed, holdMessage(held, silence))
+			return
+		}
+		go s.deliver(eventID, rule, send, val, domain.AlertFiring, samples)
+	}
+	if release {
+		fmt.Printf("[AlertService] Rule %s released: nothing holds its notifications anymore\n", rule.ID)
+		send := s.cooledDown(releaseEvent, rule)
+
+		s.stateMu.Lock()
+		if st.state == domain.AlertFiring && st.eventID == releaseEvent {
+			if st.notified == nil {
+				st.notified = make(map[string]bool, len(send))
+			}
+			for _, out := range send {
+				st.notified[out.NodeID] = true
+			}
+		}
+		s.stateMu.Unlock()
+
+		if len(send) == 0 {
+			s.recordNotification(releaseEvent, domain.NotifySuppressed, "")
+		} else {
+			go s.deliver(releaseEvent, rule, send, val, domain.AlertFiring, samples)
+		}
+	}
+	if resolvedEvent != 0 {
+		s.recordResolved(resolvedEvent, val, now)
+	}
+	if len(resolveTo) > 0 {
+		fmt.Printf("[AlertService] Rule %s resolved (Value: %f)\n", rule.ID, val)
+		go s.deliver(resolvedEvent, rule, resolveTo, val, domain.AlertResolved, samples)
+	}
+}
+
+// cooledDown returns the outputs of rule out of cooldown, recording the others
+// as suppressed deliveries of the event.
+func (s *AlertService) cooledDown(eventID uint, rule domain.AlertRule) []domain.AlertOutput {
+	var send []domain.AlertOutput
+	for _, out := range rule.Outputs {
+		if s.allowSend(rule, out) {
+			send = append(send, out)
+		} else {
+			s.recordDelivery(eventID, rule, out, "", domain.AlertFiring, domain.NotifySuppressed, nil)
+		}
+	}
+	return send
+}
+
+// notifiedOutputs returns the outputs of rule that were told about the firing.
+func notifiedOutputs(rule domain.AlertRule, notified map[string]bool) []domain.AlertOutput {
+	var out []domain.AlertOutput
+	for _, o := range rule.Outputs {
+		if notified[o.NodeID] {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// deliveryTarget is one recipient or endpoint of an output.
+type deliveryTarget struct {
+	name     string
+	notifier domain.Notifier
+}
+
+// deliver notifies every target of the outputs in parallel and records each
+// delivery on its own; for a firing it also stores the overall outcome.
+func (s *AlertService) deliver(eventID uint, rule domain.AlertRule, outputs []domain.AlertOutput, val float64, state domain.AlertState, samples []domain.Sample) {
+	base := s.notification(rule, val, state, samples)
+
+	var (
+		wg                     sync.WaitGroup
+		mu                     sync.Mutex
+		sent, failed, disabled int
+		firstErr               string
+	)
+	for _, out := range outputs {
+		n := base
+		render(rule.ID, out, &n)
+		for _, t := range s.targets(out) {
+			wg.Add(1)
+			go func(out domain.AlertOutput, t deliveryTarget) {
+				defer wg.Done()
+				err := t.notifier.Notify(context.Background(), n)
+				status := deliveryStatus(err)
+				s.recordDelivery(eventID, rule, out, t.name, state, status, err)
+
+				mu.Lock()
+				defer mu.Unlock()
+				switch status {
+				case domain.NotifySent:
+					sent++
+				case domain.NotifyDisabled:
+					disabled++
+				default:
+					failed++
+					if firstErr == "" {
+						firstErr = fmt.Sprintf("%s %s: %v", out.Channel, t.name, err)
+					}
+				}
+			}(out, t)
+		}
+	}
+	wg.Wait()
+
+	if state != domain.AlertFiring {
+		return
+	}
+	status := domain.NotifyDisabled
+	switch {
+	case failed > 0 && sent > 0:
+		status = domain.NotifyPartial
+	case failed > 0:
+		status = domain.NotifyFailed
+	case sent > 0:
+		status = domain.NotifySent
+	}
+	s.recordNotification(eventID, status, firstErr)
+}
+
+func deliveryStatus(err error) string {
+	switch {
+	case errors.Is(err, domain.ErrNotifyDisabled):
+		return domain.NotifyDisabled
+	case err != nil:
+		return domain.NotifyFailed
+	default:
+		return domain.NotifySent
+	}
+}
+
+func (s *AlertService) notification(rule domain.AlertRule, val float64, state domain.AlertState, samples []domain.Sample) domain.Notification {
+	n := domain.Notification{
+		RuleID:     rule.ID,
+		TopologyID: rule.TopologyID,
+		Topology:   rule.TopologyName,
+		Device:     rule.DeviceID,
+		Metric:     rule.Metric,
+		Instance:   rule.Instance,
+		Operator:   rule.Operator,
+		Threshold:  rule.Threshold,
+		Value:      val,
+		State:      state,
+		Samples:    samples,
+		Time:       time.Now(),
+	}
+	if rule.Expr != nil {
+		n.Expression = rule.Expr.String()
+	} else if rule.Metric == MetricHeartbeat {
+		n.Window = rule.Window
+		n.Downstream = rule.Downstream
+	} else if window, ok := aggregationWindow(rule); ok {
+		n.Aggregation, n.Window = rule.Aggregation, rule.Window
+		if _, err := time.ParseDuration(rule.Window); err != nil {
+			n.Window = window.String()
+		}
+	}
+	return n
+}
+
+// render fills the subject and bodies from the output templates. Templates
+// are validated when the topology is saved; should one still fail, the raw
+// text is sent rather than nothing.
+func render(ruleID string, out domain.AlertOutput, n *domain.Notification) {
+	var err error
+	if n.Subject, err = notify.Render("subject", out.Subject, *n); err != nil {
+		fmt.Printf("[AlertService] Rule %s: %v\n", ruleID, err)
+		n.Subject = out.Subject
+	}
+	if n.Message, err = notify.Render("body", out.Body, *n); err != nil {
+		fmt.Printf("[AlertService] Rule %s: %v\n", ruleID, err)
+		n.Message = out.Body
+	}
+	if out.HTML != "" {
+		if n.HTML, err = notify.RenderHTML("html", out.HTML, *n); err != nil {
+			fmt.Printf("[AlertService] Rule %s: %v\n", ruleID, err)
+			n.HTML = ""
+		}
+	}
+}
+
+// targets expands an output into its deliveries: one per email recipient
+// (To, Cc and Bcc alike) or the single endpoint of an HTTP channel.
+func (s *AlertService) targets(out domain.AlertOutput) []deliveryTarget {
+	switch out.Channel {
+	case domain.ChannelWebhook, domain.ChannelSlack, domain.ChannelDiscord, domain.ChannelNtfy:
+		return []deliveryTarget{{name: endpointHost(out.Webhook.URL), notifier: httpNotifier(out)}}
+	}
+	var targets []deliveryTarget
+	for _, list := range [][]string{out.To, out.Cc, out.Bcc} {
+		for _, rcpt := range list {
+			targets = append(targets, deliveryTarget{name: rcpt, notifier: notify.NewEmailNotifier(s.mailQueue, out.To, out.Cc, rcpt)})
+		}
+	}
+	return targets
+}
+
+func httpNotifier(out domain.AlertOutput) domain.Notifier {
+	switch out.Channel {
+	case domain.ChannelSlack:
+		return notify.NewSlackNotifier(out.Webhook)
+	case domain.ChannelDiscord:
+		return notify.NewDiscordNotifier(out.Webhook)
+	case domain.ChannelNtfy:
+		return notify.NewNtfyNotifier(out.Webhook)
+	}
+	return notify.NewWebhookNotifier(out.Webhook)
+}
+
+// endpointHost identifies an endpoint without the path, which for chat
+// webhooks carries the secret token.
+func endpointHost(raw string) string {
+	if u, err := url.Parse(raw); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return raw
+}
+
+func (s *AlertService) recordFiring(rule domain.AlertRule, val float64, now time.Time) uint {
+	if s.events == nil {
+		return 0
+	}
+	e := &domain.AlertEvent{
+		RuleID:       rule.ID,
+		TopologyID:   rule.TopologyID,
+		UserID:       rule.UserID,
+		DeviceID:     rule.DeviceID,
+		Metric:       rule.Metric,
+		Operator:     rule.Operator,
+		Threshold:    rule.Threshold,
+		Value:        val,
+		State:        domain.AlertFiring,
+		FiredAt:      now,
+		NotifyStatus: domain.NotifyPending,
+	}
+	if err := s.events.Create(e); err != nil {
+		fmt.Printf("[AlertService] Error saving alert event: %v\n", err)
+		return 0
+	}
+	return e.ID
+}
+
+func (s *AlertService) recordResolved(eventID uint, val float64, now time.Time) {
+	if s.events == nil || eventID == 0 {
+		return
+	}
+	if err := s.events.Resolve(eventID, now, val); err != nil {
+		fmt.Printf("[AlertService] Error resolving alert event %d: %v\n", eventID, err)
+	}
+}
+
+// recordNotification stores the overall outcome of a firing's deliveries.
+func (s *AlertService) recordNotification(eventID uint, status, msg string) {
+	if s.events == nil || eventID == 0 {
+		return
+	}
+	if err := s.events.SetNotification(eventID, status, msg); err != nil {
+		fmt.Printf("[AlertService] Error updating alert event %d: %v\n", eventID, err)
+	}
+}
+
+func (s *AlertService) recordDelivery(eventID uint, rule domain.AlertRule, out domain.AlertOutput, target string, state domain.AlertState, status string, err error) {
+	if s.events == nil || eventID == 0 {
+		return
+	}
+	d := &domain.AlertDelivery{
+		EventID:    eventID,
+		TopologyID: rule.TopologyID,
+		RuleID:     rule.ID,
+		NodeID:     out.NodeID,
+		Channel:    out.Channel,
+		Target:     target,
+		State:      state,
+		Status:     status,
+	}
+	if err != nil && status == domain.NotifyFailed {
+		d.Error = err.Error()
+	}
+	if err := s.events.AddDelivery(d); err != nil {
+		fmt.Printf("[AlertService] Error saving delivery for alert event %d: %v\n", eventID, err)
+	}
+}
+
+// restore reloads cooldowns and open alerts after a restart.
+func (s *AlertService) restore() {
+	if s.events == nil {
+		return
+	}
+
+	if last, err := s.events.LastNotified(); err != nil {
+		fmt.Printf("[AlertService] Error restoring cooldowns: %v\n", err)
+	} else {
+		s.lastSentMu.Lock()
+		for id, t := range last {
+			s.lastSent[id] = t
+		}
+		s.lastSentMu.Unlock()
+	}
+
+	open, err := s.events.Open()
+	if err != nil {
+		fmt.Printf("[AlertService] Error restoring open alerts: %v\n", err)
+		return
+	}
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	for _, e := range open {
+		rule := domain.AlertRule{
+			ID:         e.RuleID,
+			TopologyID: e.TopologyID,
+			UserID:     e.UserID,
+			DeviceID:   e.DeviceID,
+			Metric:     e.Metric,
+			Operator:   e.Operator,
+			Threshold:  e.Threshold,
+		}
+		s.states[stateKey(rule)] = &ruleState{
+			rule:     rule,
+			state:    domain.AlertFiring,
+			since:    e.FiredAt,
+			value:    e.Value,
+			notified: sentOutputs(e.Deliveries),
+			eventID:  e.ID,
+		}
+	}
+	fmt.Printf("[AlertService] Restored %d open alerts\n", len(open))
+}
+
+func sentOutputs(deliveries []domain.AlertDelivery) map[string]bool {
+	sent := make(map[string]bool)
+	for _, d := range deliveries {
+		if d.State == domain.AlertFiring && d.Status == domain.NotifySent {
+			sent[d.NodeID] = true
+		}
+	}
+	return sent
+}
+
+// History lists persisted alert events.
+func (s *AlertService) History(filter domain.AlertEventFilter) ([]domain.AlertEvent, int64, error) {
+	if s.events == nil {
+		return nil, 0, errors.New("alert history not configured")
+	}
+	return s.events.List(filter)
+}
+
+// Acknowledge marks an alert event as seen by an operator.
+func (s *AlertService) Acknowledge(id uint, scope domain.MetricScope, comment string) error {
+	if s.events == nil {
+		return errors.New("alert history not configured")
+	}
+	return s.events.Ack(id, scope, scope.UserID, comment, time.Now())
+}
+
+// DeadLetters lists the mail the queue gave up on.
+func (s *AlertService) DeadLetters(limit, offset int) ([]domain.MailDeadLetter, int64, error) {
+	if s.deadMail == nil {
+		return nil, 0, errors.New("dead-letter store not configured")
+	}
+	return s.deadMail.List(limit, offset)
+}
+
+// pruneStates drops the state of rules that no longer exist in a topology.
+func (s *AlertService) pruneStates(topologyID uint, rules []domain.AlertRule) {
+	keep := make(map[string]bool, len(rules))
+	for _, r := range rules {
+		keep[stateKey(r)] = true
+	}
+
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	for key, st := range s.states {
+		if st.rule.TopologyID == topologyID && !keep[key] {
+			delete(s.states, key)
+		}
+	}
+}
+
+// GetActiveAlerts returns the rules currently pending or firing.
+func (s *AlertService) GetActiveAlerts() []domain.ActiveAlert {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	out := make([]domain.ActiveAlert, 0)
+	for _, st := range s.states {
+		if st.state != domain.AlertPending && st.state != domain.AlertFiring {
+			continue
+		}
+		out = append(out, domain.ActiveAlert{
+			RuleID:     st.rule.ID,
+			TopologyID: st.rule.TopologyID,
+			UserID:     st.rule.UserID,
+			DeviceID:   st.rule.DeviceID,
+			Metric:     st.rule.Metric,
+			Instance:   st.rule.Instance,
+			Operator:   st.rule.Operator,
+			Threshold:  st.rule.Threshold,
+			State:      st.state,
+			Value:      st.value,
+			Since:      st.since,
+			HeldBy:     st.heldBy,
+			SilencedBy: st.silenced,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Since.Before(out[j].Since) })
+	return out
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+)
+
+// newTestAlertService builds an AlertService with no repositories, mailer or
+// outputs, so transitions only touch the in-memory state.
+func newTestAlertService() *AlertService {
+	return &AlertService{
+		rules:      make(map[uint][]domain.AlertRule),
+		lastSent:   make(map[string]time.Time),
+		lastSeen:   make(map[deviceKey]time.Time),
+		states:     make(map[string]*ruleState),
+		buffers:    make(map[bufferKey]*sampleBuffer),
+		windows:    make(map[bufferKey]time.Duration),
+		addrs:      make(map[deviceKey]deviceAddr),
+		conds:      make(map[string]condResult),
+		dependents: make(map[string][]domain.AlertRule),
+	}
+}
+
+// step is one observation fed to a rule, at an offset from the start.
+type step struct {
+	at       time.Duration
+	breached bool
+	want     domain.AlertState
+}
+
+func (s *AlertService) stateOf(rule domain.AlertRule) (domain.AlertState, *ruleState) {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	st, ok := s.states[stateKey(rule)]
+	if !ok {
+		return domain.AlertInactive, nil
+	}
+	return st.state, st
+}
+
+func TestTransition(t *testing.T) {
+	tests := []struct {
+		name  string
+		for_  string
+		steps []step
+	}{
+		{"fires at once without for", "", []step{
+			{0, true, domain.AlertFiring},
+			{time.Minute, true, domain.AlertFiring},
+			{2 * time.Minute, false, domain.AlertResolved},
+		}},
+		{"pending until for elapses", "1m", []step{
+			{0, true, domain.AlertPending},
+			{30 * time.Second, true, domain.AlertPending},
+			{time.Minute, true, domain.AlertFiring},
+			{90 * time.Second, true, domain.AlertFiring},
+		}},
+		{"clearing while pending goes back to inactive", "1m", []step{
+			{0, true, domain.AlertPending},
+			{30 * time.Second, false, domain.AlertInactive},
+			{45 * time.Second, true, domain.AlertPending},
+			{90 * time.Second, true, domain.AlertPending},
+			{105 * time.Second, true, domain.AlertFiring},
+		}},
+		{"resolved fires again after for", "1m", []step{
+			{0, true, domain.AlertPending},
+			{time.Minute, true, domain.AlertFiring},
+			{2 * time.Minute, false, domain.AlertResolved},
+			{3 * time.Minute, false, domain.AlertResolved},
+			{4 * time.Minute, true, domain.AlertPending},
+			{5 * time.Minute, true, domain.AlertFiring},
+		}},
+		{"never breached stays inactive", "1m", []step{
+			{0, false, domain.AlertInactive},
+			{time.Minute, false, domain.AlertInactive},
+		}},
+		{"invalid for counts as zero", "soon", []step{
+			{0, true, domain.AlertFiring},
+		}},
+		{"negative for counts as zero", "-1m", []step{
+			{0, true, domain.AlertFiring},
+		}},
+	}
+
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestAlertService()
+			rule := domain.AlertRule{ID: "r1", TopologyID: 1, DeviceID: "web-1", Metric: "cpu", Operator: ">", Threshold: 90, For: tt.for_}
+
+			for i, st := range tt.steps {
+				val := 10.0
+				if st.breached {
+					val = 95
+				}
+				s.transition(rule, st.breached, val, start.Add(st.at))
+				if got, _ := s.stateOf(rule); got != st.want {
+					t.Fatalf("step %d (%v, breached=%v): state = %s, want %s", i, st.at, st.breached, got, st.want)
+				}
+			}
+		})
+	}
+}
+
+func TestTransitionSilence(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	ends := start.Add(10 * time.Minute)
+
+	tests := []struct {
+		name         string
+		silence      domain.Silence
+		at           time.Duration // second observation, still breached
+		wantSilenced bool
+	}{
+		{"silence still active", domain.Silence{ID: 7, UserID: 1, StartsAt: start, EndsAt: &ends}, 5 * time.Minute, true},
+		{"silence over releases the alert", domain.Silence{ID: 7, UserID: 1, StartsAt: start, EndsAt: &ends}, 15 * time.Minute, false},
+		{"silence for another user", domain.Silence{ID: 7, UserID: 2, StartsAt: start, EndsAt: &ends}, 5 * time.Minute, false},
+		{"silence for another device", domain.Silence{ID: 7, UserID: 1, Device: "db-1", StartsAt: start}, 5 * time.Minute, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestAlertService()
+			s.silences = []domain.Silence{tt.silence}
+			rule := domain.AlertRule{ID: "r1", TopologyID: 1, UserID: 1, DeviceID: "web-1", Metric: "cpu", Operator: ">", Threshold: 90}
+
+			s.transition(rule, true, 95, start)
+			s.transition(rule, true, 95, start.Add(tt.at))
+
+			state, st := s.stateOf(rule)
+			if state != domain.AlertFiring {
+				t.Fatalf("state = %s, want firing", state)
+			}
+			if got := st.silenced != 0; got != tt.wantSilenced {
+				t.Fatalf("silenced = %d, want silenced %v", st.silenced, tt.wantSilenced)
+			}
+
+			// resolving clears the hold so the next firing starts fresh
+			s.transition(rule, false, 10, start.Add(tt.at+time.Minute))
+			if state, st := s.stateOf(rule); state != domain.AlertResolved || st.silenced != 0 {
+				t.Fatalf("after clear: state = %s, silenced = %d", state, st.silenced)
+			}
+		})
+	}
+}
+
+func TestSilencedByComposite(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	composite := domain.AlertRule{ID: "both", TopologyID: 1, UserID: 1, Metric: MetricComposite,
+		Expr: op(domain.ExprAnd, leaf(condA), leaf(condC))}
+
+	tests := []struct {
+		name    string
+		silence domain.Silence
+		want    bool
+	}{
+		{"device of one condition", domain.Silence{UserID: 1, Device: "db-1"}, true},
+		{"device and metric of one condition", domain.Silence{UserID: 1, Device: "web-1", Metric: "cpu"}, true},
+		{"metric of another device", domain.Silence{UserID: 1, Device: "web-1", Metric: "disk"}, false},
+		{"device not in the expression", domain.Silence{UserID: 1, Device: "mail-1"}, false},
+		{"the composite rule itself", domain.Silence{UserID: 1, RuleID: "both"}, true},
+		{"another user's device", domain.Silence{UserID: 2, Device: "db-1"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestAlertService()
+			tt.silence.ID, tt.silence.StartsAt = 7, now.Add(-time.Minute)
+			s.silences = []domain.Silence{tt.silence}
+
+			if got := s.silencedBy(composite, now) != nil; got != tt.want {
+				t.Fatalf("silenced = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPruneStates(t *testing.T) {
+	s := newTestAlertService()
+	now := time.Now()
+	keep := domain.AlertRule{ID: "keep", TopologyID: 1}
+	gone := domain.AlertRule{ID: "gone", TopologyID: 1}
+	other := domain.AlertRule{ID: "gone", TopologyID: 2}
+	for _, r := range []domain.AlertRule{keep, gone, other} {
+		s.transition(r, true, 1, now)
+	}
+
+	s.pruneStates(1, []domain.AlertRule{keep})
+
+	for _, tt := range []struct {
+		rule domain.AlertRule
+		want bool
+	}{{keep, true}, {gone, false}, {other, true}} {
+		if _, st := s.stateOf(tt.rule); (st != nil) != tt.want {
+			t.Errorf("state of %s kept = %v, want %v", stateKey(tt.rule), st != nil, tt.want)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+)
+
+const (
+	defaultAggregationWindow = 5 * time.Minute
+	// maxBufferedSamples caps each rolling buffer, whatever the window.
+	maxBufferedSamples = 4096
+)
+
+// bufferKey identifies the series a windowed rule reads.
+type bufferKey struct {
+	device   deviceKey
+	metric   string
+	instance string
+}
+
+func ruleBufferKey(rule domain.AlertRule) bufferKey {
+	return bufferKey{device: ruleDevice(rule), metric: rule.Metric, instance: rule.Instance}
+}
+
+// sampleBuffer holds the recent values of one device metric, oldest first.
+type sampleBuffer struct {
+	samples []domain.Sample
+}
+
+// add inserts a sample in time order (replayed metrics arrive late) and drops
+// those older than keep.
+func (b *sampleBuffer) add(s domain.Sample, keep time.Duration, now time.Time) {
+	i := sort.Search(len(b.samples), func(i int) bool { return b.samples[i].Time.After(s.Time) })
+	b.samples = append(b.samples, domain.Sample{})
+	copy(b.samples[i+1:], b.samples[i:])
+	b.samples[i] = s
+
+	cut := 0
+	for cut < len(b.samples) && now.Sub(b.samples[cut].Time) > keep {
+		cut++
+	}
+	if len(b.samples)-cut > maxBufferedSamples {
+		cut = len(b.samples) - maxBufferedSamples
+	}
+	if cut > 0 {
+		b.samples = append(b.samples[:0], b.samples[cut:]...)
+	}
+}
+
+// aggregate applies agg to the samples within window before now.
+func (b *sampleBuffer) aggregate(agg string, window time.Duration, now time.Time) (float64, bool) {
+	var (
+		n             int
+		sum, min, max float64
+		last          float64
+	)
+	for _, s := range b.samples {
+		if now.Sub(s.Time) > window {
+			continue
+		}
+		if n == 0 || s.Value < min {
+			min = s.Value
+		}
+		if n == 0 || s.Value > max {
+			max = s.Value
+		}
+		sum += s.Value
+		last = s.Value
+		n++
+	}
+	if n == 0 {
+		return 0, false
+	}
+
+	switch agg {
+	case domain.AggAvg:
+		return sum / float64(n), true
+	case domain.AggMin:
+		return min, true
+	case domain.AggMax:
+		return max, true
+	default:
+		return last, true
+	}
+}
+
+// aggregationWindow reports whether the rule aggregates over a window, and its span.
+func aggregationWindow(rule domain.AlertRule) (time.Duration, bool) {
+	if rule.Metric == MetricHeartbeat {
+		return 0, false
+	}
+	switch rule.Aggregation {
+	case domain.AggAvg, domain.AggMin, domain.AggMax:
+	default:
+		return 0, false
+	}
+	window, err := time.ParseDuration(rule.Window)
+	if err != nil || window <= 0 {
+		window = defaultAggregationWindow
+	}
+	return window, true
+}
+
+// windowsFor returns, per series, the longest window any rule needs. Must be
+// called with s.mu held.
+func (s *AlertService) windowsFor() map[bufferKey]time.Duration {
+	windows := make(map[bufferKey]time.Duration)
+	for _, rules := range s.rules {
+		for _, rule := range rules {
+			window, ok := aggregationWindow(rule)
+			if !ok {
+				continue
+			}
+			key := ruleBufferKey(rule)
+			if window > windows[key] {
+				windows[key] = window
+			}
+		}
+	}
+	return windows
+}
+
+// sampleTime is when the agent took m: metrics replayed from the spool or a
+// batch carry their own time. A missing or future timestamp counts as now.
+func sampleTime(m domain.Metric, now time.Time) time.Time {
+	if m.Timestamp.IsZero() || m.Timestamp.After(now) {
+		return now
+	}
+	return m.Timestamp
+}
+
+// bufferSample records, at its own time, the metric values windowed rules on
+// this device need; samples already outside a window are dropped. Must be
+// called with s.mu held (read).
+func (s *AlertService) bufferSample(m domain.Metric, at, now time.Time) {
+	s.bufMu.Lock()
+	defer s.bufMu.Unlock()
+
+	device := metricDevice(m)
+	for key, keep := range s.windows {
+		if key.device != device || now.Sub(at) > keep {
+			continue
+		}
+		val := getMetricValue(m, key.metric, key.instance)
+		if val == -1 {
+			continue
+		}
+		buf, ok := s.buffers[key]
+		if !ok {
+			buf = &sampleBuffer{}
+			s.buffers[key] = buf
+		}
+		buf.add(domain.Sample{Time: at, Value: val}, keep, now)
+	}
+}
+
+// windowValue aggregates the buffered series of a windowed rule.
+func (s *AlertService) windowValue(rule domain.AlertRule, window time.Duration, now time.Time) (float64, bool) {
+	s.bufMu.Lock()
+	defer s.bufMu.Unlock()
+
+	buf, ok := s.buffers[ruleBufferKey(rule)]
+	if !ok {
+		return 0, false
+	}
+	return buf.aggregate(rule.Aggregation, window, now)
+}
+
+// resizeBuffers applies the windows of the current rules and frees the series
+// no rule reads anymore. Must be called with s.mu held.
+func (s *AlertService) resizeBuffers() {
+	windows := s.windowsFor()
+
+	s.bufMu.Lock()
+	defer s.bufMu.Unlock()
+
+	s.windows = windows
+	for key := range s.buffers {
+		if _, ok := windows[key]; !ok {
+			delete(s.buffers, key)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+)
+
+func TestWindowValue(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	// at builds a sample taken ago before now; ago == -1 leaves the timestamp unset
+	at := func(owner uint, ago time.Duration, cpu float64) domain.Metric {
+		m := owned(owner, "web-1", cpu)
+		if ago != -1 {
+			m.Timestamp = now.Add(-ago)
+		}
+		return m
+	}
+
+	tests := []struct {
+		name    string
+		agg     string
+		metrics []domain.Metric // in arrival order
+		want    float64
+		wantOK  bool
+	}{
+		{"live samples", domain.AggAvg, []domain.Metric{at(1, 2*time.Minute, 80), at(1, time.Minute, 100)}, 90, true},
+		{"replayed sample older than the window", domain.AggAvg, []domain.Metric{at(1, 10*time.Minute, 100), at(1, 0, 80)}, 80, true},
+		{"only stale samples", domain.AggMax, []domain.Metric{at(1, 6*time.Minute, 100)}, 0, false},
+		{"late sample keeps time order", domain.AggMax, []domain.Metric{at(1, 0, 50), at(1, 4*time.Minute, 100)}, 100, true},
+		{"missing timestamp counts as now", domain.AggMin, []domain.Metric{at(1, -1, 70), at(1, time.Minute, 90)}, 70, true},
+		{"future timestamp counts as now", domain.AggAvg, []domain.Metric{at(1, -time.Hour, 60)}, 60, true},
+		{"same device name, another owner", domain.AggAvg, []domain.Metric{at(2, time.Minute, 100), at(1, time.Minute, 40)}, 40, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestAlertService()
+			rule := domain.AlertRule{ID: "cpu", TopologyID: 1, UserID: 1, DeviceID: "web-1", Metric: "cpu", Aggregation: tt.agg, Window: "5m"}
+			s.rules[1] = []domain.AlertRule{rule}
+			s.resizeBuffers()
+
+			for _, m := range tt.metrics {
+				s.bufferSample(m, sampleTime(m, now), now)
+			}
+
+			got, ok := s.windowValue(rule, 5*time.Minute, now)
+			if got != tt.want || ok != tt.wantOK {
+				t.Fatalf("windowValue = (%v, %v), want (%v, %v)", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -3,20 +3,46 @@ package service
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
+	"fmt"
+	"path"
+	"sync"
 	"time"
 
 	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
 )
 
+var (
+	ErrInvalidTokenKind     = errors.New("invalid token kind")
+	ErrInvalidDevicePattern = errors.New("invalid device pattern")
+	ErrDeviceNotAllowed     = errors.New("device_name not allowed for this token")
+)
+
 type TokenService struct {
 	repo domain.APITokenRepository
+
+	// nombres distintos al del token ya informados, para no repetir el aviso
+	mismatchMu     sync.Mutex
+	mismatchLogged map[uint]string
 }
 
 func NewTokenService(repo domain.APITokenRepository) *TokenService {
-	return &TokenService{repo: repo}
+	return &TokenService{repo: repo, mismatchLogged: map[uint]string{}}
 }
 
-func (s *TokenService) GenerateForUser(name string, deviceName string, userID uint) (string, error) {
+func (s *TokenService) GenerateForUser(name string, deviceName string, kind string, userID uint) (string, error) {
+	switch kind {
+	case "":
+		kind = domain.TokenKindDevice
+	case domain.TokenKindDevice:
+	case domain.TokenKindFleet:
+		if _, err := path.Match(deviceName, ""); err != nil {
+			return "", ErrInvalidDevicePattern
+		}
+	default:
+		return "", ErrInvalidTokenKind
+	}
+
 	raw := "ntk_" + time.Now().Format("20060102150405.000000000")
 	hash := hashToken(raw)
 
@@ -25,6 +51,7 @@ func (s *TokenService) GenerateForUser(name string, deviceName string, userID ui
 		TokenHash:  hash,
 		UserID:     &userID,
 		DeviceName: deviceName,
+		Kind:       kind,
 		CreatedAt:  time.Now(),
 	}
 
@@ -39,6 +66,38 @@ func (s *TokenService) Validate(raw string) (*domain.APIToken, error) {
 	return s.repo.FindByHash(hash)
 }
 
+// ResolveDevice devuelve el nombre de dispositivo con el que se guardan las
+// métricas enviadas con tok. Un token de dispositivo siempre usa su propio
+// nombre (los agentes envían el hostname, que puede no coincidir con la
+// etiqueta del token); uno de flota exige un nombre que calce con su patrón.
+func (s *TokenService) ResolveDevice(tok *domain.APIToken, claimed string) (string, error) {
+	if tok.Kind == domain.TokenKindFleet {
+		if claimed == "" {
+			return "", ErrDeviceNotAllowed
+		}
+		ok, err := path.Match(tok.DeviceName, claimed)
+		if err != nil || !ok {
+			return "", ErrDeviceNotAllowed
+		}
+		return claimed, nil
+	}
+
+	if claimed != "" && claimed != tok.DeviceName {
+		s.logMismatch(tok, claimed)
+	}
+	return tok.DeviceName, nil
+}
+
+func (s *TokenService) logMismatch(tok *domain.APIToken, claimed string) {
+	s.mismatchMu.Lock()
+	defer s.mismatchMu.Unlock()
+	if s.mismatchLogged[tok.ID] == claimed {
+		return
+	}
+	s.mismatchLogged[tok.ID] = claimed
+	fmt.Printf("[tokens] token %d envía device_name %q, se guarda como %q\n", tok.ID, claimed, tok.DeviceName)
+}
+
 func (s *TokenService) ListByUser(userID uint) ([]domain.APIToken, error) {
 	return s.repo.FindByUser(userID)
 }
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+)
+
+const (
+	// intervalo asumido mientras no haya dos reportes para estimarlo
+	defaultDeviceInterval = 60 * time.Second
+	// un dispositivo pasa a offline tras offlineFactor intervalos sin reportar
+	offlineFactor   = 3
+	minOfflineAfter = 30 * time.Second
+	// peso de cada nueva muestra en el promedio móvil del intervalo
+	intervalSmoothing = 0.2
+	// huecos mayores a maxGapFactor intervalos se tratan como caída, no como cambio de ritmo
+	maxGapFactor = 10
+)
+
+type DeviceService struct {
+	repo domain.DeviceRepository
+	mu   sync.Mutex
+}
+
+func NewDeviceService(repo domain.DeviceRepository) *DeviceService {
+	return &DeviceService{repo: repo}
+}
+
+type deviceKey struct {
+	owner uint
+	name  string
+}
+
+// metricDevice devuelve la clave del dispositivo que envió m.
+func metricDevice(m domain.Metric) deviceKey {
+	k := deviceKey{name: m.DeviceName}
+	if m.OwnerID != nil {
+		k.owner = *m.OwnerID
+	}
+	return k
+}
+
+// Record registra o actualiza los dispositivos que aparecen en un lote de métricas.
+func (s *DeviceService) Record(ms []domain.Metric) error {
+	groups := make(map[deviceKey][]domain.Metric)
+	var order []deviceKey
+	for _, m := range ms {
+		k := metricDevice(m)
+		if _, ok := groups[k]; !ok {
+			order = append(order, k)
+		}
+		groups[k] = append(groups[k], m)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	for _, k := range order {
+		if err := s.record(k, groups[k]); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", k.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *DeviceService) record(k deviceKey, ms []domain.Metric) error {
+	d, err := s.repo.Find(k.owner, k.name)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		d = &domain.Device{OwnerID: k.owner, Name: k.name}
+	}
+
+	sort.SliceStable(ms, func(i, j int) bool { return ms[i].Timestamp.Before(ms[j].Timestamp) })
+
+	now := time.Now().UTC()
+	for _, m := range ms {
+		ts := m.Timestamp
+		if ts.IsZero() || ts.After(now) {
+			ts = now
+		}
+		if d.FirstSeen.IsZero() || ts.Before(d.FirstSeen) {
+			d.FirstSeen = ts
+		}
+		if !d.LastSeen.IsZero() && ts.After(d.LastSeen) {
+			d.IntervalSec = nextInterval(d.IntervalSec, ts.Sub(d.LastSeen).Seconds())
+		}
+		if ts.After(d.LastSeen) {
+			d.LastSeen = ts
+		}
+
+		if m.IpAddress != "" {
+			d.IpAddress = m.IpAddress
+		}
+		if m.Gateway != "" {
+			d.Gateway = m.Gateway
+		}
+		if m.OS != "" {
+			d.OS = m.OS
+		}
+		if m.DeviceType != "" {
+			d.DeviceType = m.DeviceType
+		}
+		if m.AgentVersion != "" {
+			d.AgentVersion = m.AgentVersion
+		}
+	}
+
+	return s.repo.Save(d)
+}
+
+// nextInterval actualiza el promedio móvil del intervalo de reporte.
+func nextInterval(current, delta float64) float64 {
+	if current <= 0 {
+		return delta
+	}
+	if delta > current*maxGapFactor {
+		return current
+	}
+	return current + intervalSmoothing*(delta-current)
+}
+
+// DeviceStatus calcula si el dispositivo está online según su último reporte
+// y su intervalo esperado.
+func DeviceStatus(d domain.Device, now time.Time) string {
+	interval := defaultDeviceInterval
+	if d.IntervalSec > 0 {
+		interval = time.Duration(d.IntervalSec * float64(time.Second))
+	}
+	after := interval * offlineFactor
+	if after < minOfflineAfter {
+		after = minOfflineAfter
+	}
+	if now.Sub(d.LastSeen) > after {
+		return domain.DeviceOffline
+	}
+	return domain.DeviceOnline
+}
+
+func (s *DeviceService) List(scope domain.MetricScope) ([]domain.Device, error) {
+	devs, err := s.repo.List(scope)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for i := range devs {
+		devs[i].Status = DeviceStatus(devs[i], now)
+	}
+	return devs, nil
+}
+
+// Names devuelve los nombres de dispositivo visibles, sin repetir.
+func (s *DeviceService) Names(scope domain.MetricScope) ([]string, error) {
+	devs, err := s.repo.List(scope)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(devs))
+	names := make([]string, 0, len(devs))
+	for _, d := range devs {
+		if !seen[d.Name] {
+			seen[d.Name] = true
+			names = append(names, d.Name)
+		}
+	}
+	return names, nil
+}
+
+func (s *DeviceService) Get(scope domain.MetricScope, name string) (*domain.Device, error) {
+	d, err := s.repo.FindByName(scope, name)
+	if err != nil {
+		return nil, err
+	}
+	d.Status = DeviceStatus(*d, time.Now())
+	return d, nil
+}
+
+// Delete quita el dispositivo del registro; sus métricas quedan sujetas a la
+// retención. Como varios usuarios pueden tener un equipo con el mismo nombre,
+// un admin puede indicar el dueño; si no, se borra el mismo que devuelve Get.
+func (s *DeviceService) Delete(scope domain.MetricScope, ownerID uint, name string) error {
+	var d *domain.Device
+	var err error
+	if scope.All && ownerID != 0 {
+		if d, err = s.repo.Find(ownerID, name); err == nil && d == nil {
+			err = domain.ErrDeviceNotFound
+		}
+	} else {
+		d, err = s.repo.FindByName(scope, name)
+	}
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(d.ID)
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+)
+
+// memDevices is an in-memory DeviceRepository.
+type memDevices struct {
+	domain.DeviceRepository
+	devices []domain.Device
+}
+
+func (r *memDevices) Find(ownerID uint, name string) (*domain.Device, error) {
+	for _, d := range r.devices {
+		if d.OwnerID == ownerID && d.Name == name {
+			return &d, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memDevices) FindByName(scope domain.MetricScope, name string) (*domain.Device, error) {
+	var found *domain.Device
+	for i, d := range r.devices {
+		if d.Name != name || (!scope.All && d.OwnerID != scope.UserID) {
+			continue
+		}
+		if found == nil || d.LastSeen.After(found.LastSeen) {
+			found = &r.devices[i]
+		}
+	}
+	if found == nil {
+		return nil, domain.ErrDeviceNotFound
+	}
+	d := *found
+	return &d, nil
+}
+
+func (r *memDevices) Delete(id uint) error {
+	for i, d := range r.devices {
+		if d.ID == id {
+			r.devices = append(r.devices[:i], r.devices[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrDeviceNotFound
+}
+
+func TestDeviceDelete(t *testing.T) {
+	now := time.Now()
+	admin := domain.MetricScope{UserID: 9, All: true}
+
+	tests := []struct {
+		name    string
+		scope   domain.MetricScope
+		owner   uint
+		device  string
+		wantErr error
+		want    []uint // IDs left
+	}{
+		{"owner deletes their own", domain.MetricScope{UserID: 1}, 0, "web-1", nil, []uint{2, 3}},
+		{"owner can't pick another owner", domain.MetricScope{UserID: 1}, 2, "web-1", nil, []uint{2, 3}},
+		{"owner without that device", domain.MetricScope{UserID: 3}, 0, "web-1", domain.ErrDeviceNotFound, []uint{1, 2, 3}},
+		{"admin picks the owner", admin, 1, "web-1", nil, []uint{2, 3}},
+		{"admin without owner deletes the one Get shows", admin, 0, "web-1", nil, []uint{1, 3}},
+		{"admin with an owner lacking it", admin, 3, "web-1", domain.ErrDeviceNotFound, []uint{1, 2, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memDevices{devices: []domain.Device{
+				{ID: 1, OwnerID: 1, Name: "web-1", LastSeen: now.Add(-time.Hour)},
+				{ID: 2, OwnerID: 2, Name: "web-1", LastSeen: now},
+				{ID: 3, OwnerID: 1, Name: "db-1", LastSeen: now},
+			}}
+			svc := NewDeviceService(repo)
+
+			if err := svc.Delete(tt.scope, tt.owner, tt.device); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			var left []uint
+			for _, d := range repo.devices {
+				left = append(left, d.ID)
+			}
+			if len(left) != len(tt.want) {
+				t.Fatalf("devices left = %v, want %v", left, tt.want)
+			}
+			for i := range left {
+				if left[i] != tt.want[i] {
+					t.Fatalf("devices left = %v, want %v", left, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+)
+
+// counterSample es la última lectura de contadores de un dispositivo.
+type counterSample struct {
+	at     time.Time
+	rx, tx uint64
+	uptime uint64
+}
+
+// rateTracker deriva tasas de los contadores acumulados que envía el agente
+// (net_rx/net_tx) comparando cada métrica con la anterior del mismo dispositivo.
+// Los nombres son únicos por dueño, así que la clave incluye al dueño.
+type rateTracker struct {
+	mu   sync.Mutex
+	last map[deviceKey]counterSample
+}
+
+func newRateTracker() *rateTracker {
+	return &rateTracker{last: make(map[deviceKey]counterSample)}
+}
+
+// deriveBatch completa las métricas derivadas de un lote, en orden cronológico.
+func (t *rateTracker) deriveBatch(ms []domain.Metric) {
+	sort.SliceStable(ms, func(i, j int) bool {
+		return ms[i].Timestamp.Before(ms[j].Timestamp)
+	})
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i := range ms {
+		t.derive(&ms[i])
+	}
+}
+
+// derive calcula NetRxRate, NetTxRate y Rebooted. Si el contador bajó (reinicio
+// del equipo o de la interfaz, o desborde) no se reporta tasa para esa muestra:
+// la diferencia no significa nada y pasa a ser la nueva base.
+func (t *rateTracker) derive(m *domain.Metric) {
+	at := m.Timestamp
+	if at.IsZero() {
+		at = time.Now()
+	}
+	cur := counterSample{at: at, rx: m.NetRxBytes, tx: m.NetTxBytes, uptime: m.UptimeSec}
+
+	key := metricDevice(*m)
+	prev, ok := t.last[key]
+	if ok && !at.After(prev.at) {
+		// muestra atrasada o repetida: no sirve para calcular tasas
+		return
+	}
+	t.last[key] = cur
+	if !ok {
+		return
+	}
+
+	if cur.uptime != 0 && prev.uptime != 0 && cur.uptime < prev.uptime {
+		m.Rebooted = true
+		return
+	}
+
+	secs := at.Sub(prev.at).Seconds()
+	m.NetRxRate = counterRate(prev.rx, cur.rx, secs)
+	m.NetTxRate = counterRate(prev.tx, cur.tx, secs)
+}
+
+func counterRate(prev, cur uint64, secs float64) *float64 {
+	if cur < prev || prev == 0 || secs <= 0 {
+		return nil
+	}
+	rate := float64(cur-prev) / secs
+	return &rate
+}
@@ -2,280 +2,129 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"time"
-
-	"github.com/influxdata/influxdb-client-go/v2/api"
 
 	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
-	"github.com/BenjaminAGH/nocturnescope/backend/internal/infrastructure/timeseries"
 )
 
 type MetricService struct {
-	writer       *timeseries.InfluxWriter
+	store        domain.MetricStore
+	devices      *DeviceService
+	processes    *ProcessService
 	alertService domain.AlertService
+	rates        *rateTracker
 }
 
-func NewMetricService(writer *timeseries.InfluxWriter, alertService domain.AlertService) *MetricService {
+func NewMetricService(store domain.MetricStore, devices *DeviceService, processes *ProcessService, alertService domain.AlertService) *MetricService {
 	return &MetricService{
-		writer:       writer,
+		store:        store,
+		devices:      devices,
+		processes:    processes,
 		alertService: alertService,
+		rates:        newRateTracker(),
 	}
 }
 
 func (s *MetricService) Store(m domain.Metric) error {
-	if s.alertService != nil {
-		go s.alertService.Evaluate(m)
-	}
-
-	if s.writer == nil {
-		fmt.Println("[metrics] Influx no configurado, métrica descartada")
-		return nil
-	}
-	return s.writer.WriteMetric(m)
+	return s.StoreBatch([]domain.Metric{m})
 }
 
-func (s *MetricService) ListDevices(ctx context.Context) ([]string, error) {
-	q, err := s.queryAPI()
-	if err != nil {
-		return nil, err
-	}
-
-	flux := fmt.Sprintf(`
-from(bucket: "%s")
-  |> range(start: -30d)
-  |> filter(fn: (r) => r._measurement == "system_metrics")
-  |> keep(columns: ["device"])
-  |> distinct(column: "device")
-  |> sort(columns: ["device"])
-`, s.writer.Bucket())
+// StoreBatch guarda un lote de métricas con una sola escritura al almacenamiento.
+func (s *MetricService) StoreBatch(ms []domain.Metric) error {
+	s.rates.deriveBatch(ms)
 
-	res, err := q.Query(ctx, flux)
-	if err != nil {
-		return nil, err
+	if s.alertService != nil {
+		go func() {
+			for _, m := range ms {
+				s.alertService.Evaluate(m)
+			}
+		}()
 	}
-	defer res.Close()
 
-	var devices []string
-	for res.Next() {
-		if v, ok := res.Record().ValueByKey("device").(string); ok && v != "" {
-			devices = append(devices, v)
+	if s.devices != nil {
+		if err := s.devices.Record(ms); err != nil {
+			fmt.Printf("[metrics] error registrando dispositivos: %v\n", err)
 		}
 	}
-	return devices, res.Err()
-}
-
-// LastStats retorna el último valor conocido de cada métrica para un dispositivo.
-func (s *MetricService) LastStats(ctx context.Context, device string) (map[string]interface{}, error) {
-	q, err := s.queryAPI()
-	if err != nil {
-		return nil, err
-	}
 
-	fields := []string{"cpu", "ram", "disk", "net_rx", "net_tx", "temp", "uptime"}
-
-	flux := fmt.Sprintf(`
-from(bucket: "%[1]s")
-  |> range(start: -24h)
-  |> filter(fn: (r) => r._measurement == "system_metrics" and r.device == "%[2]s")
-  |> filter(fn: (r) => contains(value: r._field, set: %[3]s))
-  |> last()
-  |> pivot(rowKey:["_time"], columnKey:["_field"], valueColumn:"_value")
-  |> group()
-  |> keep(columns: ["_time","cpu","ram","disk","net_rx","net_tx","temp","uptime","os","ip","gateway"])
-`, s.writer.Bucket(), device, fieldsToFluxArray(fields))
-
-	res, err := q.Query(ctx, flux)
-	if err != nil {
-		return nil, err
-	}
-	defer res.Close()
-
-	out := map[string]interface{}{}
-	for res.Next() {
-		rec := res.Record()
-
-		// Tags (strings)
-		if v, ok := rec.ValueByKey("os").(string); ok {
-			out["os"] = v
-		}
-		if v, ok := rec.ValueByKey("ip").(string); ok {
-			out["ip"] = v
-		}
-		if v, ok := rec.ValueByKey("gateway").(string); ok {
-			out["gateway"] = v
+	if s.processes != nil {
+		if err := s.processes.Record(ms); err != nil {
+			fmt.Printf("[metrics] error guardando procesos: %v\n", err)
 		}
-
-		// Fields (floats)
-		for _, k := range fields {
-			if v := rec.ValueByKey(k); v != nil {
-				if f, ok := toFloat(v); ok {
-					out[k] = f
-				}
-			}
-		}
-	}
-	return out, res.Err()
-}
-
-// Point es el punto de serie temporal para el frontend.
-type Point struct {
-	T time.Time `json:"t"`
-	V float64   `json:"v"`
-}
-
-// TimeSeries retorna una serie temporal para un `field` dado, con rango/agg/interval configurables.
-func (s *MetricService) TimeSeries(ctx context.Context, device, field, rangeDur, agg, interval string) ([]Point, error) {
-	q, err := s.queryAPI()
-	if err != nil {
-		return nil, err
-	}
-
-	r := durOrDefault(rangeDur, "1h")
-	a := aggOrDefault(agg, "mean")
-	every := intervalOrDefault(interval, "1m")
-
-	fn := "mean"
-	switch a {
-	case "min":
-		fn = "min"
-	case "max":
-		fn = "max"
-	case "last":
-		fn = "last"
-	case "sum":
-		fn = "sum"
-	}
-
-	flux := fmt.Sprintf(`
-from(bucket: "%s")
-  |> range(start: -%s)
-  |> filter(fn: (r) => r._measurement == "system_metrics" and r.device == "%s" and r._field == "%s")
-  |> aggregateWindow(every: %s, fn: %s, createEmpty: false)
-  |> keep(columns: ["_time","_value"])
-`, s.writer.Bucket(), r, device, field, every, fn)
-
-	res, err := q.Query(ctx, flux)
-	if err != nil {
-		return nil, err
 	}
-	defer res.Close()
 
-	out := []Point{}
-	for res.Next() {
-		rec := res.Record()
-		if f, ok := toFloat(rec.Value()); ok {
-			out = append(out, Point{T: rec.Time(), V: f})
-		}
+	if s.store == nil {
+		fmt.Printf("[metrics] almacenamiento no configurado, %d métricas descartadas\n", len(ms))
+		return nil
 	}
-	return out, res.Err()
+	return s.store.WriteMetrics(context.Background(), ms)
 }
 
-// History retorna los últimos N registros para un dispositivo.
-func (s *MetricService) History(ctx context.Context, device, rangeDur string) ([]map[string]interface{}, error) {
-	q, err := s.queryAPI()
-	if err != nil {
-		return nil, err
+// ValidateMetric revisa una métrica recibida antes de guardarla.
+func ValidateMetric(m domain.Metric) error {
+	if m.DeviceName == "" {
+		return errors.New("device_name required")
 	}
-
-	r := durOrDefault(rangeDur, "1h")
-	fields := []string{"cpu", "ram", "disk", "net_rx", "net_tx", "temp", "uptime"}
-
-	flux := fmt.Sprintf(`
-from(bucket: "%[1]s")
-  |> range(start: -%[2]s)
-  |> filter(fn: (r) => r._measurement == "system_metrics" and r.device == "%[3]s")
-  |> filter(fn: (r) => contains(value: r._field, set: %[4]s))
-  |> pivot(rowKey:["_time"], columnKey:["_field"], valueColumn:"_value")
-  |> keep(columns: ["_time","cpu","ram","disk","net_rx","net_tx","temp","uptime"])
-  |> sort(columns: ["_time"], desc: true)
-  |> limit(n: 100)
-`, s.writer.Bucket(), r, device, fieldsToFluxArray(fields))
-
-	res, err := q.Query(ctx, flux)
-	if err != nil {
-		return nil, err
-	}
-	defer res.Close()
-
-	out := make([]map[string]interface{}, 0)
-	for res.Next() {
-		rec := res.Record()
-		row := map[string]interface{}{
-			"time": rec.Time(),
+	for name, v := range map[string]float64{"cpu_usage": m.CPUUsage, "ram_usage": m.RAMUsage, "disk_usage": m.DiskUsage} {
+		if v < 0 || v > 100 {
+			return fmt.Errorf("%s out of range: %.2f", name, v)
 		}
-		for _, k := range fields {
-			if v := rec.ValueByKey(k); v != nil {
-				if f, ok := toFloat(v); ok {
-					row[k] = f
-				}
-			}
-		}
-		out = append(out, row)
 	}
-	return out, res.Err()
+	if len(m.TopProcs) > maxTopProcs {
+		return fmt.Errorf("top_procs: too many processes (%d, max %d)", len(m.TopProcs), maxTopProcs)
+	}
+	return nil
 }
 
-// ====== Helpers internos ======
-
-func (s *MetricService) queryAPI() (api.QueryAPI, error) {
-	if s.writer == nil || s.writer.Client() == nil || s.writer.Org() == "" || s.writer.Bucket() == "" {
-		return nil, fmt.Errorf("influx query no inicializado")
+// ListDevices devuelve los nombres de los dispositivos registrados.
+func (s *MetricService) ListDevices(ctx context.Context, scope domain.MetricScope) ([]string, error) {
+	if s.devices == nil {
+		return nil, errors.New("device registry not configured")
 	}
-	return s.writer.Client().QueryAPI(s.writer.Org()), nil
+	return s.devices.Names(scope)
 }
 
-func toFloat(v any) (float64, bool) {
-	switch x := v.(type) {
-	case float64:
-		return x, true
-	case float32:
-		return float64(x), true
-	case int64:
-		return float64(x), true
-	case int32:
-		return float64(x), true
-	case int:
-		return float64(x), true
-	default:
-		return 0, false
+// LastStats retorna el último valor conocido de cada métrica para un dispositivo.
+func (s *MetricService) LastStats(ctx context.Context, scope domain.MetricScope, device string) (map[string]interface{}, error) {
+	if s.store == nil {
+		return nil, errStoreNotConfigured
 	}
+	return s.store.LastStats(ctx, scope, device)
 }
 
-func fieldsToFluxArray(fields []string) string {
-	out := "["
-	for i, f := range fields {
-		if i > 0 {
-			out += ","
-		}
-		out += `"` + f + `"`
+// TimeSeries retorna una serie temporal para un `field` dado, con rango/agg/interval configurables.
+// En las series por instancia (ej. mount_used_pct) `instance` elige cuál (ej. "/data").
+func (s *MetricService) TimeSeries(ctx context.Context, scope domain.MetricScope, device, field, instance, rangeDur, agg, interval string) ([]domain.MetricPoint, error) {
+	if s.store == nil {
+		return nil, errStoreNotConfigured
 	}
-	out += "]"
-	return out
+	return s.store.TimeSeries(ctx, scope, device, field, instance, rangeDur, agg, interval)
 }
 
-func durOrDefault(s, def string) string {
-	if s == "" {
-		return def
+// History retorna los últimos N registros para un dispositivo.
+func (s *MetricService) History(ctx context.Context, scope domain.MetricScope, device, rangeDur string) ([]map[string]interface{}, error) {
+	if s.store == nil {
+		return nil, errStoreNotConfigured
 	}
-	return s
+	return s.store.History(ctx, scope, device, rangeDur)
 }
 
-func aggOrDefault(s, def string) string {
-	switch s {
-	case "mean", "min", "max", "last", "sum":
-		return s
-	default:
-		if def == "" {
-			return "mean"
-		}
-		return def
+// LastInstances retorna el último valor de cada instancia de un grupo de series
+// (ej. "mount") para un dispositivo.
+func (s *MetricService) LastInstances(ctx context.Context, scope domain.MetricScope, device, group string) ([]map[string]interface{}, error) {
+	if s.store == nil {
+		return nil, errStoreNotConfigured
 	}
+	return s.store.LastInstances(ctx, scope, device, group)
 }
 
-func intervalOrDefault(s, def string) string {
-	if s == "" {
-		return def
+// Processes retorna la última lista de procesos reportada por un dispositivo.
+func (s *MetricService) Processes(ctx context.Context, scope domain.MetricScope, device string) (*domain.ProcessSnapshot, error) {
+	if s.processes == nil {
+		return nil, errors.New("process registry not configured")
 	}
-	return s
+	return s.processes.Latest(scope, device)
 }
+
+var errStoreNotConfigured = errors.New("almacenamiento de métricas no inicializado")
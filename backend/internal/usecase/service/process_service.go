@@ -0,0 +1,66 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+)
+
+// maxTopProcs limita cuántos procesos se aceptan por métrica.
+const maxTopProcs = 50
+
+// ProcessService guarda la última lista de procesos de cada dispositivo.
+type ProcessService struct {
+	repo domain.ProcessSnapshotRepository
+}
+
+func NewProcessService(repo domain.ProcessSnapshotRepository) *ProcessService {
+	return &ProcessService{repo: repo}
+}
+
+// Record guarda, por dispositivo, la métrica más reciente del lote que trae procesos.
+func (s *ProcessService) Record(ms []domain.Metric) error {
+	latest := make(map[deviceKey]*domain.Metric)
+	var order []deviceKey
+	for i := range ms {
+		m := &ms[i]
+		if len(m.TopProcs) == 0 {
+			continue
+		}
+		k := metricDevice(*m)
+		prev, ok := latest[k]
+		if !ok {
+			order = append(order, k)
+		}
+		if !ok || !m.Timestamp.Before(prev.Timestamp) {
+			latest[k] = m
+		}
+	}
+
+	now := time.Now().UTC()
+	var errs []error
+	for _, k := range order {
+		m := latest[k]
+		ts := m.Timestamp
+		if ts.IsZero() || ts.After(now) {
+			ts = now
+		}
+		snap := &domain.ProcessSnapshot{
+			Device:    k.name,
+			OwnerID:   k.owner,
+			Time:      ts,
+			Processes: m.TopProcs,
+		}
+		if err := s.repo.Save(snap); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", k.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Latest devuelve la última lista de procesos del dispositivo.
+func (s *ProcessService) Latest(scope domain.MetricScope, device string) (*domain.ProcessSnapshot, error) {
+	return s.repo.Latest(scope, device)
+}
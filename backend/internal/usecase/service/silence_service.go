@@ -0,0 +1,116 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+)
+
+type SilenceService struct {
+	repo         domain.SilenceRepository
+	users        domain.UserRepository
+	alertService domain.AlertService
+}
+
+func NewSilenceService(repo domain.SilenceRepository, users domain.UserRepository, alertService domain.AlertService) *SilenceService {
+	return &SilenceService{
+		repo:         repo,
+		users:        users,
+		alertService: alertService,
+	}
+}
+
+func (s *SilenceService) List(scope domain.MetricScope) ([]domain.Silence, error) {
+	return s.repo.List(scope)
+}
+
+func (s *SilenceService) Get(id uint, scope domain.MetricScope) (*domain.Silence, error) {
+	return s.repo.FindByID(id, scope)
+}
+
+// Create stores a silence owned by the caller, who is recorded as its author.
+// Without starts_at it starts right away.
+func (s *SilenceService) Create(scope domain.MetricScope, silence *domain.Silence) error {
+	silence.ID = 0
+	silence.UserID = scope.UserID
+	silence.Author = s.author(scope.UserID)
+	if silence.StartsAt.IsZero() {
+		silence.StartsAt = time.Now()
+	}
+	if err := checkSilence(scope, *silence); err != nil {
+		return err
+	}
+	if err := s.repo.Create(silence); err != nil {
+		return err
+	}
+	return s.LoadSilences()
+}
+
+// Update replaces the matchers and schedule of a silence; owner and author are kept.
+func (s *SilenceService) Update(id uint, scope domain.MetricScope, silence *domain.Silence) error {
+	existing, err := s.repo.FindByID(id, scope)
+	if err != nil {
+		return err
+	}
+	silence.ID = existing.ID
+	silence.UserID = existing.UserID
+	silence.Author = existing.Author
+	silence.CreatedAt = existing.CreatedAt
+	if silence.StartsAt.IsZero() {
+		silence.StartsAt = existing.StartsAt
+	}
+	if err := checkSilence(scope, *silence); err != nil {
+		return err
+	}
+	if err := s.repo.Update(silence); err != nil {
+		return err
+	}
+	return s.LoadSilences()
+}
+
+func (s *SilenceService) Delete(id uint, scope domain.MetricScope) error {
+	if err := s.repo.Delete(id, scope); err != nil {
+		return err
+	}
+	return s.LoadSilences()
+}
+
+// LoadSilences hands the silences that haven't ended to the alert service.
+func (s *SilenceService) LoadSilences() error {
+	if s.alertService == nil {
+		return nil
+	}
+	all, err := s.repo.FindAll()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	current := make([]domain.Silence, 0, len(all))
+	for _, silence := range all {
+		if silence.EndsAt == nil || silence.EndsAt.After(now) {
+			current = append(current, silence)
+		}
+	}
+	s.alertService.UpdateSilences(current)
+	return nil
+}
+
+func checkSilence(scope domain.MetricScope, silence domain.Silence) error {
+	if silence.Global && !scope.All {
+		return fmt.Errorf("%w: only admins can create global silences", domain.ErrInvalidSilence)
+	}
+	return silence.Validate()
+}
+
+func (s *SilenceService) author(userID uint) string {
+	if s.users != nil {
+		if u, err := s.users.FindByID(userID); err == nil && u != nil {
+			if u.Email != "" {
+				return u.Email
+			}
+			return u.Username
+		}
+	}
+	return fmt.Sprintf("user %d", userID)
+}
@@ -0,0 +1,73 @@
+package service
+
+import "slices"
+
+// deviceDependencies reads the parent/child relations drawn between device
+// nodes: an edge from one device to another means the target sits behind the
+// source. Nodes in between that aren't devices, such as a router or switch
+// without an agent, pass the dependency through. It returns, by device name,
+// the direct parents and every device behind it.
+func deviceDependencies(flow FlowData, nodeMap map[string]Node) (upstream, downstream map[string][]string) {
+	upstream = make(map[string][]string)
+	children := make(map[string][]string)
+
+	for _, n := range flow.Nodes {
+		if n.Type != "device" {
+			continue
+		}
+		name := deviceNodeName(n)
+		if name == "" {
+			continue
+		}
+
+		seen := map[string]bool{n.ID: true}
+		queue := []string{n.ID}
+		for len(queue) > 0 {
+			cur := queue[0]
+			queue = queue[1:]
+			for _, src := range findSourceNodeIDs(flow.Edges, cur) {
+				if seen[src] {
+					continue
+				}
+				seen[src] = true
+				switch node := nodeMap[src]; node.Type {
+				case "device":
+					if parent := deviceNodeName(node); parent != "" && parent != name && !slices.Contains(upstream[name], parent) {
+						upstream[name] = append(upstream[name], parent)
+						children[parent] = append(children[parent], name)
+					}
+				case "router":
+					queue = append(queue, src)
+				}
+			}
+		}
+	}
+
+	downstream = make(map[string][]string)
+	for parent := range children {
+		seen := map[string]bool{parent: true}
+		stack := append([]string(nil), children[parent]...)
+		for len(stack) > 0 {
+			dev := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if seen[dev] {
+				continue
+			}
+			seen[dev] = true
+			downstream[parent] = append(downstream[parent], dev)
+			stack = append(stack, children[dev]...)
+		}
+	}
+	return upstream, downstream
+}
+
+// deviceNodeName is the device a node stands for: DeviceNode keeps it in
+// 'label' or 'deviceName', MonitoringNode in 'connectedDevice'.
+func deviceNodeName(n Node) string {
+	for _, key := range []string{"label", "deviceName", "connectedDevice"} {
+		if name, _ := n.Data[key].(string); name != "" {
+			return name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+)
+
+// isLogicNode reports whether the node combines conditions (and, or, not).
+func isLogicNode(n Node) bool {
+	switch n.Type {
+	case domain.ExprAnd, domain.ExprOr, domain.ExprNot:
+		return true
+	}
+	return false
+}
+
+// feedsLogicNode reports whether any logic node reads the node.
+func feedsLogicNode(edges []Edge, nodeMap map[string]Node, sourceID string) bool {
+	for _, e := range edges {
+		if e.Source == sourceID && isLogicNode(nodeMap[e.Target]) {
+			return true
+		}
+	}
+	return false
+}
+
+// findSourceNodeIDs returns every node feeding targetID, each once.
+func findSourceNodeIDs(edges []Edge, targetID string) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, e := range edges {
+		if e.Target == targetID && !seen[e.Source] {
+			seen[e.Source] = true
+			ids = append(ids, e.Source)
+		}
+	}
+	return ids
+}
+
+// compileExpr builds the expression tree rooted at a logic node. Its inputs
+// must be action nodes connected to a device, or other logic nodes; path
+// holds the logic nodes being compiled, to detect cycles.
+func compileExpr(id string, edges []Edge, nodeMap map[string]Node, conds map[string]*domain.AlertRule, path map[string]bool) (*domain.AlertExpr, error) {
+	if path[id] {
+		return nil, fmt.Errorf("%w: logic node %s is part of a cycle", domain.ErrInvalidTopology, id)
+	}
+	path[id] = true
+	defer delete(path, id)
+
+	node := nodeMap[id]
+	inputs := findSourceNodeIDs(edges, id)
+	switch {
+	case node.Type == domain.ExprNot && len(inputs) != 1:
+		return nil, fmt.Errorf("%w: not node %s needs exactly one input, has %d", domain.ErrInvalidTopology, id, len(inputs))
+	case node.Type != domain.ExprNot && len(inputs) < 2:
+		return nil, fmt.Errorf("%w: %s node %s needs at least two inputs, has %d", domain.ErrInvalidTopology, node.Type, id, len(inputs))
+	}
+
+	expr := &domain.AlertExpr{Op: node.Type}
+	for _, in := range inputs {
+		src, ok := nodeMap[in]
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("%w: input %s of logic node %s does not exist", domain.ErrInvalidTopology, in, id)
+		case isLogicNode(src):
+			arg, err := compileExpr(in, edges, nodeMap, conds, path)
+			if err != nil {
+				return nil, err
+			}
+			expr.Args = append(expr.Args, arg)
+		case src.Type == "action":
+			cond, ok := conds[in]
+			if !ok {
+				return nil, fmt.Errorf("%w: action %s feeding logic node %s has no device connected", domain.ErrInvalidTopology, in, id)
+			}
+			expr.Args = append(expr.Args, &domain.AlertExpr{Cond: cond})
+		default:
+			return nil, fmt.Errorf("%w: logic node %s takes actions or logic nodes, not %s node %s", domain.ErrInvalidTopology, id, src.Type, in)
+		}
+	}
+	return expr, nil
+}
+
+// validateLogic compiles every logic node of the flow, so cycles and dangling
+// inputs are reported when the topology is saved rather than ignored.
+func validateLogic(data string) error {
+	var flow FlowData
+	if err := json.Unmarshal([]byte(data), &flow); err != nil {
+		return nil
+	}
+	nodeMap := make(map[string]Node, len(flow.Nodes))
+	for _, n := range flow.Nodes {
+		nodeMap[n.ID] = n
+	}
+
+	t := &domain.Topology{}
+	conds := make(map[string]*domain.AlertRule)
+	for _, n := range flow.Nodes {
+		if n.Type != "action" {
+			continue
+		}
+		if rule, ok := actionCondition(t, n, flow.Edges, nodeMap, func(string) {}); ok {
+			conds[n.ID] = rule
+		}
+	}
+
+	for _, n := range flow.Nodes {
+		if !isLogicNode(n) {
+			continue
+		}
+		if _, err := compileExpr(n.ID, flow.Edges, nodeMap, conds, map[string]bool{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
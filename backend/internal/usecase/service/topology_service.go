@@ -4,9 +4,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/mail"
+	"net/url"
 	"strings"
 
 	"github.com/BenjaminAGH/nocturnescope/backend/internal/domain"
+	"github.com/BenjaminAGH/nocturnescope/backend/internal/infrastructure/notify"
 )
 
 type TopologyService struct {
@@ -25,6 +28,12 @@ func (s *TopologyService) Save(userID uint, name string, data string) (*domain.T
 	if !isValidJSON(data) {
 		return nil, errors.New("invalid JSON data")
 	}
+	if err := validateTemplates(data); err != nil {
+		return nil, err
+	}
+	if err := validateLogic(data); err != nil {
+		return nil, err
+	}
 
 	t := &domain.Topology{
 		UserID: userID,
@@ -53,6 +62,12 @@ func (s *TopologyService) Update(id uint, userID uint, name string, data string)
 	if !isValidJSON(data) {
 		return nil, errors.New("invalid JSON data")
 	}
+	if err := validateTemplates(data); err != nil {
+		return nil, err
+	}
+	if err := validateLogic(data); err != nil {
+		return nil, err
+	}
 
 	existing, err := s.repo.FindByID(id, userID)
 	if err != nil {
@@ -84,6 +99,27 @@ func isValidJSON(str string) bool {
 	return json.Unmarshal([]byte(str), &js) == nil
 }
 
+// validateTemplates rejects a topology whose output nodes carry a subject or
+// body template that doesn't parse or references unknown fields.
+func validateTemplates(data string) error {
+	var flow FlowData
+	if err := json.Unmarshal([]byte(data), &flow); err != nil {
+		return nil
+	}
+	for _, n := range flow.Nodes {
+		for _, f := range []struct {
+			key  string
+			html bool
+		}{{"subject", false}, {"body", false}, {"html", true}} {
+			text, _ := n.Data[f.key].(string)
+			if err := notify.ValidateTemplate(f.key, text, f.html); err != nil {
+				return fmt.Errorf("node %s: %w", n.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
 // --- Rule Extraction Logic ---
 
 type FlowData struct {
@@ -118,101 +154,165 @@ func (s *TopologyService) processRules(t *domain.Topology) {
 
 	s.log(fmt.Sprintf("Processing topology %d. Nodes: %d, Edges: %d", t.ID, len(flow.Nodes), len(flow.Edges)))
 
-	// Find Action Nodes
-	var rules []domain.AlertRule
+	// Conditions of the action nodes, also the leaves of composite rules
+	conds := make(map[string]*domain.AlertRule)
 	for _, n := range flow.Nodes {
-		if n.Type == "action" {
-			s.log(fmt.Sprintf("Found Action Node: %s", n.ID))
-
-			// Find connected Device (input) and Email (output)
-			deviceID := findSourceNodeID(flow.Edges, n.ID)
-			emailID := findTargetNodeID(flow.Edges, n.ID)
-
-			s.log(fmt.Sprintf("Action %s connections - DeviceID: %s, EmailID: %s", n.ID, deviceID, emailID))
+		if n.Type != "action" {
+			continue
+		}
+		s.log(fmt.Sprintf("Found Action Node: %s", n.ID))
+		if rule, ok := actionCondition(t, n, flow.Edges, nodeMap, s.log); ok {
+			conds[n.ID] = rule
+		}
+	}
 
-			if deviceID == "" || emailID == "" {
-				s.log("Skipping action: missing input or output connection")
+	var rules []domain.AlertRule
+	for _, n := range flow.Nodes {
+		switch {
+		case n.Type == "action":
+			cond, ok := conds[n.ID]
+			if !ok {
 				continue
 			}
+			rule := *cond
+			rule.Outputs = s.alertOutputs(nodeMap, findOutputNodeIDs(flow.Edges, nodeMap, n.ID))
+			if len(rule.Outputs) == 0 {
+				if !feedsLogicNode(flow.Edges, nodeMap, n.ID) {
+					s.log("Skipping action: no connected output is fully configured")
+					continue
+				}
+				s.log(fmt.Sprintf("Action %s only feeds logic nodes", n.ID))
+			}
 
-			deviceNode, ok1 := nodeMap[deviceID]
-			emailNode, ok2 := nodeMap[emailID]
+			s.log(fmt.Sprintf("Extracted Data - Device: %s, Metric: %s, Op: %s, Threshold: %f, Outputs: %d", rule.DeviceID, rule.Metric, rule.Operator, rule.Threshold, len(rule.Outputs)))
+			rules = append(rules, rule)
 
-			if !ok1 || !ok2 || emailNode.Type != "email" {
-				s.log(fmt.Sprintf("Skipping action: Invalid nodes. DeviceFound: %v, EmailFound: %v, EmailNodeType: %s", ok1, ok2, emailNode.Type))
-				continue
+		case isLogicNode(n):
+			outputIDs := findOutputNodeIDs(flow.Edges, nodeMap, n.ID)
+			if len(outputIDs) == 0 {
+				continue // an inner node, compiled into the rule that reads it
 			}
-
-			// Extract device name from device node label or data
-			// Assuming device node data has 'label' which is the device name
-			deviceName, _ := deviceNode.Data["label"].(string)
-			// Also try 'deviceName' if 'label' is missing (DeviceNode uses 'deviceName')
-			if deviceName == "" {
-				deviceName, _ = deviceNode.Data["deviceName"].(string)
+			expr, err := compileExpr(n.ID, flow.Edges, nodeMap, conds, map[string]bool{})
+			if err != nil {
+				s.log(fmt.Sprintf("Skipping logic node %s: %v", n.ID, err))
+				continue
 			}
-			// Also try 'connectedDevice' if it's a MonitoringNode
-			if deviceName == "" {
-				deviceName, _ = deviceNode.Data["connectedDevice"].(string)
+			forDur, _ := n.Data["for"].(string)
+			rule := domain.AlertRule{
+				ID:           n.ID,
+				TopologyID:   t.ID,
+				TopologyName: t.Name,
+				UserID:       t.UserID,
+				DeviceID:     strings.Join(expr.Devices(), ", "),
+				Metric:       MetricComposite,
+				For:          forDur,
+				Expr:         expr,
+				Outputs:      s.alertOutputs(nodeMap, outputIDs),
 			}
-
-			if deviceName == "" {
-				s.log(fmt.Sprintf("Skipping action: Device name is empty for node %s. Data: %v", deviceNode.ID, deviceNode.Data))
+			if len(rule.Outputs) == 0 {
+				s.log("Skipping logic node: no connected output is fully configured")
 				continue
 			}
 
-			// Extract Action Data
-			metricRaw, _ := n.Data["metric"].(string)
-			metric := strings.ToLower(metricRaw)
-			if metric == "" {
-				metric = "cpu" // Default
-			}
+			s.log(fmt.Sprintf("Extracted Composite Rule %s: %s, Outputs: %d", n.ID, expr, len(rule.Outputs)))
+			rules = append(rules, rule)
+		}
+	}
 
-			operator, _ := n.Data["operator"].(string)
-			if operator == "" {
-				operator = ">=" // Default
-			}
+	// Parent/child relations between devices, to hold the alerts of devices
+	// behind one that is down
+	upstream, downstream := deviceDependencies(flow, nodeMap)
+	for i := range rules {
+		if rules[i].Expr == nil {
+			rules[i].Upstream = upstream[rules[i].DeviceID]
+			rules[i].Downstream = downstream[rules[i].DeviceID]
+		}
+	}
 
-			threshold, okThreshold := n.Data["threshold"].(float64)
-			if !okThreshold {
-				s.log(fmt.Sprintf("Warning: Threshold is not float64. Data: %v", n.Data["threshold"]))
-				// Attempt to recover if it's int or string (though JSON unmarshal usually gives float64 for numbers)
-				threshold = 70.0 // Default
-			}
+	s.alertService.UpdateRules(t.ID, rules)
+}
 
-			// Extract Email Data
-			s.log(fmt.Sprintf("Email Node Data: %v", emailNode.Data)) // Log full data
-			emailTo, _ := emailNode.Data["to"].(string)
-			subject, _ := emailNode.Data["subject"].(string)
-			body, _ := emailNode.Data["body"].(string)
-			cooldown, _ := emailNode.Data["cooldown"].(string)
+// actionCondition reads the condition of an action node and the device feeding
+// it; false when the node isn't connected to a device yet.
+func actionCondition(t *domain.Topology, n Node, edges []Edge, nodeMap map[string]Node, log func(string)) (*domain.AlertRule, bool) {
+	deviceID := findSourceNodeID(edges, n.ID)
+	if deviceID == "" {
+		log(fmt.Sprintf("Skipping action %s: missing input connection", n.ID))
+		return nil, false
+	}
 
-			s.log(fmt.Sprintf("Extracted Data - Device: %s, Metric: %s, Op: %s, Threshold: %f, Email: %s", deviceName, metric, operator, threshold, emailTo))
+	deviceNode, ok := nodeMap[deviceID]
+	if !ok {
+		log(fmt.Sprintf("Skipping action: device node %s not found", deviceID))
+		return nil, false
+	}
 
-			if emailTo == "" {
-				s.log("Skipping action: EmailTo is empty. User must configure email recipient.")
-				continue
-			}
+	deviceName := deviceNodeName(deviceNode)
+	if deviceName == "" {
+		log(fmt.Sprintf("Skipping action: Device name is empty for node %s. Data: %v", deviceNode.ID, deviceNode.Data))
+		return nil, false
+	}
 
-			if cooldown == "" {
-				cooldown = "1h" // Default
-			}
+	// Extract Action Data
+	metricRaw, _ := n.Data["metric"].(string)
+	metric := strings.ToLower(metricRaw)
+	if metric == "" {
+		metric = "cpu" // Default
+	}
 
-			rules = append(rules, domain.AlertRule{
-				ID:           n.ID,
-				TopologyID:   t.ID,
-				DeviceID:     deviceName,
-				Metric:       metric,
-				Operator:     operator,
-				Threshold:    threshold,
-				EmailTo:      emailTo,
-				EmailSubject: subject,
-				EmailBody:    body,
-				Cooldown:     cooldown,
-			})
+	operator, _ := n.Data["operator"].(string)
+	if operator == "" {
+		operator = ">=" // Default
+	}
+
+	window, _ := n.Data["window"].(string)
+	aggregation, _ := n.Data["aggregation"].(string)
+	switch aggregation {
+	case "", domain.AggLast:
+		aggregation = domain.AggLast
+	case domain.AggAvg, domain.AggMin, domain.AggMax:
+		if window == "" {
+			window = "5m" // Default
 		}
+	default:
+		log(fmt.Sprintf("Warning: unknown aggregation %q on %s, using the last value", aggregation, n.ID))
+		aggregation = domain.AggLast
+	}
+	forDur, _ := n.Data["for"].(string)
+	instance, _ := n.Data["instance"].(string) // e.g. the mount of mount_used_pct
+
+	threshold, okThreshold := n.Data["threshold"].(float64)
+	if !okThreshold && metric != MetricHeartbeat {
+		log(fmt.Sprintf("Warning: Threshold is not float64. Data: %v", n.Data["threshold"]))
+		// Attempt to recover if it's int or string (though JSON unmarshal usually gives float64 for numbers)
+		threshold = 70.0 // Default
 	}
 
-	s.alertService.UpdateRules(t.ID, rules)
+	return &domain.AlertRule{
+		ID:           n.ID,
+		TopologyID:   t.ID,
+		TopologyName: t.Name,
+		UserID:       t.UserID,
+		DeviceID:     deviceName,
+		Metric:       metric,
+		Instance:     strings.TrimSpace(instance),
+		Operator:     operator,
+		Threshold:    threshold,
+		Aggregation:  aggregation,
+		Window:       window,
+		For:          forDur,
+	}, true
+}
+
+// alertOutputs reads every connected email or HTTP channel node that is usable.
+func (s *TopologyService) alertOutputs(nodeMap map[string]Node, ids []string) []domain.AlertOutput {
+	var outputs []domain.AlertOutput
+	for _, id := range ids {
+		if out, ok := s.alertOutput(nodeMap[id]); ok {
+			outputs = append(outputs, out)
+		}
+	}
+	return outputs
 }
 
 func (s *TopologyService) LoadRules() error {
@@ -241,13 +341,142 @@ func findSourceNodeID(edges []Edge, targetID string) string {
 	return ""
 }
 
-func findTargetNodeID(edges []Edge, sourceID string) string {
+// findOutputNodeIDs returns every notification output node fed by sourceID.
+func findOutputNodeIDs(edges []Edge, nodeMap map[string]Node, sourceID string) []string {
+	var ids []string
+	seen := make(map[string]bool)
 	for _, e := range edges {
-		if e.Source == sourceID {
-			return e.Target
+		if e.Source != sourceID || seen[e.Target] {
+			continue
+		}
+		switch nodeMap[e.Target].Type {
+		case domain.ChannelEmail, domain.ChannelWebhook, domain.ChannelSlack, domain.ChannelDiscord, domain.ChannelNtfy:
+			seen[e.Target] = true
+			ids = append(ids, e.Target)
 		}
 	}
-	return ""
+	return ids
+}
+
+// alertOutput reads an output node; false when it isn't usable yet.
+func (s *TopologyService) alertOutput(node Node) (domain.AlertOutput, bool) {
+	// node.Data holds webhook URLs, signing secrets and auth headers: never log it
+	s.log(fmt.Sprintf("Output node %s (%s)", node.ID, node.Type))
+
+	out := domain.AlertOutput{NodeID: node.ID, Channel: node.Type}
+	out.Subject, _ = node.Data["subject"].(string)
+	out.Body, _ = node.Data["body"].(string)
+	out.HTML, _ = node.Data["html"].(string)
+	out.Cooldown, _ = node.Data["cooldown"].(string)
+	if out.Cooldown == "" {
+		out.Cooldown = "1h" // Default
+	}
+
+	switch node.Type {
+	case domain.ChannelEmail:
+		var bad []string
+		for _, f := range []struct {
+			key  string
+			list *[]string
+		}{{"to", &out.To}, {"cc", &out.Cc}, {"bcc", &out.Bcc}} {
+			var invalid []string
+			*f.list, invalid = ParseRecipients(node.Data[f.key])
+			bad = append(bad, invalid...)
+		}
+		if len(bad) > 0 {
+			s.log(fmt.Sprintf("Output %s: ignoring invalid recipients %v", node.ID, bad))
+		}
+		if len(out.To)+len(out.Cc)+len(out.Bcc) == 0 {
+			s.log(fmt.Sprintf("Skipping output %s: no recipients. User must configure email recipient.", node.ID))
+			return out, false
+		}
+	default:
+		out.Webhook = webhookConfig(node.Data)
+		if !validWebhookURL(out.Webhook.URL) {
+			s.log(fmt.Sprintf("Skipping output %s: invalid %s URL. User must configure an http(s) endpoint.", node.ID, node.Type))
+			return out, false
+		}
+	}
+	return out, true
+}
+
+// ParseRecipients accepts a list or a string of addresses separated by commas,
+// semicolons or new lines, and returns the valid addresses (deduplicated)
+// and the entries that couldn't be parsed.
+func ParseRecipients(v interface{}) (valid, invalid []string) {
+	var raw []string
+	switch x := v.(type) {
+	case string:
+		raw = []string{x}
+	case []interface{}:
+		for _, item := range x {
+			if str, ok := item.(string); ok {
+				raw = append(raw, str)
+			}
+		}
+	case []string:
+		raw = x
+	}
+
+	seen := make(map[string]bool)
+	for _, r := range raw {
+		for _, entry := range strings.FieldsFunc(r, func(c rune) bool { return c == ',' || c == ';' || c == '\n' }) {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+			addr, err := mail.ParseAddress(entry)
+			if err != nil {
+				invalid = append(invalid, entry)
+				continue
+			}
+			if !seen[strings.ToLower(addr.Address)] {
+				seen[strings.ToLower(addr.Address)] = true
+				valid = append(valid, addr.Address)
+			}
+		}
+	}
+	return valid, invalid
+}
+
+// validWebhookURL accepts absolute http(s) URLs only.
+func validWebhookURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// webhookConfig reads an HTTP output node. Headers may come as an object or as
+// "Name: value" lines.
+func webhookConfig(data map[string]interface{}) domain.WebhookConfig {
+	cfg := domain.WebhookConfig{Headers: map[string]string{}}
+	cfg.URL, _ = data["url"].(string)
+	cfg.Method, _ = data["method"].(string)
+	cfg.Secret, _ = data["secret"].(string)
+	cfg.Timeout, _ = data["timeout"].(string)
+	cfg.URL = strings.TrimSpace(cfg.URL)
+	cfg.Method = strings.ToUpper(strings.TrimSpace(cfg.Method))
+
+	if r, ok := data["retries"].(float64); ok {
+		retries := int(r)
+		cfg.Retries = &retries
+	}
+
+	switch h := data["headers"].(type) {
+	case map[string]interface{}:
+		for k, v := range h {
+			if str, ok := v.(string); ok {
+				cfg.Headers[k] = str
+			}
+		}
+	case string:
+		for _, line := range strings.Split(h, "\n") {
+			k, v, ok := strings.Cut(line, ":")
+			if ok && strings.TrimSpace(k) != "" {
+				cfg.Headers[strings.TrimSpace(k)] = strings.TrimSpace(v)
+			}
+		}
+	}
+	return cfg
 }
 
 func (s *TopologyService) log(msg string) {
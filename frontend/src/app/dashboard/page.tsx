@@ -15,6 +15,14 @@ import {
   getDevices,
   getLastStats,
   getTimeseries,
+  getMounts,
+  getInterfaces,
+  getProcesses,
+  getDisks,
+  MountStats,
+  InterfaceStatsRow,
+  DiskIOStats,
+  ProcessSnapshot,
 } from "@/lib/api/api";
 import { formatCL, formatTickCL } from "@/lib/time";
 import LogViewer from "@/components/LogViewer";
@@ -25,12 +33,59 @@ const FIELD_OPTIONS = [
   { v: "cpu", l: "CPU (%)" },
   { v: "ram", l: "RAM (%)" },
   { v: "disk", l: "DISK (%)" },
-  { v: "net_rx", l: "Net RX (B/s)" },
-  { v: "net_tx", l: "Net TX (B/s)" },
+  { v: "net_rx", l: "Net RX (B)" },
+  { v: "net_tx", l: "Net TX (B)" },
+  { v: "net_rx_rate", l: "Net RX (B/s)" },
+  { v: "net_tx_rate", l: "Net TX (B/s)" },
   { v: "temp", l: "Temp (°C)" },
   { v: "uptime", l: "Uptime (s)" },
+  { v: "load1", l: "Load 1m" },
+  { v: "load5", l: "Load 5m" },
+  { v: "load15", l: "Load 15m" },
+  { v: "swap", l: "Swap (%)" },
+  { v: "psi_cpu_some", l: "Presión CPU (%)" },
+  { v: "psi_memory_some", l: "Presión memoria (%)" },
+  { v: "psi_memory_full", l: "Presión memoria, total (%)" },
+  { v: "psi_io_some", l: "Presión I/O (%)" },
+  { v: "psi_io_full", l: "Presión I/O, total (%)" },
+  { v: "ctx_switch_rate", l: "Cambios de contexto (/s)" },
+  { v: "fork_rate", l: "Procesos creados (/s)" },
+  { v: "mount_used_pct", l: "Montaje: uso (%)" },
+  { v: "mount_free_bytes", l: "Montaje: libre (B)" },
+  { v: "mount_inodes_used_pct", l: "Montaje: inodos (%)" },
+  { v: "iface_rx_bytes_rate", l: "Interfaz: RX (B/s)" },
+  { v: "iface_tx_bytes_rate", l: "Interfaz: TX (B/s)" },
+  { v: "iface_rx_packets_rate", l: "Interfaz: RX (pkt/s)" },
+  { v: "iface_tx_packets_rate", l: "Interfaz: TX (pkt/s)" },
+  { v: "iface_rx_errors_rate", l: "Interfaz: errores RX (/s)" },
+  { v: "iface_tx_errors_rate", l: "Interfaz: errores TX (/s)" },
+  { v: "iface_rx_drops_rate", l: "Interfaz: descartes RX (/s)" },
+  { v: "iface_tx_drops_rate", l: "Interfaz: descartes TX (/s)" },
+  { v: "iface_up", l: "Interfaz: enlace (1 = arriba)" },
+  { v: "disk_read_bytes_rate", l: "Disco: lectura (B/s)" },
+  { v: "disk_write_bytes_rate", l: "Disco: escritura (B/s)" },
+  { v: "disk_read_iops", l: "Disco: lecturas (IOPS)" },
+  { v: "disk_write_iops", l: "Disco: escrituras (IOPS)" },
+  { v: "disk_await_ms", l: "Disco: espera (ms)" },
+  { v: "disk_util_pct", l: "Disco: utilización (%)" },
 ];
 
+// las series por instancia (montaje, interfaz, disco) necesitan elegir cuál
+const isMountField = (f: string) => f.startsWith("mount_");
+const isIfaceField = (f: string) => f.startsWith("iface_");
+const isDiskField = (f: string) => f.startsWith("disk_");
+
+function formatBytes(b?: number) {
+  if (typeof b !== "number") return "—";
+  const units = ["B", "KB", "MB", "GB", "TB"];
+  let i = 0;
+  while (b >= 1024 && i < units.length - 1) {
+    b /= 1024;
+    i++;
+  }
+  return `${b.toFixed(i ? 1 : 0)} ${units[i]}`;
+}
+
 const RANGE_OPTIONS = ["30m", "1h", "6h", "24h", "7d"];
 const INTERVAL_OPTIONS = ["1m", "5m", "15m", "1h"];
 const AGG_OPTIONS = ["mean", "min", "max", "last"];
@@ -45,9 +100,15 @@ export default function DashboardPage() {
   const [range, setRange] = useState<string>("1h");
   const [interval, setInterval] = useState<string>("1m");
   const [agg, setAgg] = useState<string>("mean");
+  const [instance, setInstance] = useState<string>("");
 
   const [points, setPoints] = useState<Point[]>([]);
   const [last, setLast] = useState<Record<string, any> | null>(null);
+  const [mounts, setMounts] = useState<MountStats[]>([]);
+  const [ifaces, setIfaces] = useState<InterfaceStatsRow[]>([]);
+  const [disks, setDisks] = useState<DiskIOStats[]>([]);
+  const [procs, setProcs] = useState<ProcessSnapshot | null>(null);
+  const [procSort, setProcSort] = useState<"cpu" | "mem">("cpu");
 
   const [loadingDevices, setLoadingDevices] = useState(false);
   const [loadingSeries, setLoadingSeries] = useState(false);
@@ -88,9 +149,33 @@ export default function DashboardPage() {
     setErr("");
     const fetchMetrics = async () => {
       try {
+        const [mountList, ifaceList, diskList, procSnap] = await Promise.all([
+          getMounts(jwt, device),
+          getInterfaces(jwt, device),
+          getDisks(jwt, device),
+          getProcesses(jwt, device),
+        ]);
+        setMounts(mountList);
+        setIfaces(ifaceList);
+        setDisks(diskList);
+        setProcs(procSnap);
+
+        // sin instancia elegida se grafica la primera
+        const names = isMountField(field)
+          ? mountList.map((m) => m.mount)
+          : isIfaceField(field)
+            ? ifaceList.map((i) => i.iface)
+            : isDiskField(field)
+              ? diskList.map((d) => d.disk)
+              : null;
+        const inst = names ? (names.includes(instance) ? instance : names[0] || "") : undefined;
+        if (inst !== undefined && inst !== instance) setInstance(inst);
+
         const [lastStats, ts] = await Promise.all([
           getLastStats(jwt, device),
-          getTimeseries(jwt, { device, field, range, agg, interval }),
+          inst === ""
+            ? Promise.resolve({ points: [] })
+            : getTimeseries(jwt, { device, field, range, agg, interval, instance: inst }),
         ]);
         setLast(lastStats);
         setPoints(ts.points || []);
@@ -104,12 +189,28 @@ export default function DashboardPage() {
     fetchMetrics();
     const intervalId = window.setInterval(fetchMetrics, 5000);
     return () => window.clearInterval(intervalId);
-  }, [jwt, device, field, range, agg, interval]);
+  }, [jwt, device, field, range, agg, interval, instance]);
+
+  const instanceOptions = isMountField(field)
+    ? mounts.map((m) => m.mount)
+    : isIfaceField(field)
+      ? ifaces.map((i) => i.iface)
+      : isDiskField(field)
+        ? disks.map((d) => d.disk)
+        : null;
+
+  const sortedProcs = useMemo(() => {
+    const list = [...(procs?.processes ?? [])];
+    return procSort === "cpu"
+      ? list.sort((a, b) => b.cpu_pct - a.cpu_pct)
+      : list.sort((a, b) => b.rss_bytes - a.rss_bytes);
+  }, [procs, procSort]);
 
   const subtitle = useMemo(() => {
     const f = FIELD_OPTIONS.find(x => x.v === field)?.l ?? field;
-    return `${device ? device : "—"} • ${f} • ${range} • ${agg.toUpperCase()}`;
-  }, [device, field, range, agg]);
+    const m = (isMountField(field) || isIfaceField(field) || isDiskField(field)) && instance ? ` ${instance}` : "";
+    return `${device ? device : "—"} • ${f}${m} • ${range} • ${agg.toUpperCase()}`;
+  }, [device, field, range, agg, instance]);
 
   return (
     <div className="container mx-auto px-4 py-6 space-y-6">
@@ -154,6 +255,23 @@ export default function DashboardPage() {
                 </option>
               ))}
             </select>
+            {instanceOptions && (
+              <select
+                className="w-full bg-background border rounded px-3 py-2 text-sm"
+                value={instance}
+                onChange={(e) => setInstance(e.target.value)}
+                disabled={!instanceOptions.length}
+              >
+                {!instanceOptions.length ? (
+                  <option value="">{isMountField(field) ? "Sin montajes" : isIfaceField(field) ? "Sin interfaces" : "Sin discos"}</option>
+                ) : null}
+                {instanceOptions.map((name) => (
+                  <option key={name} value={name}>
+                    {name}
+                  </option>
+                ))}
+              </select>
+            )}
           </div>
 
           <div className="space-y-1">
@@ -216,10 +334,15 @@ export default function DashboardPage() {
           ["cpu", "CPU (%)"],
           ["ram", "RAM (%)"],
           ["disk", "DISK (%)"],
-          ["net_rx", "Net RX (B/s)"],
-          ["net_tx", "Net TX (B/s)"],
+          ["net_rx_rate", "Net RX (B/s)"],
+          ["net_tx_rate", "Net TX (B/s)"],
           ["temp", "Temp (°C)"],
           ["uptime", "Uptime (s)"],
+          ["load1", "Load 1m"],
+          ["swap", "Swap (%)"],
+          ["psi_cpu_some", "Presión CPU (%)"],
+          ["psi_memory_some", "Presión memoria (%)"],
+          ["psi_io_some", "Presión I/O (%)"],
         ].map(([k, label]) => (
           <div
             key={k}
@@ -252,6 +375,217 @@ export default function DashboardPage() {
         </div>
       </section>
 
+      {/* Puntos de montaje */}
+      {mounts.length > 0 && (
+        <section className="rounded-xl bg-card text-card-foreground ring-1 ring-border/50 overflow-hidden">
+          <div className="px-4 pt-4 pb-2 text-sm text-muted-foreground">Puntos de montaje</div>
+          <div className="overflow-x-auto">
+            <table className="w-full text-sm">
+              <thead className="bg-muted/50 border-y border-border">
+                <tr>
+                  <th className="text-left px-4 py-2 font-medium">Montaje</th>
+                  <th className="text-left px-4 py-2 font-medium">Uso</th>
+                  <th className="text-right px-4 py-2 font-medium">Libre</th>
+                  <th className="text-right px-4 py-2 font-medium">Total</th>
+                  <th className="text-right px-4 py-2 font-medium">Inodos</th>
+                </tr>
+              </thead>
+              <tbody>
+                {mounts.map((m) => {
+                  const used = m.mount_used_pct ?? 0;
+                  return (
+                    <tr key={m.mount} className="border-b border-border last:border-0">
+                      <td className="px-4 py-2 font-mono">
+                        {m.mount}
+                        {m.mount_read_only ? (
+                          <span className="ml-2 px-1.5 py-0.5 text-xs rounded bg-yellow-500/10 text-yellow-600 dark:text-yellow-400">
+                            sólo lectura
+                          </span>
+                        ) : null}
+                      </td>
+                      <td className="px-4 py-2">
+                        <div className="flex items-center gap-2">
+                          <div className="h-2 w-32 rounded bg-muted overflow-hidden">
+                            <div
+                              className={`h-full ${used >= 90 ? "bg-red-500" : used >= 75 ? "bg-yellow-500" : "bg-green-500"}`}
+                              style={{ width: `${Math.min(used, 100)}%` }}
+                            />
+                          </div>
+                          <span>{used.toFixed(1)}%</span>
+                        </div>
+                      </td>
+                      <td className="px-4 py-2 text-right">{formatBytes(m.mount_free_bytes)}</td>
+                      <td className="px-4 py-2 text-right">{formatBytes(m.mount_total_bytes)}</td>
+                      <td className="px-4 py-2 text-right">
+                        {typeof m.mount_inodes_used_pct === "number" ? `${m.mount_inodes_used_pct.toFixed(1)}%` : "—"}
+                      </td>
+                    </tr>
+                  );
+                })}
+              </tbody>
+            </table>
+          </div>
+        </section>
+      )}
+
+      {/* Interfaces de red */}
+      {ifaces.length > 0 && (
+        <section className="rounded-xl bg-card text-card-foreground ring-1 ring-border/50 overflow-hidden">
+          <div className="px-4 pt-4 pb-2 text-sm text-muted-foreground">Interfaces de red</div>
+          <div className="overflow-x-auto">
+            <table className="w-full text-sm">
+              <thead className="bg-muted/50 border-y border-border">
+                <tr>
+                  <th className="text-left px-4 py-2 font-medium">Interfaz</th>
+                  <th className="text-left px-4 py-2 font-medium">Enlace</th>
+                  <th className="text-right px-4 py-2 font-medium">RX</th>
+                  <th className="text-right px-4 py-2 font-medium">TX</th>
+                  <th className="text-right px-4 py-2 font-medium">Paquetes RX/TX</th>
+                  <th className="text-right px-4 py-2 font-medium">Errores RX/TX</th>
+                  <th className="text-right px-4 py-2 font-medium">Descartes RX/TX</th>
+                </tr>
+              </thead>
+              <tbody>
+                {ifaces.map((i) => {
+                  const pair = (a?: number, b?: number) =>
+                    typeof a === "number" && typeof b === "number" ? `${a.toFixed(1)} / ${b.toFixed(1)}` : "—";
+                  const hasErrors = (i.iface_rx_errors_rate ?? 0) + (i.iface_tx_errors_rate ?? 0) > 0;
+                  return (
+                    <tr key={i.iface} className="border-b border-border last:border-0">
+                      <td className="px-4 py-2 font-mono">{i.iface}</td>
+                      <td className="px-4 py-2">
+                        <span
+                          className={`px-1.5 py-0.5 text-xs rounded ${i.iface_up
+                            ? "bg-green-500/10 text-green-600 dark:text-green-400"
+                            : "bg-red-500/10 text-red-600 dark:text-red-400"
+                            }`}
+                        >
+                          {i.iface_up ? "arriba" : "abajo"}
+                        </span>
+                      </td>
+                      <td className="px-4 py-2 text-right">
+                        {typeof i.iface_rx_bytes_rate === "number" ? `${formatBytes(i.iface_rx_bytes_rate)}/s` : "—"}
+                      </td>
+                      <td className="px-4 py-2 text-right">
+                        {typeof i.iface_tx_bytes_rate === "number" ? `${formatBytes(i.iface_tx_bytes_rate)}/s` : "—"}
+                      </td>
+                      <td className="px-4 py-2 text-right">{pair(i.iface_rx_packets_rate, i.iface_tx_packets_rate)}</td>
+                      <td className={`px-4 py-2 text-right ${hasErrors ? "text-destructive" : ""}`}>
+                        {pair(i.iface_rx_errors_rate, i.iface_tx_errors_rate)}
+                      </td>
+                      <td className="px-4 py-2 text-right">{pair(i.iface_rx_drops_rate, i.iface_tx_drops_rate)}</td>
+                    </tr>
+                  );
+                })}
+              </tbody>
+            </table>
+          </div>
+        </section>
+      )}
+
+      {/* Discos */}
+      {disks.length > 0 && (
+        <section className="rounded-xl bg-card text-card-foreground ring-1 ring-border/50 overflow-hidden">
+          <div className="px-4 pt-4 pb-2 text-sm text-muted-foreground">Discos (I/O)</div>
+          <div className="overflow-x-auto">
+            <table className="w-full text-sm">
+              <thead className="bg-muted/50 border-y border-border">
+                <tr>
+                  <th className="text-left px-4 py-2 font-medium">Disco</th>
+                  <th className="text-right px-4 py-2 font-medium">Lectura</th>
+                  <th className="text-right px-4 py-2 font-medium">Escritura</th>
+                  <th className="text-right px-4 py-2 font-medium">IOPS L/E</th>
+                  <th className="text-right px-4 py-2 font-medium">Espera</th>
+                  <th className="text-left px-4 py-2 font-medium">Utilización</th>
+                </tr>
+              </thead>
+              <tbody>
+                {disks.map((d) => {
+                  const util = d.disk_util_pct ?? 0;
+                  return (
+                    <tr key={d.disk} className="border-b border-border last:border-0">
+                      <td className="px-4 py-2 font-mono">{d.disk}</td>
+                      <td className="px-4 py-2 text-right">
+                        {typeof d.disk_read_bytes_rate === "number" ? `${formatBytes(d.disk_read_bytes_rate)}/s` : "—"}
+                      </td>
+                      <td className="px-4 py-2 text-right">
+                        {typeof d.disk_write_bytes_rate === "number" ? `${formatBytes(d.disk_write_bytes_rate)}/s` : "—"}
+                      </td>
+                      <td className="px-4 py-2 text-right">
+                        {typeof d.disk_read_iops === "number" && typeof d.disk_write_iops === "number"
+                          ? `${d.disk_read_iops.toFixed(1)} / ${d.disk_write_iops.toFixed(1)}`
+                          : "—"}
+                      </td>
+                      <td className="px-4 py-2 text-right">
+                        {typeof d.disk_await_ms === "number" ? `${d.disk_await_ms.toFixed(1)} ms` : "—"}
+                      </td>
+                      <td className="px-4 py-2">
+                        <div className="flex items-center gap-2">
+                          <div className="h-2 w-32 rounded bg-muted overflow-hidden">
+                            <div
+                              className={`h-full ${util >= 90 ? "bg-red-500" : util >= 60 ? "bg-yellow-500" : "bg-green-500"}`}
+                              style={{ width: `${Math.min(util, 100)}%` }}
+                            />
+                          </div>
+                          <span>{util.toFixed(1)}%</span>
+                        </div>
+                      </td>
+                    </tr>
+                  );
+                })}
+              </tbody>
+            </table>
+          </div>
+        </section>
+      )}
+
+      {/* Procesos */}
+      {procs && procs.processes.length > 0 && (
+        <section className="rounded-xl bg-card text-card-foreground ring-1 ring-border/50 overflow-hidden">
+          <div className="px-4 pt-4 pb-2 flex items-center justify-between text-sm text-muted-foreground">
+            <span>Procesos • {formatCL(procs.time)}</span>
+            <select
+              className="bg-background border rounded px-2 py-1 text-xs"
+              value={procSort}
+              onChange={(e) => setProcSort(e.target.value as "cpu" | "mem")}
+            >
+              <option value="cpu">Ordenar por CPU</option>
+              <option value="mem">Ordenar por memoria</option>
+            </select>
+          </div>
+          <div className="overflow-x-auto">
+            <table className="w-full text-sm">
+              <thead className="bg-muted/50 border-y border-border">
+                <tr>
+                  <th className="text-left px-4 py-2 font-medium">PID</th>
+                  <th className="text-left px-4 py-2 font-medium">Proceso</th>
+                  <th className="text-left px-4 py-2 font-medium">Usuario</th>
+                  <th className="text-right px-4 py-2 font-medium">CPU</th>
+                  <th className="text-right px-4 py-2 font-medium">Memoria</th>
+                  <th className="text-right px-4 py-2 font-medium">RSS</th>
+                  <th className="text-left px-4 py-2 font-medium">Comando</th>
+                </tr>
+              </thead>
+              <tbody>
+                {sortedProcs.map((p) => (
+                  <tr key={p.pid} className="border-b border-border last:border-0">
+                    <td className="px-4 py-2 font-mono">{p.pid}</td>
+                    <td className="px-4 py-2 font-mono">{p.name || "—"}</td>
+                    <td className="px-4 py-2">{p.user || "—"}</td>
+                    <td className="px-4 py-2 text-right">{p.cpu_pct.toFixed(1)}%</td>
+                    <td className="px-4 py-2 text-right">{p.mem_pct.toFixed(1)}%</td>
+                    <td className="px-4 py-2 text-right">{formatBytes(p.rss_bytes)}</td>
+                    <td className="px-4 py-2 font-mono text-xs text-muted-foreground max-w-md truncate" title={p.cmdline}>
+                      {p.cmdline || "—"}
+                    </td>
+                  </tr>
+                ))}
+              </tbody>
+            </table>
+          </div>
+        </section>
+      )}
+
       {/* Gráfico */}
       <section className="rounded-xl bg-card text-card-foreground p-4 ring-1 ring-border/50">
         <div className="mb-2 text-sm text-muted-foreground">{subtitle}</div>
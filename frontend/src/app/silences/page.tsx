@@ -0,0 +1,597 @@
+"use client";
+
+import { useEffect, useState } from "react";
+import { useRouter } from "next/navigation";
+import {
+    getSilences,
+    createSilence,
+    updateSilence,
+    deleteSilence,
+    getDevices,
+    Silence,
+    SilenceInput,
+} from "@/lib/api/api";
+import { InformationCircleIcon } from "@heroicons/react/24/outline";
+import { useNotification } from "@/context/NotificationContext";
+
+const WEEKDAYS = ["Dom", "Lun", "Mar", "Mié", "Jue", "Vie", "Sáb"];
+
+const METRICS = [
+    { value: "", label: "Todas" },
+    { value: "cpu", label: "CPU Usage" },
+    { value: "ram", label: "RAM Usage" },
+    { value: "disk", label: "Disk Usage" },
+    { value: "temp", label: "Temperature" },
+    { value: "net_rx_rate", label: "Network RX/s" },
+    { value: "net_tx_rate", label: "Network TX/s" },
+    { value: "uptime", label: "Uptime" },
+    { value: "reboot", label: "Reboot" },
+    { value: "cpu_core_max", label: "CPU Core Max" },
+    { value: "heartbeat", label: "Heartbeat" },
+    { value: "composite", label: "Compuesta (lógica)" },
+];
+
+interface SilenceForm {
+    comment: string;
+    device: string;
+    metric: string;
+    ruleId: string;
+    topologyId: string;
+    global: boolean;
+    startsAt: string;
+    endsAt: string;
+    recurring: boolean;
+    weekdays: number[];
+    from: string;
+    to: string;
+    timezone: string;
+}
+
+// datetime-local trabaja en hora local sin zona
+function toLocalInput(iso?: string) {
+    if (!iso) return "";
+    const d = new Date(iso);
+    const pad = (n: number) => String(n).padStart(2, "0");
+    return `${d.getFullYear()}-${pad(d.getMonth() + 1)}-${pad(d.getDate())}T${pad(d.getHours())}:${pad(d.getMinutes())}`;
+}
+
+function emptyForm(): SilenceForm {
+    const now = new Date();
+    return {
+        comment: "",
+        device: "",
+        metric: "",
+        ruleId: "",
+        topologyId: "",
+        global: false,
+        startsAt: toLocalInput(now.toISOString()),
+        endsAt: toLocalInput(new Date(now.getTime() + 2 * 60 * 60 * 1000).toISOString()),
+        recurring: false,
+        weekdays: [],
+        from: "02:00",
+        to: "04:00",
+        timezone: Intl.DateTimeFormat().resolvedOptions().timeZone || "UTC",
+    };
+}
+
+function formFromSilence(s: Silence): SilenceForm {
+    const recurring = !!(s.from || s.to);
+    return {
+        comment: s.comment || "",
+        device: s.device || "",
+        metric: s.metric || "",
+        ruleId: s.rule_id || "",
+        topologyId: s.topology_id ? String(s.topology_id) : "",
+        global: s.global,
+        startsAt: toLocalInput(s.starts_at),
+        endsAt: toLocalInput(s.ends_at),
+        recurring,
+        weekdays: s.weekdays || [],
+        from: s.from || "02:00",
+        to: s.to || "04:00",
+        timezone: s.timezone || "UTC",
+    };
+}
+
+function silenceFromForm(f: SilenceForm): SilenceInput {
+    const input: SilenceInput = {
+        global: f.global,
+        comment: f.comment.trim() || undefined,
+        device: f.device.trim() || undefined,
+        metric: f.metric || undefined,
+        rule_id: f.ruleId.trim() || undefined,
+        topology_id: f.topologyId ? Number(f.topologyId) : undefined,
+        starts_at: new Date(f.startsAt).toISOString(),
+        ends_at: f.endsAt ? new Date(f.endsAt).toISOString() : undefined,
+    };
+    if (f.recurring) {
+        input.weekdays = f.weekdays.length > 0 ? [...f.weekdays].sort() : undefined;
+        input.from = f.from;
+        input.to = f.to;
+        input.timezone = f.timezone;
+    }
+    return input;
+}
+
+function formatDate(iso?: string) {
+    if (!iso) return "—";
+    return new Date(iso).toLocaleDateString("es-CL", {
+        year: "numeric",
+        month: "short",
+        day: "numeric",
+        hour: "2-digit",
+        minute: "2-digit",
+    });
+}
+
+function describeMatchers(s: Silence) {
+    const parts: string[] = [];
+    if (s.device) parts.push(`dispositivo=${s.device}`);
+    if (s.metric) parts.push(`métrica=${s.metric}`);
+    if (s.rule_id) parts.push(`regla=${s.rule_id}`);
+    if (s.topology_id) parts.push(`topología=${s.topology_id}`);
+    return parts.length > 0 ? parts.join(", ") : "Todas las alertas";
+}
+
+function describeSchedule(s: Silence) {
+    if (!s.from && !s.to) return "Único";
+    const days = s.weekdays && s.weekdays.length > 0
+        ? s.weekdays.map((d) => WEEKDAYS[d]).join(", ")
+        : "Todos los días";
+    return `${days} ${s.from}–${s.to} (${s.timezone || "UTC"})`;
+}
+
+function isExpired(s: Silence) {
+    return !!s.ends_at && new Date(s.ends_at).getTime() <= Date.now();
+}
+
+export default function SilencesPage() {
+    const router = useRouter();
+    const [jwt, setJwt] = useState<string | null>(null);
+    const [silences, setSilences] = useState<Silence[]>([]);
+    const [devices, setDevices] = useState<string[]>([]);
+    const [loading, setLoading] = useState(false);
+    const [error, setError] = useState("");
+    const { notify } = useNotification();
+
+    // Modal state
+    const [showModal, setShowModal] = useState(false);
+    const [editingId, setEditingId] = useState<number | null>(null);
+    const [form, setForm] = useState<SilenceForm>(emptyForm());
+
+    // Authentication check
+    useEffect(() => {
+        const t = localStorage.getItem("jwt");
+        if (!t) {
+            router.replace("/auth/login");
+            return;
+        }
+        setJwt(t);
+    }, [router]);
+
+    // Load silences and devices
+    useEffect(() => {
+        if (!jwt) return;
+
+        const loadData = async () => {
+            setLoading(true);
+            setError("");
+            try {
+                const [silencesData, devicesData] = await Promise.all([
+                    getSilences(jwt),
+                    getDevices(jwt),
+                ]);
+                setSilences(silencesData || []);
+                setDevices(devicesData || []);
+            } catch (e: any) {
+                setError(e?.message || "Error loading data");
+            } finally {
+                setLoading(false);
+            }
+        };
+
+        loadData();
+    }, [jwt]);
+
+    const reloadSilences = async () => {
+        if (!jwt) return;
+        const data = await getSilences(jwt);
+        setSilences(data || []);
+    };
+
+    const openCreateModal = () => {
+        setEditingId(null);
+        setForm(emptyForm());
+        setShowModal(true);
+    };
+
+    const openEditModal = (s: Silence) => {
+        setEditingId(s.id);
+        setForm(formFromSilence(s));
+        setShowModal(true);
+    };
+
+    const closeModal = () => {
+        setShowModal(false);
+        setEditingId(null);
+    };
+
+    const handleSave = async () => {
+        if (!jwt) return;
+
+        setLoading(true);
+        setError("");
+        try {
+            const input = silenceFromForm(form);
+            if (editingId !== null) {
+                await updateSilence(jwt, editingId, input);
+                notify("Silencio actualizado", "success");
+            } else {
+                await createSilence(jwt, input);
+                notify("Silencio creado", "success");
+            }
+            closeModal();
+            await reloadSilences();
+        } catch (e: any) {
+            notify(e?.message || "Error guardando silencio", "error");
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    // Terminar ahora: un silencio único expira, una ventana deja de abrirse
+    const handleExpire = async (s: Silence) => {
+        if (!jwt) return;
+
+        setLoading(true);
+        setError("");
+        try {
+            const input = silenceFromForm(formFromSilence(s));
+            input.ends_at = new Date(Math.max(Date.now(), new Date(s.starts_at).getTime() + 1000)).toISOString();
+            await updateSilence(jwt, s.id, input);
+            await reloadSilences();
+        } catch (e: any) {
+            setError(e?.message || "Error expiring silence");
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    const handleDelete = async (id: number) => {
+        if (!jwt || !confirm("¿Estás seguro de eliminar este silencio?")) return;
+
+        setLoading(true);
+        setError("");
+        try {
+            await deleteSilence(jwt, id);
+            await reloadSilences();
+        } catch (e: any) {
+            setError(e?.message || "Error deleting silence");
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    const toggleWeekday = (d: number) => {
+        setForm((f) => ({
+            ...f,
+            weekdays: f.weekdays.includes(d) ? f.weekdays.filter((w) => w !== d) : [...f.weekdays, d],
+        }));
+    };
+
+    const canSave = !!form.startsAt && (form.recurring ? !!form.from && !!form.to : !!form.endsAt);
+
+    return (
+        <div className="container mx-auto px-4 py-6 space-y-6">
+            <header className="flex justify-between items-center">
+                <div>
+                    <h1 className="text-2xl font-semibold">Silencios y Mantenimiento</h1>
+                    <p className="text-sm text-muted-foreground mt-1">
+                        Pausa las notificaciones durante intervenciones planificadas
+                    </p>
+                </div>
+                <button
+                    onClick={openCreateModal}
+                    className="px-4 py-2 bg-primary text-primary-foreground rounded-lg hover:bg-primary/90 transition-colors"
+                >
+                    + Crear Silencio
+                </button>
+            </header>
+
+            {error && (
+                <div className="rounded-lg bg-destructive/10 text-destructive px-4 py-3">
+                    {error}
+                </div>
+            )}
+
+            {/* Silences List */}
+            <div className="rounded-xl bg-card border border-border overflow-hidden">
+                <div className="overflow-x-auto">
+                    <table className="w-full">
+                        <thead className="bg-muted/50 border-b border-border">
+                            <tr>
+                                <th className="text-left px-4 py-3 text-sm font-medium">Alcance</th>
+                                <th className="text-left px-4 py-3 text-sm font-medium">Horario</th>
+                                <th className="text-left px-4 py-3 text-sm font-medium">Desde</th>
+                                <th className="text-left px-4 py-3 text-sm font-medium">Hasta</th>
+                                <th className="text-left px-4 py-3 text-sm font-medium">Autor</th>
+                                <th className="text-right px-4 py-3 text-sm font-medium">Acciones</th>
+                            </tr>
+                        </thead>
+                        <tbody>
+                            {loading && silences.length === 0 ? (
+                                <tr>
+                                    <td colSpan={6} className="text-center py-8 text-muted-foreground">
+                                        Cargando...
+                                    </td>
+                                </tr>
+                            ) : silences.length === 0 ? (
+                                <tr>
+                                    <td colSpan={6} className="text-center py-8 text-muted-foreground">
+                                        No hay silencios. Crea uno antes de tu próxima intervención.
+                                    </td>
+                                </tr>
+                            ) : (
+                                silences.map((s) => (
+                                    <tr
+                                        key={s.id}
+                                        className={`border-b border-border last:border-0 hover:bg-muted/30 transition-colors ${isExpired(s) ? "opacity-60" : ""}`}
+                                    >
+                                        <td className="px-4 py-3">
+                                            <div className="font-medium text-sm">{describeMatchers(s)}</div>
+                                            {s.comment && (
+                                                <div className="text-xs text-muted-foreground">{s.comment}</div>
+                                            )}
+                                            {s.global && (
+                                                <span className="inline-flex mt-1 px-2 py-0.5 text-xs bg-primary/10 text-primary rounded-md font-medium">
+                                                    global
+                                                </span>
+                                            )}
+                                        </td>
+                                        <td className="px-4 py-3 text-sm">{describeSchedule(s)}</td>
+                                        <td className="px-4 py-3 text-sm text-muted-foreground">{formatDate(s.starts_at)}</td>
+                                        <td className="px-4 py-3 text-sm text-muted-foreground">
+                                            {s.ends_at ? formatDate(s.ends_at) : "Indefinido"}
+                                            {isExpired(s) && <span className="ml-2 text-xs">(expirado)</span>}
+                                        </td>
+                                        <td className="px-4 py-3 text-sm text-muted-foreground">{s.author}</td>
+                                        <td className="px-4 py-3 text-right whitespace-nowrap">
+                                            <button
+                                                onClick={() => openEditModal(s)}
+                                                disabled={loading}
+                                                className="px-3 py-1 text-sm hover:bg-muted rounded transition-colors disabled:opacity-50"
+                                            >
+                                                Editar
+                                            </button>
+                                            {!isExpired(s) && (
+                                                <button
+                                                    onClick={() => handleExpire(s)}
+                                                    disabled={loading}
+                                                    className="px-3 py-1 text-sm hover:bg-muted rounded transition-colors disabled:opacity-50"
+                                                >
+                                                    Terminar
+                                                </button>
+                                            )}
+                                            <button
+                                                onClick={() => handleDelete(s.id)}
+                                                disabled={loading}
+                                                className="px-3 py-1 text-sm text-destructive hover:bg-destructive/10 rounded transition-colors disabled:opacity-50"
+                                            >
+                                                Eliminar
+                                            </button>
+                                        </td>
+                                    </tr>
+                                ))
+                            )}
+                        </tbody>
+                    </table>
+                </div>
+            </div>
+
+            {/* Info Card */}
+            <div className="rounded-lg bg-blue-500/10 border border-blue-500/20 p-4">
+                <h3 className="font-medium text-blue-600 dark:text-blue-400 mb-2 flex items-center gap-2">
+                    <InformationCircleIcon className="w-5 h-5" />
+                    Información
+                </h3>
+                <ul className="text-sm text-muted-foreground space-y-1">
+                    <li>• Las alertas silenciadas se siguen registrando, pero no se notifican</li>
+                    <li>• Si la alerta sigue activa al terminar el silencio, se notifica en ese momento</li>
+                    <li>• Los filtros vacíos coinciden con cualquier dispositivo, métrica o regla</li>
+                    <li>• Una ventana de mantenimiento se abre cada semana en los días y horas indicados</li>
+                    <li>• Sólo un administrador puede crear silencios globales</li>
+                </ul>
+            </div>
+
+            {/* Create / Edit Modal */}
+            {showModal && (
+                <div className="fixed inset-0 bg-black/50 flex items-center justify-center z-50 p-4">
+                    <div className="bg-card border border-border rounded-lg p-6 w-full max-w-lg space-y-4 max-h-[90vh] overflow-y-auto">
+                        <h3 className="text-lg font-semibold">
+                            {editingId !== null ? "Editar Silencio" : "Crear Silencio"}
+                        </h3>
+                        <div className="space-y-4">
+                            <div className="space-y-2">
+                                <label className="text-sm font-medium">Comentario</label>
+                                <input
+                                    type="text"
+                                    placeholder="Ej: Actualización del servidor"
+                                    value={form.comment}
+                                    onChange={(e) => setForm({ ...form, comment: e.target.value })}
+                                    className="w-full px-3 py-2 bg-background border border-border rounded-md text-sm"
+                                    autoFocus
+                                />
+                            </div>
+
+                            <div className="grid grid-cols-2 gap-3">
+                                <div className="space-y-2">
+                                    <label className="text-sm font-medium">Dispositivo</label>
+                                    <input
+                                        type="text"
+                                        list="silence-devices-list"
+                                        placeholder="Todos"
+                                        value={form.device}
+                                        onChange={(e) => setForm({ ...form, device: e.target.value })}
+                                        className="w-full px-3 py-2 bg-background border border-border rounded-md text-sm"
+                                    />
+                                    <datalist id="silence-devices-list">
+                                        {devices.map((device) => (
+                                            <option key={device} value={device} />
+                                        ))}
+                                    </datalist>
+                                </div>
+                                <div className="space-y-2">
+                                    <label className="text-sm font-medium">Métrica</label>
+                                    <select
+                                        value={form.metric}
+                                        onChange={(e) => setForm({ ...form, metric: e.target.value })}
+                                        className="w-full px-3 py-2 bg-background border border-border rounded-md text-sm"
+                                    >
+                                        {METRICS.map((m) => (
+                                            <option key={m.value} value={m.value}>{m.label}</option>
+                                        ))}
+                                    </select>
+                                </div>
+                                <div className="space-y-2">
+                                    <label className="text-sm font-medium">ID de Topología</label>
+                                    <input
+                                        type="number"
+                                        min={1}
+                                        placeholder="Todas"
+                                        value={form.topologyId}
+                                        onChange={(e) => setForm({ ...form, topologyId: e.target.value })}
+                                        className="w-full px-3 py-2 bg-background border border-border rounded-md text-sm"
+                                    />
+                                </div>
+                                <div className="space-y-2">
+                                    <label className="text-sm font-medium">ID de Regla</label>
+                                    <input
+                                        type="text"
+                                        placeholder="Todas"
+                                        value={form.ruleId}
+                                        onChange={(e) => setForm({ ...form, ruleId: e.target.value })}
+                                        className="w-full px-3 py-2 bg-background border border-border rounded-md text-sm"
+                                    />
+                                </div>
+                            </div>
+
+                            <div className="grid grid-cols-2 gap-3">
+                                <div className="space-y-2">
+                                    <label className="text-sm font-medium">Desde</label>
+                                    <input
+                                        type="datetime-local"
+                                        value={form.startsAt}
+                                        onChange={(e) => setForm({ ...form, startsAt: e.target.value })}
+                                        className="w-full px-3 py-2 bg-background border border-border rounded-md text-sm"
+                                    />
+                                </div>
+                                <div className="space-y-2">
+                                    <label className="text-sm font-medium">Hasta</label>
+                                    <input
+                                        type="datetime-local"
+                                        value={form.endsAt}
+                                        onChange={(e) => setForm({ ...form, endsAt: e.target.value })}
+                                        className="w-full px-3 py-2 bg-background border border-border rounded-md text-sm"
+                                    />
+                                    {form.recurring && (
+                                        <p className="text-xs text-muted-foreground">Vacío: la ventana se repite indefinidamente.</p>
+                                    )}
+                                </div>
+                            </div>
+
+                            <label className="flex items-center gap-2 text-sm">
+                                <input
+                                    type="checkbox"
+                                    checked={form.recurring}
+                                    onChange={(e) => setForm({ ...form, recurring: e.target.checked })}
+                                />
+                                Ventana de mantenimiento recurrente
+                            </label>
+
+                            {form.recurring && (
+                                <div className="space-y-3 rounded-md border border-border p-3">
+                                    <div className="space-y-2">
+                                        <label className="text-sm font-medium">Días</label>
+                                        <div className="flex flex-wrap gap-1">
+                                            {WEEKDAYS.map((label, d) => (
+                                                <button
+                                                    key={d}
+                                                    type="button"
+                                                    onClick={() => toggleWeekday(d)}
+                                                    className={`px-2 py-1 text-xs rounded border transition-colors ${form.weekdays.includes(d)
+                                                        ? "bg-primary text-primary-foreground border-primary"
+                                                        : "bg-background border-border hover:bg-muted"
+                                                        }`}
+                                                >
+                                                    {label}
+                                                </button>
+                                            ))}
+                                        </div>
+                                        <p className="text-xs text-muted-foreground">Sin días seleccionados se abre todos los días.</p>
+                                    </div>
+                                    <div className="grid grid-cols-3 gap-3">
+                                        <div className="space-y-2">
+                                            <label className="text-sm font-medium">Inicio</label>
+                                            <input
+                                                type="time"
+                                                value={form.from}
+                                                onChange={(e) => setForm({ ...form, from: e.target.value })}
+                                                className="w-full px-3 py-2 bg-background border border-border rounded-md text-sm"
+                                            />
+                                        </div>
+                                        <div className="space-y-2">
+                                            <label className="text-sm font-medium">Fin</label>
+                                            <input
+                                                type="time"
+                                                value={form.to}
+                                                onChange={(e) => setForm({ ...form, to: e.target.value })}
+                                                className="w-full px-3 py-2 bg-background border border-border rounded-md text-sm"
+                                            />
+                                        </div>
+                                        <div className="space-y-2">
+                                            <label className="text-sm font-medium">Zona horaria</label>
+                                            <input
+                                                type="text"
+                                                placeholder="America/Santiago"
+                                                value={form.timezone}
+                                                onChange={(e) => setForm({ ...form, timezone: e.target.value })}
+                                                className="w-full px-3 py-2 bg-background border border-border rounded-md text-sm"
+                                            />
+                                        </div>
+                                    </div>
+                                    <p className="text-xs text-muted-foreground">
+                                        Si el fin es anterior al inicio, la ventana cruza la medianoche.
+                                    </p>
+                                </div>
+                            )}
+
+                            <label className="flex items-center gap-2 text-sm">
+                                <input
+                                    type="checkbox"
+                                    checked={form.global}
+                                    onChange={(e) => setForm({ ...form, global: e.target.checked })}
+                                />
+                                Global (todas las topologías de todos los usuarios, sólo administradores)
+                            </label>
+                        </div>
+                        <div className="flex gap-2">
+                            <button
+                                onClick={closeModal}
+                                className="flex-1 px-4 py-2 bg-background hover:bg-accent border border-border rounded text-sm"
+                            >
+                                Cancelar
+                            </button>
+                            <button
+                                onClick={handleSave}
+                                disabled={loading || !canSave}
+                                className="flex-1 px-4 py-2 bg-primary text-primary-foreground hover:bg-primary/90 rounded text-sm disabled:opacity-50"
+                            >
+                                {loading ? "Guardando..." : "Guardar"}
+                            </button>
+                        </div>
+                    </div>
+                </div>
+            )}
+        </div>
+    );
+}
@@ -2,7 +2,7 @@
 
 import { useEffect, useState } from "react";
 import { useRouter } from "next/navigation";
-import { createAPIToken, getAPITokens, deleteAPIToken, APIToken } from "@/lib/api/tokens";
+import { createAPIToken, getAPITokens, deleteAPIToken, APIToken, TokenKind } from "@/lib/api/tokens";
 import { getDevices } from "@/lib/api/api";
 import { InformationCircleIcon, CheckCircleIcon, ClipboardDocumentIcon } from "@heroicons/react/24/outline";
 import { useNotification } from "@/context/NotificationContext";
@@ -20,6 +20,7 @@ export default function TokensPage() {
     const [showCreateModal, setShowCreateModal] = useState(false);
     const [newTokenName, setNewTokenName] = useState("");
     const [selectedDevice, setSelectedDevice] = useState("");
+    const [tokenKind, setTokenKind] = useState<TokenKind>("device");
     const [createdToken, setCreatedToken] = useState<string | null>(null);
 
     // Authentication check
@@ -62,10 +63,11 @@ export default function TokensPage() {
         setLoading(true);
         setError("");
         try {
-            const result = await createAPIToken(jwt, newTokenName.trim(), selectedDevice);
+            const result = await createAPIToken(jwt, newTokenName.trim(), selectedDevice, tokenKind);
             setCreatedToken(result.token);
             setNewTokenName("");
             setSelectedDevice("");
+            setTokenKind("device");
 
             // Reload tokens
             const tokensData = await getAPITokens(jwt);
@@ -107,6 +109,7 @@ export default function TokensPage() {
         setCreatedToken(null);
         setNewTokenName("");
         setSelectedDevice("");
+        setTokenKind("device");
     };
 
     return (
@@ -170,6 +173,9 @@ export default function TokensPage() {
                                             <span className="inline-flex items-center gap-1 px-2 py-1 bg-primary/10 text-primary rounded-md font-medium">
                                                 {token.DeviceName}
                                             </span>
+                                            {token.Kind === "fleet" && (
+                                                <span className="ml-2 text-xs text-muted-foreground">flota</span>
+                                            )}
                                         </td>
                                         <td className="px-4 py-3 text-sm text-muted-foreground">
                                             {new Date(token.CreatedAt).toLocaleDateString('es-CL', {
@@ -205,6 +211,7 @@ export default function TokensPage() {
                 </h3>
                 <ul className="text-sm text-muted-foreground space-y-1">
                     <li>• Cada token está asociado a un dispositivo específico</li>
+                    <li>• Un token de flota acepta un patrón (ej. web-*) y puede reportar por varios dispositivos</li>
                     <li>• Los tokens permiten que tus dispositivos envíen métricas al sistema</li>
                     <li>• Guarda el token en un lugar seguro, solo se muestra una vez al crearlo</li>
                     <li>• Puedes eliminar tokens que ya no uses</li>
@@ -231,12 +238,25 @@ export default function TokensPage() {
                                         />
                                     </div>
                                     <div className="space-y-2">
-                                        <label className="text-sm font-medium">Nombre del Dispositivo</label>
+                                        <label className="text-sm font-medium">Tipo de Token</label>
+                                        <select
+                                            value={tokenKind}
+                                            onChange={(e) => setTokenKind(e.target.value as TokenKind)}
+                                            className="w-full px-3 py-2 bg-background border border-border rounded-md text-sm"
+                                        >
+                                            <option value="device">Dispositivo único</option>
+                                            <option value="fleet">Flota (patrón)</option>
+                                        </select>
+                                    </div>
+                                    <div className="space-y-2">
+                                        <label className="text-sm font-medium">
+                                            {tokenKind === "fleet" ? "Patrón de Dispositivos" : "Nombre del Dispositivo"}
+                                        </label>
                                         <div className="relative">
                                             <input
                                                 type="text"
                                                 list="devices-list"
-                                                placeholder="Ej: servidor-linux-01"
+                                                placeholder={tokenKind === "fleet" ? "Ej: servidor-linux-*" : "Ej: servidor-linux-01"}
                                                 value={selectedDevice}
                                                 onChange={(e) => setSelectedDevice(e.target.value)}
                                                 className="w-full px-3 py-2 bg-background border border-border rounded-md text-sm"
@@ -20,7 +20,7 @@ import "@xyflow/react/dist/style.css";
 import DeviceNode, { DeviceNodeData } from "@/components/topology/DeviceNode";
 import RouterNode, { RouterNodeData } from "@/components/topology/RouterNode";
 import TopologyControls from "@/components/topology/TopologyControls";
-import { getDevices, getLastStats, getRecentAlerts } from "@/lib/api/api";
+import { getDevices, getLastStats, getActiveAlerts } from "@/lib/api/api";
 import {
     saveTopology,
     getTopologies,
@@ -32,7 +32,10 @@ import {
 import MonitoringNode, { MonitoringNodeData } from "@/components/topology/MonitoringNode";
 import ActionNode, { ActionNodeData } from "@/components/topology/ActionNode";
 import EmailNode, { EmailNodeData } from "@/components/topology/EmailNode";
+import WebhookNode, { WebhookNodeData } from "@/components/topology/WebhookNode";
+import ChatNode, { ChatChannel, ChatNodeData } from "@/components/topology/ChatNode";
 import NotificationNode, { NotificationNodeData } from "@/components/topology/NotificationNode";
+import LogicNode, { LogicNodeData, LogicOperator } from "@/components/topology/LogicNode";
 import { useNotification } from "@/context/NotificationContext";
 
 const nodeTypes = {
@@ -41,9 +44,22 @@ const nodeTypes = {
     monitoring: MonitoringNode,
     action: ActionNode,
     email: EmailNode,
+    webhook: WebhookNode,
+    slack: ChatNode,
+    discord: ChatNode,
+    ntfy: ChatNode,
     notification: NotificationNode,
+    and: LogicNode,
+    or: LogicNode,
+    not: LogicNode,
 };
 
+// Nodos que reciben la alerta de una regla de disparo
+const OUTPUT_NODE_TYPES = ['email', 'webhook', 'slack', 'discord', 'ntfy'];
+
+// Nodos que combinan condiciones de acciones; disparan como una regla más
+const LOGIC_NODE_TYPES = ['and', 'or', 'not'];
+
 function TopologyEditor() {
     const router = useRouter();
     const { fitView, screenToFlowPosition } = useReactFlow();
@@ -227,25 +243,30 @@ function TopologyEditor() {
         return () => clearInterval(interval);
     }, [jwt, setNodes, setEdges, autoDetectGateways]);
 
-    // Polling de alertas recientes para confirmación visual
+    // Polling de alertas en estado firing para confirmación visual
     useEffect(() => {
         if (!jwt) return;
 
         const checkAlerts = async () => {
             try {
-                const recentAlerts = await getRecentAlerts(jwt);
+                const firing = await getActiveAlerts(jwt, "firing");
+                const topologyAlerts = firing
+                    .filter((a) => !selectedTopology || a.topology_id === selectedTopology);
+                const recentAlerts = topologyAlerts.map((a) => a.rule_id);
+                // Las alertas retenidas por un dispositivo aguas arriba caído no llegan a las salidas
+                const notifiedAlerts = topologyAlerts.filter((a) => !a.held_by && !a.silenced_by).map((a) => a.rule_id);
 
                 if (recentAlerts.length > 0) {
                     setNodes((nds) => nds.map((n) => {
-                        // Si es un nodo de acción y está en la lista de alertas recientes
-                        if (n.type === 'action' && recentAlerts.includes(n.id)) {
+                        // Si es un nodo de acción (o lógico) y está en la lista de alertas recientes
+                        if ((n.type === 'action' || LOGIC_NODE_TYPES.includes(n.type as string)) && recentAlerts.includes(n.id)) {
                             return { ...n, data: { ...n.data, isActive: true } };
                         }
 
-                        // Si es un nodo de email conectado a una acción activa
-                        if (n.type === 'email') {
+                        // Si es un nodo de salida (email/webhook/chat) conectado a una acción activa
+                        if (OUTPUT_NODE_TYPES.includes(n.type as string)) {
                             const isConnectedToActiveAction = edgesRef.current.some(e =>
-                                e.target === n.id && recentAlerts.includes(e.source)
+                                e.target === n.id && notifiedAlerts.includes(e.source)
                             );
 
                             if (isConnectedToActiveAction) {
@@ -258,7 +279,7 @@ function TopologyEditor() {
                         // Notification Node Logic
                         if (n.type === 'notification') {
                             const isConnectedToActiveAction = edgesRef.current.some(e =>
-                                e.target === n.id && recentAlerts.includes(e.source)
+                                e.target === n.id && notifiedAlerts.includes(e.source)
                             );
 
                             if (isConnectedToActiveAction) {
@@ -278,7 +299,7 @@ function TopologyEditor() {
                 } else {
                     // Reset active state if no alerts
                     setNodes((nds) => nds.map((n) => {
-                        if ((n.type === 'action' || n.type === 'email' || n.type === 'notification') && n.data.isActive) {
+                        if ((n.type === 'action' || LOGIC_NODE_TYPES.includes(n.type as string) || OUTPUT_NODE_TYPES.includes(n.type as string) || n.type === 'notification') && n.data.isActive) {
                             return { ...n, data: { ...n.data, isActive: false } };
                         }
                         return n;
@@ -291,7 +312,7 @@ function TopologyEditor() {
 
         const interval = setInterval(checkAlerts, 3000);
         return () => clearInterval(interval);
-    }, [jwt, setNodes, notify]);
+    }, [jwt, setNodes, notify, selectedTopology]);
 
     const onConnect = useCallback(
         (params: Connection) => setEdges((eds) => addEdge(params, eds)),
@@ -368,6 +389,44 @@ function TopologyEditor() {
         setNodes((nds) => [...nds, newNode]);
     }, [setNodes]);
 
+    const handleAddWebhookNode = useCallback(() => {
+        const id = `webhook-${++nodeIdCounter.current}`;
+        const newNode: Node<WebhookNodeData> = {
+            id,
+            type: "webhook",
+            position: { x: Math.random() * 400 + 100, y: Math.random() * 400 + 100 },
+            data: {
+                url: '',
+                method: 'POST',
+            },
+        };
+        setNodes((nds) => [...nds, newNode]);
+    }, [setNodes]);
+
+    const handleAddChatNode = useCallback((channel: ChatChannel) => {
+        const id = `${channel}-${++nodeIdCounter.current}`;
+        const newNode: Node<ChatNodeData> = {
+            id,
+            type: channel,
+            position: { x: Math.random() * 400 + 100, y: Math.random() * 400 + 100 },
+            data: {
+                url: '',
+            },
+        };
+        setNodes((nds) => [...nds, newNode]);
+    }, [setNodes]);
+
+    const handleAddLogicNode = useCallback((op: LogicOperator) => {
+        const id = `${op}-${++nodeIdCounter.current}`;
+        const newNode: Node<LogicNodeData> = {
+            id,
+            type: op,
+            position: { x: Math.random() * 400 + 100, y: Math.random() * 400 + 100 },
+            data: {},
+        };
+        setNodes((nds) => [...nds, newNode]);
+    }, [setNodes]);
+
     const handleAddNotificationNode = useCallback(() => {
         const id = `notif-${++nodeIdCounter.current}`;
         const newNode: Node<NotificationNodeData> = {
@@ -439,6 +498,38 @@ function TopologyEditor() {
                     },
                 };
                 setNodes((nds) => [...nds, newNode]);
+            } else if (type === 'webhook') {
+                const id = `webhook-${++nodeIdCounter.current}`;
+                const newNode: Node<WebhookNodeData> = {
+                    id,
+                    type: "webhook",
+                    position,
+                    data: {
+                        url: '',
+                        method: 'POST',
+                    },
+                };
+                setNodes((nds) => [...nds, newNode]);
+            } else if (type === 'slack' || type === 'discord' || type === 'ntfy') {
+                const id = `${type}-${++nodeIdCounter.current}`;
+                const newNode: Node<ChatNodeData> = {
+                    id,
+                    type,
+                    position,
+                    data: {
+                        url: '',
+                    },
+                };
+                setNodes((nds) => [...nds, newNode]);
+            } else if (LOGIC_NODE_TYPES.includes(type)) {
+                const id = `${type}-${++nodeIdCounter.current}`;
+                const newNode: Node<LogicNodeData> = {
+                    id,
+                    type,
+                    position,
+                    data: {},
+                };
+                setNodes((nds) => [...nds, newNode]);
             } else if (type === 'notification') {
                 const id = `notif-${++nodeIdCounter.current}`;
                 const newNode: Node<NotificationNodeData> = {
@@ -495,12 +586,25 @@ function TopologyEditor() {
                         deviceName: (n.data as any).deviceName,
                         label: (n.data as any).label,
                         metric: (n.data as any).metric,
+                        instance: (n.data as any).instance,
                         operator: (n.data as any).operator,
                         threshold: (n.data as any).threshold,
+                        window: (n.data as any).window,
+                        aggregation: (n.data as any).aggregation,
+                        for: (n.data as any).for,
                         to: (n.data as any).to,
                         subject: (n.data as any).subject,
                         body: (n.data as any).body,
+                        html: (n.data as any).html,
+                        cc: (n.data as any).cc,
+                        bcc: (n.data as any).bcc,
                         cooldown: (n.data as any).cooldown,
+                        url: (n.data as any).url,
+                        method: (n.data as any).method,
+                        headers: (n.data as any).headers,
+                        secret: (n.data as any).secret,
+                        timeout: (n.data as any).timeout,
+                        retries: (n.data as any).retries,
                         message: (n.data as any).message, // Save notification message
                     },
                 })),
@@ -687,6 +791,9 @@ function TopologyEditor() {
                     onAddMonitoringNode={handleAddMonitoringNode}
                     onAddActionNode={handleAddActionNode}
                     onAddEmailNode={handleAddEmailNode}
+                    onAddWebhookNode={handleAddWebhookNode}
+                    onAddChatNode={handleAddChatNode}
+                    onAddLogicNode={handleAddLogicNode}
                     onAddNotificationNode={handleAddNotificationNode}
                     selectedNode={selectedNode}
                     onUpdateNodeData={handleUpdateNodeData}
@@ -12,8 +12,10 @@ const FIELDS = [
   { v:"cpu",    l:"CPU %" },
   { v:"ram",    l:"RAM %" },
   { v:"disk",   l:"DISK %" },
-  { v:"net_rx", l:"Net RX (B/s)" },
-  { v:"net_tx", l:"Net TX (B/s)" },
+  { v:"net_rx", l:"Net RX (B)" },
+  { v:"net_tx", l:"Net TX (B)" },
+  { v:"net_rx_rate", l:"Net RX (B/s)" },
+  { v:"net_tx_rate", l:"Net TX (B/s)" },
   { v:"temp",   l:"Temp (°C)" },
   { v:"uptime", l:"Uptime (s)" },
 ];
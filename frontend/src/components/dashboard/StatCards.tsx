@@ -3,7 +3,7 @@
 export default function StatCards({ stats }:{ stats: Record<string, number> }){
   const order = [
     ["cpu","CPU %"],["ram","RAM %"],["disk","DISK %"],
-    ["net_rx","Net RX (B/s)"],["net_tx","Net TX (B/s)"],
+    ["net_rx_rate","Net RX (B/s)"],["net_tx_rate","Net TX (B/s)"],
     ["temp","Temp (°C)"],["uptime","Uptime (s)"],
   ] as const;
   return (
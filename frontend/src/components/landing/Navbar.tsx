@@ -12,6 +12,7 @@ import {
   Squares2X2Icon,
   ShareIcon,
   BellIcon,
+  BellSlashIcon,
   TrashIcon,
   CheckCircleIcon
 } from "@heroicons/react/24/outline";
@@ -205,6 +206,19 @@ export const Navbar = () => {
                     )}
                   </Menu.Item>
 
+                  <Menu.Item>
+                    {({ active }) => (
+                      <Link
+                        href="/silences"
+                        className={`${active ? "bg-muted" : ""
+                          } flex items-center gap-2 px-4 py-2 text-sm text-foreground mx-1 rounded-lg transition-colors`}
+                      >
+                        <BellSlashIcon className="w-4 h-4" />
+                        Silencios
+                      </Link>
+                    )}
+                  </Menu.Item>
+
                   <div className="my-1 border-t border-border/50" />
 
                   <Menu.Item>
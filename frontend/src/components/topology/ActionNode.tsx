@@ -6,8 +6,12 @@ import { BoltIcon } from "@heroicons/react/24/outline";
 
 export interface ActionNodeData extends Record<string, unknown> {
     metric?: string;
+    instance?: string; // punto de montaje (mount_*), interfaz (iface_*) o disco (disk_*)
     operator?: string;
     threshold?: number;
+    window?: string;
+    aggregation?: string; // last, avg, min, max
+    for?: string;
     connectedDevice?: string;
     isActive?: boolean;
 }
@@ -17,6 +21,40 @@ const METRICS = [
     { value: "ram", label: "RAM Usage" },
     { value: "disk", label: "Disk Usage" },
     { value: "temp", label: "Temperature" },
+    { value: "net_rx_rate", label: "Network RX/s" },
+    { value: "net_tx_rate", label: "Network TX/s" },
+    { value: "uptime", label: "Uptime" },
+    { value: "reboot", label: "Reboot" },
+    { value: "cpu_core_max", label: "CPU Core Max" },
+    { value: "load1", label: "Load 1m" },
+    { value: "load5", label: "Load 5m" },
+    { value: "load15", label: "Load 15m" },
+    { value: "swap", label: "Swap Usage" },
+    { value: "psi_cpu_some", label: "CPU Pressure" },
+    { value: "psi_memory_some", label: "Memory Pressure" },
+    { value: "psi_memory_full", label: "Memory Pressure (full)" },
+    { value: "psi_io_some", label: "I/O Pressure" },
+    { value: "psi_io_full", label: "I/O Pressure (full)" },
+    { value: "ctx_switch_rate", label: "Context Switches/s" },
+    { value: "fork_rate", label: "Forks/s" },
+    { value: "mount_used_pct", label: "Mount Usage" },
+    { value: "mount_free_bytes", label: "Mount Free" },
+    { value: "mount_inodes_used_pct", label: "Mount Inodes" },
+    { value: "mount_read_only", label: "Mount Read-only" },
+    { value: "iface_rx_bytes_rate", label: "Interface RX/s" },
+    { value: "iface_tx_bytes_rate", label: "Interface TX/s" },
+    { value: "iface_rx_errors_rate", label: "Interface RX Errors/s" },
+    { value: "iface_tx_errors_rate", label: "Interface TX Errors/s" },
+    { value: "iface_rx_drops_rate", label: "Interface RX Drops/s" },
+    { value: "iface_tx_drops_rate", label: "Interface TX Drops/s" },
+    { value: "iface_up", label: "Interface Link" },
+    { value: "disk_read_bytes_rate", label: "Disk Read/s" },
+    { value: "disk_write_bytes_rate", label: "Disk Write/s" },
+    { value: "disk_read_iops", label: "Disk Read IOPS" },
+    { value: "disk_write_iops", label: "Disk Write IOPS" },
+    { value: "disk_await_ms", label: "Disk Await (ms)" },
+    { value: "disk_util_pct", label: "Disk Util" },
+    { value: "heartbeat", label: "Heartbeat" },
 ];
 
 const OPERATORS = [
@@ -29,7 +67,7 @@ const OPERATORS = [
 
 function ActionNode({ id, data, selected }: NodeProps) {
     const typedData = data as ActionNodeData;
-    const { connectedDevice, metric = "cpu", operator = ">=", threshold = 70, isActive } = typedData;
+    const { connectedDevice, metric = "cpu", instance, operator = ">=", threshold = 70, window = "5m", aggregation = "last", isActive } = typedData;
 
     return (
         <div
@@ -72,11 +110,27 @@ function ActionNode({ id, data, selected }: NodeProps) {
                     </div>
                 )}
 
-                <div className="flex items-center gap-2 text-xs bg-muted/30 p-2 rounded border border-border/50">
-                    <span className="font-mono font-semibold uppercase">{metric}</span>
-                    <span className="text-muted-foreground">{operator}</span>
-                    <span className="font-mono font-semibold">{threshold}</span>
-                </div>
+                {metric === "heartbeat" ? (
+                    <div className="flex items-center gap-2 text-xs bg-muted/30 p-2 rounded border border-border/50">
+                        <span className="text-muted-foreground">Sin datos por</span>
+                        <span className="font-mono font-semibold">{window}</span>
+                    </div>
+                ) : (
+                    <div className="flex items-center gap-2 text-xs bg-muted/30 p-2 rounded border border-border/50">
+                        {aggregation !== "last" ? (
+                            <span className="font-mono font-semibold">
+                                {aggregation}(<span className="uppercase">{metric}</span>) {window}
+                            </span>
+                        ) : (
+                            <span className="font-mono font-semibold uppercase">{metric}</span>
+                        )}
+                        {instance && (metric.startsWith("mount_") || metric.startsWith("iface_") || metric.startsWith("disk_")) && (
+                            <span className="font-mono text-muted-foreground">{instance}</span>
+                        )}
+                        <span className="text-muted-foreground">{operator}</span>
+                        <span className="font-mono font-semibold">{threshold}</span>
+                    </div>
+                )}
             </div>
         </div>
     );
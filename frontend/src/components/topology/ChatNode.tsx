@@ -0,0 +1,70 @@
+"use client";
+
+import { memo } from "react";
+import { Handle, Position, NodeProps } from "@xyflow/react";
+import { ChatBubbleLeftRightIcon } from "@heroicons/react/24/outline";
+
+export type ChatChannel = "slack" | "discord" | "ntfy";
+
+export const CHAT_CHANNEL_LABELS: Record<ChatChannel, string> = {
+    slack: "Slack",
+    discord: "Discord",
+    ntfy: "ntfy",
+};
+
+export interface ChatNodeData extends Record<string, unknown> {
+    url?: string; // webhook entrante (Slack/Discord) o URL del tópico (ntfy)
+    headers?: string; // una cabecera por línea: "Nombre: valor" (e.g. Authorization en ntfy)
+    timeout?: string;
+    retries?: number;
+    cooldown?: string;
+    isActive?: boolean;
+}
+
+// Un mismo componente para los tres canales de chat; el tipo de nodo define el formato.
+function ChatNode({ data, selected, type }: NodeProps) {
+    const typedData = data as ChatNodeData;
+    const { isActive, url } = typedData;
+    const label = CHAT_CHANNEL_LABELS[type as ChatChannel] ?? type;
+
+    let host = "";
+    try {
+        host = url ? new URL(url).host : "";
+    } catch {
+        host = "";
+    }
+
+    return (
+        <div
+            className={`min-w-[150px] max-w-[240px] bg-card border-2 rounded-lg shadow-lg flex flex-col overflow-hidden transition-all ${selected ? "border-primary ring-2 ring-primary/20" : "border-border"
+                } ${isActive ? "shadow-[0_0_15px_rgba(168,85,247,0.5)] border-purple-500" : ""}`}
+        >
+            {/* Input Handle (from Action) */}
+            <Handle
+                type="target"
+                position={Position.Left}
+                id="t-in"
+                className="w-3 h-3 !bg-primary"
+            />
+
+            {/* Header */}
+            <div className={`px-3 py-2 flex items-center gap-2 ${isActive ? "bg-purple-500/10" : "bg-muted/50"}`}>
+                <ChatBubbleLeftRightIcon className={`w-5 h-5 ${isActive ? "text-purple-500 animate-pulse" : "text-muted-foreground"}`} />
+                <span className="font-medium text-sm">{label}</span>
+            </div>
+
+            {/* Status Indicator */}
+            <div className="px-3 py-2 space-y-1 border-t border-border/50">
+                {host ? (
+                    <div className="text-xs text-muted-foreground truncate">{host}</div>
+                ) : (
+                    <div className="text-xs text-muted-foreground italic">
+                        Sin URL configurada
+                    </div>
+                )}
+            </div>
+        </div>
+    );
+}
+
+export default memo(ChatNode);
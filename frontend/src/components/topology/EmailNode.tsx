@@ -6,8 +6,11 @@ import { EnvelopeIcon } from "@heroicons/react/24/outline";
 
 export interface EmailNodeData extends Record<string, unknown> {
     subject?: string;
-    body?: string;
-    to?: string;
+    body?: string; // plantilla text/template
+    html?: string; // plantilla HTML opcional
+    to?: string; // uno o varios, separados por coma
+    cc?: string;
+    bcc?: string;
     cooldown?: string; // e.g., "5m", "1h"
     isActive?: boolean;
     connectedDevice?: string;
@@ -15,7 +18,8 @@ export interface EmailNodeData extends Record<string, unknown> {
 
 function EmailNode({ id, data, selected }: NodeProps) {
     const typedData = data as EmailNodeData;
-    const { isActive, to } = typedData;
+    const { isActive, to, cc, bcc } = typedData;
+    const extra = [cc, bcc].join(",").split(/[,;\n]/).filter((r) => r.trim() !== "").length;
 
     return (
         <div
@@ -50,6 +54,11 @@ function EmailNode({ id, data, selected }: NodeProps) {
                         To: {to}
                     </div>
                 )}
+                {extra > 0 && (
+                    <div className="text-[10px] text-muted-foreground">
+                        +{extra} en copia
+                    </div>
+                )}
             </div>
         </div>
     );
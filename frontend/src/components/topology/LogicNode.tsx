@@ -0,0 +1,68 @@
+"use client";
+
+import { memo } from "react";
+import { Handle, Position, NodeProps } from "@xyflow/react";
+import { Squares2X2Icon } from "@heroicons/react/24/outline";
+
+export type LogicOperator = "and" | "or" | "not";
+
+export const LOGIC_LABELS: Record<LogicOperator, string> = {
+    and: "AND",
+    or: "OR",
+    not: "NOT",
+};
+
+// Descripción corta de cada operador, para el nodo y el panel de configuración
+export const LOGIC_HINTS: Record<LogicOperator, string> = {
+    and: "Todas las condiciones de entrada",
+    or: "Cualquiera de las condiciones de entrada",
+    not: "Niega su única entrada",
+};
+
+export interface LogicNodeData extends Record<string, unknown> {
+    for?: string; // e.g., "2m"
+    isActive?: boolean;
+}
+
+// Un mismo componente para and/or/not; el tipo de nodo define el operador.
+// Recibe acciones u otros nodos lógicos y se conecta a las salidas como una acción.
+function LogicNode({ data, selected, type }: NodeProps) {
+    const typedData = data as LogicNodeData;
+    const { isActive } = typedData;
+    const op = type as LogicOperator;
+
+    return (
+        <div
+            className={`min-w-[120px] bg-card border-2 rounded-lg shadow-lg flex flex-col overflow-hidden transition-all ${selected ? "border-primary ring-2 ring-primary/20" : "border-border"
+                } ${isActive ? "shadow-[0_0_15px_rgba(239,68,68,0.5)] border-red-500" : ""}`}
+        >
+            {/* Input Handle (from Actions or other logic nodes) */}
+            <Handle
+                type="target"
+                position={Position.Left}
+                id="t-in"
+                className="w-3 h-3 !bg-primary"
+            />
+
+            {/* Header */}
+            <div className={`px-3 py-2 flex items-center gap-2 ${isActive ? "bg-red-500/10" : "bg-muted/50"}`}>
+                <Squares2X2Icon className={`w-5 h-5 ${isActive ? "text-red-500 animate-pulse" : "text-muted-foreground"}`} />
+                <span className="font-mono font-semibold text-sm">{LOGIC_LABELS[op] ?? type}</span>
+            </div>
+
+            <div className="px-3 py-2 border-t border-border/50 text-[10px] text-muted-foreground">
+                {LOGIC_HINTS[op]}
+            </div>
+
+            {/* Output Handle (to outputs or other logic nodes) */}
+            <Handle
+                type="source"
+                position={Position.Right}
+                id="s-out"
+                className="w-3 h-3 !bg-primary"
+            />
+        </div>
+    );
+}
+
+export default memo(LogicNode);
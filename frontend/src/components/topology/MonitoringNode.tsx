@@ -23,6 +23,8 @@ const METRIC_COLORS: Record<string, string> = {
     disk: "#a855f7", // purple-500
     net_rx: "#3b82f6", // blue-500
     net_tx: "#10b981", // green-500
+    net_rx_rate: "#3b82f6", // blue-500
+    net_tx_rate: "#10b981", // green-500
     temp: "#f97316", // orange-500
 };
 
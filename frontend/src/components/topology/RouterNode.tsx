@@ -17,6 +17,9 @@ function RouterNode({ data }: NodeProps) {
 
     return (
         <div className="min-w-[220px] rounded-lg bg-gradient-to-br from-blue-500/20 to-purple-500/20 border-2 border-blue-500 shadow-lg hover:shadow-xl transition-shadow">
+            {/* Entrada: el dispositivo que hace de gateway, si tiene agente */}
+            <Handle type="target" position={Position.Top} className="w-3 h-3" />
+
             <div className="p-4 space-y-2">
                 {/* Header con icono de router */}
                 <div className="flex items-center gap-2">
@@ -40,6 +43,7 @@ function RouterNode({ data }: NodeProps) {
                 )}
             </div>
 
+            {/* Salida: los dispositivos detrás de este router (sus alertas se retienen si cae) */}
             <Handle type="source" position={Position.Bottom} className="w-3 h-3" />
         </div>
     );
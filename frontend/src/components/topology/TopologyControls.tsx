@@ -1,8 +1,11 @@
 "use client";
 
 import { useState } from "react";
-import { ChartBarIcon, CheckCircleIcon, ExclamationTriangleIcon, BoltIcon, EnvelopeIcon, ChevronRightIcon, ChevronLeftIcon, BellIcon } from "@heroicons/react/24/outline";
+import { ChartBarIcon, CheckCircleIcon, ExclamationTriangleIcon, BoltIcon, EnvelopeIcon, ChevronRightIcon, ChevronLeftIcon, BellIcon, GlobeAltIcon, ChatBubbleLeftRightIcon, Squares2X2Icon } from "@heroicons/react/24/outline";
 import { useNotification } from "@/context/NotificationContext";
+import { ChatChannel, CHAT_CHANNEL_LABELS } from "./ChatNode";
+import { LogicOperator, LOGIC_LABELS, LOGIC_HINTS } from "./LogicNode";
+import type { TestNotificationRequest } from "@/lib/api/api";
 
 interface TopologyControlsProps {
     devices: string[];
@@ -16,6 +19,9 @@ interface TopologyControlsProps {
     onAddMonitoringNode: () => void;
     onAddActionNode: () => void;
     onAddEmailNode: () => void;
+    onAddWebhookNode: () => void;
+    onAddChatNode: (channel: ChatChannel) => void;
+    onAddLogicNode: (op: LogicOperator) => void;
     onAddNotificationNode: () => void; // Added
     selectedNode: any;
     onUpdateNodeData: (id: string, data: any) => void;
@@ -27,7 +33,54 @@ const METRIC_OPTIONS = [
     { value: "disk", label: "Disk Usage" },
     { value: "net_rx", label: "Network RX" },
     { value: "net_tx", label: "Network TX" },
+    { value: "net_rx_rate", label: "Network RX (bytes/s)" },
+    { value: "net_tx_rate", label: "Network TX (bytes/s)" },
     { value: "temp", label: "Temperature" },
+    { value: "uptime", label: "Uptime (s)" },
+    { value: "load1", label: "Load (1 min)" },
+    { value: "load5", label: "Load (5 min)" },
+    { value: "load15", label: "Load (15 min)" },
+    { value: "swap", label: "Swap Usage" },
+    { value: "psi_cpu_some", label: "Presión CPU (%)" },
+    { value: "psi_memory_some", label: "Presión memoria (%)" },
+    { value: "psi_memory_full", label: "Presión memoria, total (%)" },
+    { value: "psi_io_some", label: "Presión I/O (%)" },
+    { value: "psi_io_full", label: "Presión I/O, total (%)" },
+    { value: "ctx_switch_rate", label: "Cambios de contexto (/s)" },
+    { value: "fork_rate", label: "Procesos creados (/s)" },
+];
+
+// Las reglas de disparo además pueden alertar cuando el dispositivo deja de reportar
+const ACTION_METRIC_OPTIONS = [
+    ...METRIC_OPTIONS.filter(opt => opt.value !== "net_rx" && opt.value !== "net_tx"),
+    { value: "cpu_core_max", label: "CPU (núcleo más cargado)" },
+    { value: "reboot", label: "Reinicio (1 = se reinició)" },
+    { value: "mount_used_pct", label: "Montaje: uso (%)" },
+    { value: "mount_free_bytes", label: "Montaje: libre (bytes)" },
+    { value: "mount_inodes_used_pct", label: "Montaje: inodos (%)" },
+    { value: "mount_read_only", label: "Montaje: sólo lectura (1 = sí)" },
+    { value: "iface_rx_bytes_rate", label: "Interfaz: RX (bytes/s)" },
+    { value: "iface_tx_bytes_rate", label: "Interfaz: TX (bytes/s)" },
+    { value: "iface_rx_errors_rate", label: "Interfaz: errores RX (/s)" },
+    { value: "iface_tx_errors_rate", label: "Interfaz: errores TX (/s)" },
+    { value: "iface_rx_drops_rate", label: "Interfaz: descartes RX (/s)" },
+    { value: "iface_tx_drops_rate", label: "Interfaz: descartes TX (/s)" },
+    { value: "iface_up", label: "Interfaz: enlace (1 = arriba)" },
+    { value: "disk_read_bytes_rate", label: "Disco: lectura (bytes/s)" },
+    { value: "disk_write_bytes_rate", label: "Disco: escritura (bytes/s)" },
+    { value: "disk_read_iops", label: "Disco: lecturas (IOPS)" },
+    { value: "disk_write_iops", label: "Disco: escrituras (IOPS)" },
+    { value: "disk_await_ms", label: "Disco: espera promedio (ms)" },
+    { value: "disk_util_pct", label: "Disco: utilización (%)" },
+    { value: "heartbeat", label: "Sin reportes (Heartbeat)" },
+];
+
+// Agregación de la regla sobre una ventana de tiempo, para no disparar por un solo pico
+const RULE_AGG_OPTIONS = [
+    { value: "last", label: "Valor actual" },
+    { value: "avg", label: "Promedio" },
+    { value: "min", label: "Mínimo" },
+    { value: "max", label: "Máximo" },
 ];
 
 const RANGE_OPTIONS = [
@@ -45,6 +98,27 @@ const INTERVAL_OPTIONS = [
     { value: "1h", label: "1 Hora" },
 ];
 
+const CHAT_CHANNELS: ChatChannel[] = ["slack", "discord", "ntfy"];
+const LOGIC_OPERATORS: LogicOperator[] = ["and", "or", "not"];
+
+const CHAT_URL_PLACEHOLDERS: Record<ChatChannel, string> = {
+    slack: "https://hooks.slack.com/services/...",
+    discord: "https://discord.com/api/webhooks/...",
+    ntfy: "https://ntfy.sh/mis-alertas",
+};
+
+// Convierte las cabeceras "Nombre: valor" (una por línea) al objeto que espera el backend
+function parseHeaders(text?: string): Record<string, string> {
+    const headers: Record<string, string> = {};
+    for (const line of (text || "").split("\n")) {
+        const idx = line.indexOf(":");
+        if (idx > 0) {
+            headers[line.slice(0, idx).trim()] = line.slice(idx + 1).trim();
+        }
+    }
+    return headers;
+}
+
 const AGG_OPTIONS = [
     { value: "mean", label: "Promedio (Mean)" },
     { value: "min", label: "Mínimo" },
@@ -64,11 +138,40 @@ export default function TopologyControls({
     onAddMonitoringNode,
     onAddActionNode,
     onAddEmailNode,
+    onAddWebhookNode,
+    onAddChatNode,
+    onAddLogicNode,
     onAddNotificationNode,
     selectedNode,
     onUpdateNodeData,
 }: TopologyControlsProps) {
     const { notify } = useNotification();
+
+    const handleTestChannel = async (node: any) => {
+        if (!node.data.url) {
+            notify("Por favor ingresa una URL primero.", "error");
+            return;
+        }
+        const jwt = localStorage.getItem("jwt");
+        if (!jwt) return;
+
+        const req: TestNotificationRequest = {
+            channel: node.type,
+            url: node.data.url,
+            method: node.data.method,
+            headers: parseHeaders(node.data.headers),
+            secret: node.data.secret,
+            timeout: node.data.timeout,
+            retries: node.data.retries,
+        };
+        try {
+            const { sendTestNotification } = await import("@/lib/api/api");
+            await sendTestNotification(jwt, req);
+            notify("Notificación de prueba enviada", "success");
+        } catch (err: any) {
+            notify(`Error enviando notificación: ${err.message}`, "error");
+        }
+    };
     const [isOpen, setIsOpen] = useState(true);
     const [showSaveDialog, setShowSaveDialog] = useState(false);
     const [topologyName, setTopologyName] = useState("");
@@ -156,6 +259,48 @@ export default function TopologyControls({
                             <EnvelopeIcon className="w-6 h-6" />
                             <span className="text-xs">Email</span>
                         </button>
+                        <button
+                            onClick={onAddWebhookNode}
+                            draggable
+                            onDragStart={(event) => {
+                                event.dataTransfer.setData('application/reactflow', 'webhook');
+                                event.dataTransfer.effectAllowed = 'move';
+                            }}
+                            className="flex flex-col items-center justify-center p-3 bg-background/50 hover:bg-accent rounded border border-border transition-colors gap-2 cursor-grab active:cursor-grabbing"
+                        >
+                            <GlobeAltIcon className="w-6 h-6" />
+                            <span className="text-xs">Webhook</span>
+                        </button>
+                        {CHAT_CHANNELS.map((channel) => (
+                            <button
+                                key={channel}
+                                onClick={() => onAddChatNode(channel)}
+                                draggable
+                                onDragStart={(event) => {
+                                    event.dataTransfer.setData('application/reactflow', channel);
+                                    event.dataTransfer.effectAllowed = 'move';
+                                }}
+                                className="flex flex-col items-center justify-center p-3 bg-background/50 hover:bg-accent rounded border border-border transition-colors gap-2 cursor-grab active:cursor-grabbing"
+                            >
+                                <ChatBubbleLeftRightIcon className="w-6 h-6" />
+                                <span className="text-xs">{CHAT_CHANNEL_LABELS[channel]}</span>
+                            </button>
+                        ))}
+                        {LOGIC_OPERATORS.map((op) => (
+                            <button
+                                key={op}
+                                onClick={() => onAddLogicNode(op)}
+                                draggable
+                                onDragStart={(event) => {
+                                    event.dataTransfer.setData('application/reactflow', op);
+                                    event.dataTransfer.effectAllowed = 'move';
+                                }}
+                                className="flex flex-col items-center justify-center p-3 bg-background/50 hover:bg-accent rounded border border-border transition-colors gap-2 cursor-grab active:cursor-grabbing"
+                            >
+                                <Squares2X2Icon className="w-6 h-6" />
+                                <span className="text-xs">{LOGIC_LABELS[op]}</span>
+                            </button>
+                        ))}
                         <button
                             onClick={onAddNotificationNode}
                             draggable
@@ -179,7 +324,10 @@ export default function TopologyControls({
                             {selectedNode.type === 'action' && "Regla de Disparo"}
                             {selectedNode.type === 'action' && "Regla de Disparo"}
                             {selectedNode.type === 'email' && "Configuración de Email"}
+                            {selectedNode.type === 'webhook' && "Configuración de Webhook"}
+                            {CHAT_CHANNELS.includes(selectedNode.type) && `Configuración de ${CHAT_CHANNEL_LABELS[selectedNode.type as ChatChannel]}`}
                             {selectedNode.type === 'notification' && "Configuración de Notificación"}
+                            {LOGIC_OPERATORS.includes(selectedNode.type) && `Condición ${LOGIC_LABELS[selectedNode.type as LogicOperator]}`}
                         </label>
 
                         <div className="space-y-3 bg-muted/30 p-3 rounded-lg border border-border">
@@ -250,36 +398,131 @@ export default function TopologyControls({
                                             value={selectedNode.data.metric || 'cpu'}
                                             onChange={(e) => onUpdateNodeData(selectedNode.id, { metric: e.target.value })}
                                         >
-                                            {METRIC_OPTIONS.map(opt => (
+                                            {ACTION_METRIC_OPTIONS.map(opt => (
                                                 <option key={opt.value} value={opt.value}>{opt.label}</option>
                                             ))}
                                         </select>
                                     </div>
 
-                                    <div className="grid grid-cols-3 gap-2">
-                                        <div className="col-span-1">
-                                            <label className="text-xs text-muted-foreground">Operador</label>
-                                            <select
+                                    {(selectedNode.data.metric || '').startsWith('mount_') && (
+                                        <div>
+                                            <label className="text-xs text-muted-foreground">Punto de montaje</label>
+                                            <input
+                                                type="text"
+                                                placeholder="ej. /data (vacío: el peor)"
                                                 className="w-full mt-1 bg-background/80 border border-border rounded px-2 py-1 text-sm"
-                                                value={selectedNode.data.operator || '>='}
-                                                onChange={(e) => onUpdateNodeData(selectedNode.id, { operator: e.target.value })}
-                                            >
-                                                <option value=">">{'>'}</option>
-                                                <option value=">=">{'>='}</option>
-                                                <option value="<">{'<'}</option>
-                                                <option value="<=">{'<='}</option>
-                                                <option value="==">{'='}</option>
-                                            </select>
+                                                value={selectedNode.data.instance || ''}
+                                                onChange={(e) => onUpdateNodeData(selectedNode.id, { instance: e.target.value })}
+                                            />
+                                            <p className="text-[10px] text-muted-foreground mt-1">Sin montaje se evalúa el más lleno (o el de menos espacio libre)</p>
                                         </div>
-                                        <div className="col-span-2">
-                                            <label className="text-xs text-muted-foreground">Umbral</label>
+                                    )}
+
+                                    {(selectedNode.data.metric || '').startsWith('iface_') && (
+                                        <div>
+                                            <label className="text-xs text-muted-foreground">Interfaz</label>
                                             <input
-                                                type="number"
+                                                type="text"
+                                                placeholder="ej. eth0 (vacío: la peor)"
+                                                className="w-full mt-1 bg-background/80 border border-border rounded px-2 py-1 text-sm"
+                                                value={selectedNode.data.instance || ''}
+                                                onChange={(e) => onUpdateNodeData(selectedNode.id, { instance: e.target.value })}
+                                            />
+                                            <p className="text-[10px] text-muted-foreground mt-1">Sin interfaz se evalúa la de mayor tasa (o cualquier enlace abajo)</p>
+                                        </div>
+                                    )}
+
+                                    {(selectedNode.data.metric || '').startsWith('disk_') && (
+                                        <div>
+                                            <label className="text-xs text-muted-foreground">Disco</label>
+                                            <input
+                                                type="text"
+                                                placeholder="ej. sda (vacío: el más cargado)"
                                                 className="w-full mt-1 bg-background/80 border border-border rounded px-2 py-1 text-sm"
-                                                value={selectedNode.data.threshold || 0}
-                                                onChange={(e) => onUpdateNodeData(selectedNode.id, { threshold: Number(e.target.value) })}
+                                                value={selectedNode.data.instance || ''}
+                                                onChange={(e) => onUpdateNodeData(selectedNode.id, { instance: e.target.value })}
                                             />
+                                            <p className="text-[10px] text-muted-foreground mt-1">Sin disco se evalúa el de mayor valor</p>
                                         </div>
+                                    )}
+
+                                    {selectedNode.data.metric === 'heartbeat' ? (
+                                        <div>
+                                            <label className="text-xs text-muted-foreground">Ventana sin datos</label>
+                                            <input
+                                                type="text"
+                                                placeholder="ej. 2m, 5m, 1h"
+                                                className="w-full mt-1 bg-background/80 border border-border rounded px-2 py-1 text-sm"
+                                                value={selectedNode.data.window || '5m'}
+                                                onChange={(e) => onUpdateNodeData(selectedNode.id, { window: e.target.value })}
+                                            />
+                                            <p className="text-[10px] text-muted-foreground mt-1">Alerta si el dispositivo no reporta en este tiempo y avisa cuando vuelve</p>
+                                        </div>
+                                    ) : (
+                                        <>
+                                        <div className="grid grid-cols-2 gap-2">
+                                            <div>
+                                                <label className="text-xs text-muted-foreground">Agregación</label>
+                                                <select
+                                                    className="w-full mt-1 bg-background/80 border border-border rounded px-2 py-1 text-sm"
+                                                    value={selectedNode.data.aggregation || 'last'}
+                                                    onChange={(e) => onUpdateNodeData(selectedNode.id, { aggregation: e.target.value })}
+                                                >
+                                                    {RULE_AGG_OPTIONS.map(opt => (
+                                                        <option key={opt.value} value={opt.value}>{opt.label}</option>
+                                                    ))}
+                                                </select>
+                                            </div>
+                                            <div>
+                                                <label className="text-xs text-muted-foreground">Ventana</label>
+                                                <input
+                                                    type="text"
+                                                    placeholder="ej. 5m, 10m"
+                                                    disabled={(selectedNode.data.aggregation || 'last') === 'last'}
+                                                    className="w-full mt-1 bg-background/80 border border-border rounded px-2 py-1 text-sm disabled:opacity-50"
+                                                    value={selectedNode.data.window || '5m'}
+                                                    onChange={(e) => onUpdateNodeData(selectedNode.id, { window: e.target.value })}
+                                                />
+                                            </div>
+                                        </div>
+                                        <div className="grid grid-cols-3 gap-2">
+                                            <div className="col-span-1">
+                                                <label className="text-xs text-muted-foreground">Operador</label>
+                                                <select
+                                                    className="w-full mt-1 bg-background/80 border border-border rounded px-2 py-1 text-sm"
+                                                    value={selectedNode.data.operator || '>='}
+                                                    onChange={(e) => onUpdateNodeData(selectedNode.id, { operator: e.target.value })}
+                                                >
+                                                    <option value=">">{'>'}</option>
+                                                    <option value=">=">{'>='}</option>
+                                                    <option value="<">{'<'}</option>
+                                                    <option value="<=">{'<='}</option>
+                                                    <option value="==">{'='}</option>
+                                                </select>
+                                            </div>
+                                            <div className="col-span-2">
+                                                <label className="text-xs text-muted-foreground">Umbral</label>
+                                                <input
+                                                    type="number"
+                                                    className="w-full mt-1 bg-background/80 border border-border rounded px-2 py-1 text-sm"
+                                                    value={selectedNode.data.threshold || 0}
+                                                    onChange={(e) => onUpdateNodeData(selectedNode.id, { threshold: Number(e.target.value) })}
+                                                />
+                                            </div>
+                                        </div>
+                                        </>
+                                    )}
+
+                                    <div>
+                                        <label className="text-xs text-muted-foreground">Sostener por (For)</label>
+                                        <input
+                                            type="text"
+                                            placeholder="ej. 0s, 2m, 10m"
+                                            className="w-full mt-1 bg-background/80 border border-border rounded px-2 py-1 text-sm"
+                                            value={selectedNode.data.for || ''}
+                                            onChange={(e) => onUpdateNodeData(selectedNode.id, { for: e.target.value })}
+                                        />
+                                        <p className="text-[10px] text-muted-foreground mt-1">Tiempo que la condición debe mantenerse antes de disparar</p>
                                     </div>
                                 </>
                             )}
@@ -288,20 +531,41 @@ export default function TopologyControls({
                             {selectedNode.type === 'email' && (
                                 <>
                                     <div>
-                                        <label className="text-xs text-muted-foreground">Destinatario (To)</label>
+                                        <label className="text-xs text-muted-foreground">Destinatarios (To)</label>
                                         <input
-                                            type="email"
-                                            placeholder="admin@example.com"
+                                            type="text"
+                                            placeholder="admin@example.com, ops@example.com"
                                             className="w-full mt-1 bg-background/80 border border-border rounded px-2 py-1 text-sm"
                                             value={selectedNode.data.to || ''}
                                             onChange={(e) => onUpdateNodeData(selectedNode.id, { to: e.target.value })}
                                         />
+                                        <p className="text-[10px] text-muted-foreground mt-1">Separa varias direcciones con coma</p>
+                                    </div>
+                                    <div className="grid grid-cols-2 gap-2">
+                                        <div>
+                                            <label className="text-xs text-muted-foreground">Cc</label>
+                                            <input
+                                                type="text"
+                                                className="w-full mt-1 bg-background/80 border border-border rounded px-2 py-1 text-sm"
+                                                value={selectedNode.data.cc || ''}
+                                                onChange={(e) => onUpdateNodeData(selectedNode.id, { cc: e.target.value })}
+                                            />
+                                        </div>
+                                        <div>
+                                            <label className="text-xs text-muted-foreground">Bcc</label>
+                                            <input
+                                                type="text"
+                                                className="w-full mt-1 bg-background/80 border border-border rounded px-2 py-1 text-sm"
+                                                value={selectedNode.data.bcc || ''}
+                                                onChange={(e) => onUpdateNodeData(selectedNode.id, { bcc: e.target.value })}
+                                            />
+                                        </div>
                                     </div>
                                     <div>
                                         <label className="text-xs text-muted-foreground">Asunto</label>
                                         <input
                                             type="text"
-                                            placeholder="Alerta CPU"
+                                            placeholder="{{.Device}}: {{.Metric}} en {{round .Value 1}}"
                                             className="w-full mt-1 bg-background/80 border border-border rounded px-2 py-1 text-sm"
                                             value={selectedNode.data.subject || ''}
                                             onChange={(e) => onUpdateNodeData(selectedNode.id, { subject: e.target.value })}
@@ -321,17 +585,36 @@ export default function TopologyControls({
                                     <div>
                                         <label className="text-xs text-muted-foreground">Contenido</label>
                                         <textarea
-                                            placeholder="El uso de CPU es alto..."
+                                            placeholder="El uso de {{.Metric}} en {{.Device}} es {{.Value}} ({{.Operator}} {{.Threshold}})"
                                             className="w-full mt-1 bg-background/80 border border-border rounded px-2 py-1 text-sm min-h-[80px] resize-none"
                                             value={selectedNode.data.body || ''}
                                             onChange={(e) => onUpdateNodeData(selectedNode.id, { body: e.target.value })}
                                         />
                                     </div>
+                                    <div>
+                                        <label className="text-xs text-muted-foreground">Contenido HTML (opcional)</label>
+                                        <textarea
+                                            placeholder="<h2>{{.Device}}</h2><p>{{.Metric}}: {{.Value}}</p>"
+                                            className="w-full mt-1 bg-background/80 border border-border rounded px-2 py-1 text-sm font-mono min-h-[60px] resize-none"
+                                            value={selectedNode.data.html || ''}
+                                            onChange={(e) => onUpdateNodeData(selectedNode.id, { html: e.target.value })}
+                                        />
+                                    </div>
+                                    <details className="text-[10px] text-muted-foreground">
+                                        <summary className="cursor-pointer">Variables de plantilla</summary>
+                                        <p className="mt-1 font-mono leading-relaxed">
+                                            {"{{.Device}} {{.Metric}} {{.Operator}} {{.Threshold}} {{.Value}} {{.State}} {{.Resolved}} {{.Window}} {{.Timestamp}} {{.Time}} {{.Topology}} {{.Samples}}"}
+                                        </p>
+                                        <p className="mt-1">
+                                            Funciones: {"round VALOR DECIMALES, date TIEMPO FORMATO, duration SEGUNDOS, upper, lower"}.
+                                            Ej: {"{{range .Samples}}{{round .Value 1}} {{end}}"}
+                                        </p>
+                                    </details>
                                     <div className="pt-2">
                                         <button
                                             onClick={async () => {
-                                                const email = selectedNode.data.to;
-                                                if (!email) {
+                                                const { to, cc, bcc, subject, body, html } = selectedNode.data;
+                                                if (!to && !cc && !bcc) {
                                                     notify("Por favor ingresa un destinatario primero.", "error");
                                                     return;
                                                 }
@@ -339,9 +622,9 @@ export default function TopologyControls({
                                                 if (!jwt) return;
 
                                                 try {
-                                                    const { sendTestEmail } = await import("@/lib/api/api");
-                                                    await sendTestEmail(jwt, email);
-                                                    notify(`Correo de prueba enviado a ${email}`, "success");
+                                                    const { sendTestNotification } = await import("@/lib/api/api");
+                                                    await sendTestNotification(jwt, { channel: "email", to, cc, bcc, subject, body, html });
+                                                    notify("Correo de prueba enviado", "success");
                                                 } catch (err: any) {
                                                     notify(`Error enviando correo: ${err.message}`, "error");
                                                 }
@@ -355,6 +638,189 @@ export default function TopologyControls({
                                 </>
                             )}
 
+                            {/* Webhook Node Config */}
+                            {selectedNode.type === 'webhook' && (
+                                <>
+                                    <div>
+                                        <label className="text-xs text-muted-foreground">URL</label>
+                                        <input
+                                            type="url"
+                                            placeholder="https://oncall.example.com/hooks/nocturne"
+                                            className="w-full mt-1 bg-background/80 border border-border rounded px-2 py-1 text-sm"
+                                            value={selectedNode.data.url || ''}
+                                            onChange={(e) => onUpdateNodeData(selectedNode.id, { url: e.target.value })}
+                                        />
+                                    </div>
+                                    <div className="grid grid-cols-3 gap-2">
+                                        <div>
+                                            <label className="text-xs text-muted-foreground">Método</label>
+                                            <select
+                                                className="w-full mt-1 bg-background/80 border border-border rounded px-2 py-1 text-sm"
+                                                value={selectedNode.data.method || 'POST'}
+                                                onChange={(e) => onUpdateNodeData(selectedNode.id, { method: e.target.value })}
+                                            >
+                                                <option value="POST">POST</option>
+                                                <option value="PUT">PUT</option>
+                                            </select>
+                                        </div>
+                                        <div>
+                                            <label className="text-xs text-muted-foreground">Timeout</label>
+                                            <input
+                                                type="text"
+                                                placeholder="10s"
+                                                className="w-full mt-1 bg-background/80 border border-border rounded px-2 py-1 text-sm"
+                                                value={selectedNode.data.timeout || ''}
+                                                onChange={(e) => onUpdateNodeData(selectedNode.id, { timeout: e.target.value })}
+                                            />
+                                        </div>
+                                        <div>
+                                            <label className="text-xs text-muted-foreground">Reintentos</label>
+                                            <input
+                                                type="number"
+                                                min={0}
+                                                max={10}
+                                                placeholder="2"
+                                                className="w-full mt-1 bg-background/80 border border-border rounded px-2 py-1 text-sm"
+                                                value={selectedNode.data.retries ?? ''}
+                                                onChange={(e) => onUpdateNodeData(selectedNode.id, { retries: e.target.value === '' ? undefined : Number(e.target.value) })}
+                                            />
+                                        </div>
+                                    </div>
+                                    <div>
+                                        <label className="text-xs text-muted-foreground">Cabeceras</label>
+                                        <textarea
+                                            placeholder={"Authorization: Bearer ...\nX-Team: infra"}
+                                            className="w-full mt-1 bg-background/80 border border-border rounded px-2 py-1 text-sm font-mono min-h-[60px] resize-none"
+                                            value={selectedNode.data.headers || ''}
+                                            onChange={(e) => onUpdateNodeData(selectedNode.id, { headers: e.target.value })}
+                                        />
+                                        <p className="text-[10px] text-muted-foreground mt-1">Una por línea, formato Nombre: valor</p>
+                                    </div>
+                                    <div>
+                                        <label className="text-xs text-muted-foreground">Secreto HMAC (opcional)</label>
+                                        <input
+                                            type="password"
+                                            className="w-full mt-1 bg-background/80 border border-border rounded px-2 py-1 text-sm"
+                                            value={selectedNode.data.secret || ''}
+                                            onChange={(e) => onUpdateNodeData(selectedNode.id, { secret: e.target.value })}
+                                        />
+                                        <p className="text-[10px] text-muted-foreground mt-1">Firma en X-Nocturne-Signature: sha256=HMAC(secreto, timestamp + "." + cuerpo)</p>
+                                    </div>
+                                    <div>
+                                        <label className="text-xs text-muted-foreground">Frecuencia (Cooldown)</label>
+                                        <input
+                                            type="text"
+                                            placeholder="ej. 10m, 1h, 24h"
+                                            className="w-full mt-1 bg-background/80 border border-border rounded px-2 py-1 text-sm"
+                                            value={selectedNode.data.cooldown || '1h'}
+                                            onChange={(e) => onUpdateNodeData(selectedNode.id, { cooldown: e.target.value })}
+                                        />
+                                    </div>
+                                    <div className="pt-2">
+                                        <button
+                                            onClick={() => handleTestChannel(selectedNode)}
+                                            className="w-full px-3 py-1.5 bg-secondary hover:bg-secondary/80 text-secondary-foreground rounded text-xs font-medium transition-colors flex items-center justify-center gap-2"
+                                        >
+                                            <GlobeAltIcon className="w-3 h-3" />
+                                            Probar Envío
+                                        </button>
+                                    </div>
+                                </>
+                            )}
+
+                            {/* Chat Node Config (Slack, Discord, ntfy) */}
+                            {CHAT_CHANNELS.includes(selectedNode.type) && (
+                                <>
+                                    <div>
+                                        <label className="text-xs text-muted-foreground">
+                                            {selectedNode.type === 'ntfy' ? "URL del tópico" : "URL del webhook entrante"}
+                                        </label>
+                                        <input
+                                            type="url"
+                                            placeholder={CHAT_URL_PLACEHOLDERS[selectedNode.type as ChatChannel]}
+                                            className="w-full mt-1 bg-background/80 border border-border rounded px-2 py-1 text-sm"
+                                            value={selectedNode.data.url || ''}
+                                            onChange={(e) => onUpdateNodeData(selectedNode.id, { url: e.target.value })}
+                                        />
+                                    </div>
+                                    <div className="grid grid-cols-2 gap-2">
+                                        <div>
+                                            <label className="text-xs text-muted-foreground">Timeout</label>
+                                            <input
+                                                type="text"
+                                                placeholder="10s"
+                                                className="w-full mt-1 bg-background/80 border border-border rounded px-2 py-1 text-sm"
+                                                value={selectedNode.data.timeout || ''}
+                                                onChange={(e) => onUpdateNodeData(selectedNode.id, { timeout: e.target.value })}
+                                            />
+                                        </div>
+                                        <div>
+                                            <label className="text-xs text-muted-foreground">Reintentos</label>
+                                            <input
+                                                type="number"
+                                                min={0}
+                                                max={10}
+                                                placeholder="2"
+                                                className="w-full mt-1 bg-background/80 border border-border rounded px-2 py-1 text-sm"
+                                                value={selectedNode.data.retries ?? ''}
+                                                onChange={(e) => onUpdateNodeData(selectedNode.id, { retries: e.target.value === '' ? undefined : Number(e.target.value) })}
+                                            />
+                                        </div>
+                                    </div>
+                                    {selectedNode.type === 'ntfy' && (
+                                        <div>
+                                            <label className="text-xs text-muted-foreground">Cabeceras</label>
+                                            <textarea
+                                                placeholder="Authorization: Bearer tk_..."
+                                                className="w-full mt-1 bg-background/80 border border-border rounded px-2 py-1 text-sm font-mono min-h-[60px] resize-none"
+                                                value={selectedNode.data.headers || ''}
+                                                onChange={(e) => onUpdateNodeData(selectedNode.id, { headers: e.target.value })}
+                                            />
+                                            <p className="text-[10px] text-muted-foreground mt-1">Para tópicos protegidos. Una por línea, formato Nombre: valor</p>
+                                        </div>
+                                    )}
+                                    <div>
+                                        <label className="text-xs text-muted-foreground">Frecuencia (Cooldown)</label>
+                                        <input
+                                            type="text"
+                                            placeholder="ej. 10m, 1h, 24h"
+                                            className="w-full mt-1 bg-background/80 border border-border rounded px-2 py-1 text-sm"
+                                            value={selectedNode.data.cooldown || '1h'}
+                                            onChange={(e) => onUpdateNodeData(selectedNode.id, { cooldown: e.target.value })}
+                                        />
+                                    </div>
+                                    <div className="pt-2">
+                                        <button
+                                            onClick={() => handleTestChannel(selectedNode)}
+                                            className="w-full px-3 py-1.5 bg-secondary hover:bg-secondary/80 text-secondary-foreground rounded text-xs font-medium transition-colors flex items-center justify-center gap-2"
+                                        >
+                                            <ChatBubbleLeftRightIcon className="w-3 h-3" />
+                                            Probar Envío
+                                        </button>
+                                    </div>
+                                </>
+                            )}
+
+                            {/* Logic Node Config */}
+                            {LOGIC_OPERATORS.includes(selectedNode.type) && (
+                                <>
+                                    <p className="text-xs text-muted-foreground">
+                                        {LOGIC_HINTS[selectedNode.type as LogicOperator]}. Conecta acciones u otros nodos lógicos a la entrada y las salidas (email, webhook, chat) a la derecha.
+                                    </p>
+                                    <div>
+                                        <label className="text-xs text-muted-foreground">Sostener por (For)</label>
+                                        <input
+                                            type="text"
+                                            placeholder="ej. 0s, 2m, 10m"
+                                            className="w-full mt-1 bg-background/80 border border-border rounded px-2 py-1 text-sm"
+                                            value={selectedNode.data.for || ''}
+                                            onChange={(e) => onUpdateNodeData(selectedNode.id, { for: e.target.value })}
+                                        />
+                                        <p className="text-[10px] text-muted-foreground mt-1">Tiempo que la combinación debe mantenerse antes de disparar</p>
+                                    </div>
+                                </>
+                            )}
+
                             {/* Notification Node Config */}
                             {selectedNode.type === 'notification' && (
                                 <div>
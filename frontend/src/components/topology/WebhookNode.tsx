@@ -0,0 +1,60 @@
+"use client";
+
+import { memo } from "react";
+import { Handle, Position, NodeProps } from "@xyflow/react";
+import { GlobeAltIcon } from "@heroicons/react/24/outline";
+
+export interface WebhookNodeData extends Record<string, unknown> {
+    url?: string;
+    method?: string; // POST por defecto
+    headers?: string; // una cabecera por línea: "Nombre: valor"
+    secret?: string; // firma HMAC-SHA256 opcional
+    timeout?: string; // e.g., "10s"
+    retries?: number;
+    cooldown?: string;
+    isActive?: boolean;
+}
+
+function WebhookNode({ data, selected }: NodeProps) {
+    const typedData = data as WebhookNodeData;
+    const { isActive, url, method = "POST", secret } = typedData;
+
+    return (
+        <div
+            className={`min-w-[150px] max-w-[240px] bg-card border-2 rounded-lg shadow-lg flex flex-col overflow-hidden transition-all ${selected ? "border-primary ring-2 ring-primary/20" : "border-border"
+                } ${isActive ? "shadow-[0_0_15px_rgba(168,85,247,0.5)] border-purple-500" : ""}`}
+        >
+            {/* Input Handle (from Action) */}
+            <Handle
+                type="target"
+                position={Position.Left}
+                id="t-in"
+                className="w-3 h-3 !bg-primary"
+            />
+
+            {/* Header */}
+            <div className={`px-3 py-2 flex justify-between items-center ${isActive ? "bg-purple-500/10" : "bg-muted/50"}`}>
+                <div className="flex items-center gap-2">
+                    <GlobeAltIcon className={`w-5 h-5 ${isActive ? "text-purple-500 animate-pulse" : "text-muted-foreground"}`} />
+                    <span className="font-medium text-sm">Webhook</span>
+                </div>
+                {secret && <span className="text-[10px] text-muted-foreground">HMAC</span>}
+            </div>
+
+            {/* Status Indicator */}
+            <div className="px-3 py-2 space-y-1 border-t border-border/50">
+                {url ? (
+                    <div className="text-xs text-muted-foreground truncate">
+                        <span className="font-mono font-semibold">{method}</span> {url}
+                    </div>
+                ) : (
+                    <div className="text-xs text-muted-foreground italic">
+                        Sin URL configurada
+                    </div>
+                )}
+            </div>
+        </div>
+    );
+}
+
+export default memo(WebhookNode);
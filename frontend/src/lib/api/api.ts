@@ -131,9 +131,11 @@ export async function getLastStats(jwt: string, device: string) {
 
 export async function getTimeseries(
   jwt: string,
-  params: { device: string; field: string; range: string; agg: string; interval: string }
+  params: { device: string; field: string; range: string; agg: string; interval: string; instance?: string }
 ) {
-  const q = new URLSearchParams(params as any).toString();
+  // instance sólo aplica a las series por instancia (ej. el punto de montaje de mount_used_pct)
+  const { instance, ...rest } = params;
+  const q = new URLSearchParams(instance ? { ...rest, instance } : rest).toString();
   const res = await fetch(`${BASE}/metrics/timeseries?${q}`, {
     headers: { Authorization: `Bearer ${jwt}` },
     cache: "no-store",
@@ -141,6 +143,98 @@ export async function getTimeseries(
   return handle(res) as Promise<{ points: { t: string; v: number }[] }>;
 }
 
+// Último valor de cada punto de montaje del dispositivo.
+export interface MountStats {
+  mount: string;
+  time: string;
+  mount_used_pct?: number;
+  mount_free_bytes?: number;
+  mount_total_bytes?: number;
+  mount_inodes_used_pct?: number;
+  mount_read_only?: number; // 1 si está montado sólo lectura
+}
+
+export async function getMounts(jwt: string, device: string) {
+  const res = await fetch(`${BASE}/metrics/mounts?device=${encodeURIComponent(device)}`, {
+    headers: { Authorization: `Bearer ${jwt}` },
+    cache: "no-store",
+  });
+  const data = await handle(res);
+  return (Array.isArray(data) ? data : []) as MountStats[];
+}
+
+// Último estado de cada interfaz de red; las tasas son por segundo.
+export interface InterfaceStatsRow {
+  iface: string;
+  time: string;
+  iface_up?: number; // 1 enlace arriba, 0 abajo
+  iface_rx_bytes_rate?: number;
+  iface_tx_bytes_rate?: number;
+  iface_rx_packets_rate?: number;
+  iface_tx_packets_rate?: number;
+  iface_rx_errors_rate?: number;
+  iface_tx_errors_rate?: number;
+  iface_rx_drops_rate?: number;
+  iface_tx_drops_rate?: number;
+}
+
+export async function getInterfaces(jwt: string, device: string) {
+  const res = await fetch(`${BASE}/metrics/interfaces?device=${encodeURIComponent(device)}`, {
+    headers: { Authorization: `Bearer ${jwt}` },
+    cache: "no-store",
+  });
+  const data = await handle(res);
+  return (Array.isArray(data) ? data : []) as InterfaceStatsRow[];
+}
+
+// Último I/O de cada disco: tasas por segundo, espera en ms y utilización en %.
+export interface DiskIOStats {
+  disk: string;
+  time: string;
+  disk_read_bytes_rate?: number;
+  disk_write_bytes_rate?: number;
+  disk_read_iops?: number;
+  disk_write_iops?: number;
+  disk_await_ms?: number;
+  disk_util_pct?: number;
+}
+
+export async function getDisks(jwt: string, device: string) {
+  const res = await fetch(`${BASE}/metrics/disks?device=${encodeURIComponent(device)}`, {
+    headers: { Authorization: `Bearer ${jwt}` },
+    cache: "no-store",
+  });
+  const data = await handle(res);
+  return (Array.isArray(data) ? data : []) as DiskIOStats[];
+}
+
+// Procesos que más CPU o memoria usan, según la última recolección del agente.
+export interface ProcessInfo {
+  pid: number;
+  name: string;
+  user?: string;
+  cpu_pct: number;
+  mem_pct: number;
+  rss_bytes: number;
+  cmdline?: string;
+}
+
+export interface ProcessSnapshot {
+  device: string;
+  time: string;
+  processes: ProcessInfo[];
+}
+
+// Retorna null si el dispositivo aún no reportó procesos.
+export async function getProcesses(jwt: string, device: string) {
+  const res = await fetch(`${BASE}/metrics/processes?device=${encodeURIComponent(device)}`, {
+    headers: { Authorization: `Bearer ${jwt}` },
+    cache: "no-store",
+  });
+  if (res.status === 404) return null;
+  return handle(res) as Promise<ProcessSnapshot>;
+}
+
 export async function getHistory(jwt: string, device: string, range: string) {
   const q = new URLSearchParams({ device, range }).toString();
   const res = await fetch(`${BASE}/metrics/history?${q}`, {
@@ -159,6 +253,32 @@ export async function getRecentAlerts(jwt: string) {
   return (data.recent_alerts || []) as string[];
 }
 
+export type AlertState = "pending" | "firing";
+
+export interface ActiveAlert {
+  rule_id: string;
+  topology_id: number;
+  device: string;
+  metric: string;
+  operator?: string;
+  threshold: number;
+  state: AlertState;
+  value: number;
+  since: string;
+  held_by?: string; // dispositivo aguas arriba caído: la alerta no se notificó
+  silenced_by?: number; // silencio activo: la alerta no se notificó
+}
+
+export async function getActiveAlerts(jwt: string, state?: AlertState) {
+  const qs = state ? `?state=${state}` : "";
+  const res = await fetch(`${BASE}/alerts/active${qs}`, {
+    headers: { Authorization: `Bearer ${jwt}` },
+    cache: "no-store",
+  });
+  const data = await handle(res);
+  return (data.alerts || []) as ActiveAlert[];
+}
+
 export async function sendTestEmail(jwt: string, email: string) {
   const res = await fetch(`${BASE}/alerts/test-email`, {
     method: "POST",
@@ -170,3 +290,96 @@ export async function sendTestEmail(jwt: string, email: string) {
   });
   return handle(res);
 }
+
+export type NotificationChannel = "email" | "webhook" | "slack" | "discord" | "ntfy";
+
+export interface TestNotificationRequest {
+  channel: NotificationChannel;
+  to?: string; // direcciones separadas por coma
+  cc?: string;
+  bcc?: string;
+  subject?: string;
+  body?: string;
+  html?: string;
+  url?: string;
+  method?: string;
+  headers?: Record<string, string>;
+  secret?: string;
+  timeout?: string;
+  retries?: number;
+}
+
+export async function sendTestNotification(jwt: string, req: TestNotificationRequest) {
+  const res = await fetch(`${BASE}/alerts/test-notification`, {
+    method: "POST",
+    headers: {
+      "Content-Type": "application/json",
+      Authorization: `Bearer ${jwt}`,
+    },
+    body: JSON.stringify(req),
+  });
+  return handle(res);
+}
+
+// Silencio: mientras está activo las alertas que coinciden no se notifican.
+// Con from/to es una ventana de mantenimiento recurrente.
+export interface Silence {
+  id: number;
+  user_id: number;
+  global: boolean;
+  author: string;
+  comment?: string;
+  topology_id?: number;
+  rule_id?: string;
+  device?: string;
+  metric?: string;
+  starts_at: string;
+  ends_at?: string;
+  weekdays?: number[]; // 0 = domingo
+  from?: string; // "02:00"
+  to?: string; // "04:00"
+  timezone?: string;
+  created_at: string;
+}
+
+export type SilenceInput = Omit<Silence, "id" | "user_id" | "author" | "created_at">;
+
+export async function getSilences(jwt: string) {
+  const res = await fetch(`${BASE}/silences`, {
+    headers: { Authorization: `Bearer ${jwt}` },
+    cache: "no-store",
+  });
+  return ((await handle(res)) || []) as Silence[];
+}
+
+export async function createSilence(jwt: string, silence: SilenceInput) {
+  const res = await fetch(`${BASE}/silences`, {
+    method: "POST",
+    headers: {
+      "Content-Type": "application/json",
+      Authorization: `Bearer ${jwt}`,
+    },
+    body: JSON.stringify(silence),
+  });
+  return handle(res) as Promise<Silence>;
+}
+
+export async function updateSilence(jwt: string, id: number, silence: SilenceInput) {
+  const res = await fetch(`${BASE}/silences/${id}`, {
+    method: "PUT",
+    headers: {
+      "Content-Type": "application/json",
+      Authorization: `Bearer ${jwt}`,
+    },
+    body: JSON.stringify(silence),
+  });
+  return handle(res) as Promise<Silence>;
+}
+
+export async function deleteSilence(jwt: string, id: number) {
+  const res = await fetch(`${BASE}/silences/${id}`, {
+    method: "DELETE",
+    headers: { Authorization: `Bearer ${jwt}` },
+  });
+  return handle(res);
+}
@@ -1,24 +1,28 @@
 const ORIGIN = (process.env.NEXT_PUBLIC_API_URL || "https://api.nocturnesec.cl").replace(/\/+$/, "");
 const API = `${ORIGIN}/api`;
 
+// "device" reporta sólo por su dispositivo; "fleet" acepta un patrón (ej. "web-*").
+export type TokenKind = "device" | "fleet";
+
 export interface APIToken {
     ID: number;
     Name: string;
     TokenHash: string;
     UserID: number;
     DeviceName: string;
+    Kind: TokenKind;
     CreatedAt: string;
     RevokedAt?: string;
 }
 
-export async function createAPIToken(jwt: string, name: string, deviceName: string): Promise<{ token: string }> {
+export async function createAPIToken(jwt: string, name: string, deviceName: string, kind: TokenKind = "device"): Promise<{ token: string }> {
     const res = await fetch(`${API}/api-tokens`, {
         method: "POST",
         headers: {
             "Content-Type": "application/json",
             Authorization: `Bearer ${jwt}`,
         },
-        body: JSON.stringify({ name, device_name: deviceName }),
+        body: JSON.stringify({ name, device_name: deviceName, kind }),
     });
 
     if (!res.ok) {